@@ -0,0 +1,149 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+const (
+	// Maximum number of keys allowed by dynamo db in a single BatchGetItem request
+	MaxBatchGetItemKeys = 100
+
+	// Maximum number of put and delete requests allowed by dynamo db in a single
+	// BatchWriteItem request
+	MaxBatchWriteItems = 25
+
+	// Maximum number of items allowed by dynamo db in a single TransactWriteItems request
+	MaxTransactWriteItems = 100
+)
+
+// WriteRequest is a put or delete request of a BatchWriteItem request, Item is nil for a
+// delete request
+type WriteRequest struct {
+	Item map[string]ddbexpr.Value
+	Key  map[string]ddbexpr.Value
+}
+
+// UniqueKeys returns the keys of `items` of the table dropping the duplicate ones, order of
+// keys is maintained
+func UniqueKeys(tableName string, items []map[string]ddbexpr.Value, keyAttributeNames []string) ([]map[string]ddbexpr.Value, error) {
+	uniqueKeys := make([]map[string]ddbexpr.Value, 0, len(items))
+	seenKeys := make(map[string]struct{}, len(items))
+	for idx, item := range items {
+		key, err := ExtractKey(item, keyAttributeNames)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", idx, err)
+		}
+
+		identity := KeyIdentity(tableName, key)
+		if _, ok := seenKeys[identity]; ok {
+			continue
+		}
+		seenKeys[identity] = struct{}{}
+
+		uniqueKeys = append(uniqueKeys, key)
+	}
+
+	return uniqueKeys, nil
+}
+
+// WriteRequests returns the put requests of `putItems` followed by the delete requests of
+// `deleteItems` of the table. Returns an error if more than one request targets the same item
+func WriteRequests(tableName string, putItems, deleteItems []map[string]ddbexpr.Value, keyAttributeNames []string) ([]WriteRequest, error) {
+	writeRequests := make([]WriteRequest, 0, len(putItems)+len(deleteItems))
+	requestIndexByKey := map[string]int{}
+	addWriteRequest := func(item map[string]ddbexpr.Value, isPut bool) error {
+		key, err := ExtractKey(item, keyAttributeNames)
+		if err != nil {
+			return err
+		}
+
+		identity := KeyIdentity(tableName, key)
+		if firstIdx, ok := requestIndexByKey[identity]; ok {
+			return fmt.Errorf("request %d and %d of batch write target the same item [%s]", firstIdx, len(writeRequests), identity)
+		}
+		requestIndexByKey[identity] = len(writeRequests)
+
+		if isPut {
+			writeRequests = append(writeRequests, WriteRequest{Item: item, Key: key})
+		} else {
+			writeRequests = append(writeRequests, WriteRequest{Key: key})
+		}
+
+		return nil
+	}
+
+	for _, putItem := range putItems {
+		if err := addWriteRequest(putItem, true); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, deleteItem := range deleteItems {
+		if err := addWriteRequest(deleteItem, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return writeRequests, nil
+}
+
+// Chunks splits `elements` into chunks of at most `size` elements, order is maintained
+func Chunks[E any](elements []E, size int) [][]E {
+	chunks := [][]E{}
+	for start := 0; start < len(elements); start += size {
+		chunks = append(chunks, elements[start:min(start+size, len(elements))])
+	}
+
+	return chunks
+}
+
+// ExtractKey returns the key of a dynamo db item
+func ExtractKey(item map[string]ddbexpr.Value, keyAttributeNames []string) (map[string]ddbexpr.Value, error) {
+	if len(keyAttributeNames) == 0 {
+		return nil, errors.New("no key attribute found in expression builder")
+	}
+
+	key := make(map[string]ddbexpr.Value, len(keyAttributeNames))
+	for _, keyAttributeName := range keyAttributeNames {
+		keyAttributeValue, ok := item[keyAttributeName]
+		if !ok || keyAttributeValue.Type == ddbexpr.VALUE_NULL {
+			return nil, errors.New("value of key attribute [" + keyAttributeName + "] is missing")
+		}
+
+		key[keyAttributeName] = keyAttributeValue
+	}
+
+	return key, nil
+}
+
+// KeyIdentity returns a string which uniquely identifies an item of a table. Table name, names
+// and values of key attributes are quoted so that separators within them can't make two keys
+// collide, and numbers are canonicalised so that e.g. 1 and 1.0 identify the same item
+func KeyIdentity(tableName string, key map[string]ddbexpr.Value) string {
+	keyAttributeNames := make([]string, 0, len(key))
+	for keyAttributeName := range key {
+		keyAttributeNames = append(keyAttributeNames, keyAttributeName)
+	}
+	sort.Strings(keyAttributeNames)
+
+	identity := strings.Builder{}
+	identity.WriteString(strconv.Quote(tableName))
+	for _, keyAttributeName := range keyAttributeNames {
+		keyAttributeValue := key[keyAttributeName]
+		identity.WriteString(", " + strconv.Quote(keyAttributeName) + "=")
+		// key attributes can only be of type string, number or binary
+		if scalarKey, ok := ddbexpr.ScalarKey(keyAttributeValue); ok {
+			identity.WriteString(strconv.Quote(scalarKey))
+		} else {
+			identity.WriteString(strconv.Quote(keyAttributeValue.Format(0)))
+		}
+	}
+
+	return identity.String()
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyIdentity(t *testing.T) {
+	stringValue := func(value string) ddbexpr.Value { return ddbexpr.Value{Type: ddbexpr.VALUE_S, String: value} }
+	numberValue := func(value string) ddbexpr.Value { return ddbexpr.Value{Type: ddbexpr.VALUE_N, String: value} }
+
+	// separators within values don't make different keys collide
+	assert.NotEqual(t,
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": stringValue("a, sk=S:b"), "sk": stringValue("c")}),
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": stringValue("a"), "sk": stringValue("b, sk=S:c")}))
+	assert.NotEqual(t,
+		KeyIdentity("persons, pk=S:a", map[string]ddbexpr.Value{"pk": stringValue("b")}),
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": stringValue("a, pk=S:b")}))
+	assert.NotEqual(t,
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": stringValue("1")}),
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": numberValue("1")}))
+
+	// equal numbers identify the same item
+	assert.Equal(t,
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": numberValue("1")}),
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": numberValue("1.0")}))
+	assert.Equal(t,
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": numberValue("100")}),
+		KeyIdentity("persons", map[string]ddbexpr.Value{"pk": numberValue("1e2")}))
+	assert.Equal(t, `"persons", "pk"="S:a\"b"`, KeyIdentity("persons", map[string]ddbexpr.Value{"pk": stringValue(`a"b`)}))
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+type MarkMode int
+
+const (
+	// Marks shadowed by the marks of a parent are silently ignored
+	MARK_MODE_PERMISSIVE MarkMode = iota
+	// Mark conflicts fail BuildProjectionBuilder and BuildUpdateBuilder
+	MARK_MODE_STRICT
+)
+
+func (mm MarkMode) String() string {
+	switch mm {
+	case MARK_MODE_PERMISSIVE:
+		return "PERMISSIVE"
+	case MARK_MODE_STRICT:
+		return "STRICT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type MarkConflictKind int
+
+const (
+	MARK_CONFLICT_SHADOWED_UPDATE MarkConflictKind = iota
+	MARK_CONFLICT_SHADOWED_PROJECTION
+	MARK_CONFLICT_REPLACED_UPDATE
+)
+
+func (mck MarkConflictKind) String() string {
+	switch mck {
+	case MARK_CONFLICT_SHADOWED_UPDATE:
+		return "SHADOWED_UPDATE"
+	case MARK_CONFLICT_SHADOWED_PROJECTION:
+		return "SHADOWED_PROJECTION"
+	case MARK_CONFLICT_REPLACED_UPDATE:
+		return "REPLACED_UPDATE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarkConflict is a mark of the tree which doesn't end up in the expression as marked, e.g. an
+// update of an attribute whose parent is set as a whole
+type MarkConflict struct {
+	Kind MarkConflictKind
+
+	// Document path of the conflicting mark
+	Path string
+
+	// Document path of the parent whose mark shadows the conflicting mark, only for
+	// MARK_CONFLICT_SHADOWED_UPDATE and MARK_CONFLICT_SHADOWED_PROJECTION
+	ShadowedBy string
+
+	message string
+}
+
+func (mc *MarkConflict) Error() string {
+	return mc.message
+}
+
+// MarkConflicts are all the conflicting marks of the tree, errors.As can be used to get the
+// first *MarkConflict
+type MarkConflicts []*MarkConflict
+
+func (mcs MarkConflicts) Error() string {
+	messages := make([]string, 0, len(mcs))
+	for _, markConflict := range mcs {
+		messages = append(messages, markConflict.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (mcs MarkConflicts) Unwrap() []error {
+	errs := make([]error, 0, len(mcs))
+	for _, markConflict := range mcs {
+		errs = append(errs, markConflict)
+	}
+
+	return errs
+}
+
+// Conflicts returns the conflicting marks of the tree of 'this' root node
+func (n *Node) Conflicts() MarkConflicts {
+	check := &markCheck{conflicts: MarkConflicts{}}
+	for _, child := range n.children {
+		child.checkMarks("", "", check)
+	}
+
+	return check.conflicts
+}
+
+// StrictConflicts returns the conflicts of `kinds` of the tree of 'this' root node in
+// MARK_MODE_STRICT, nil otherwise
+func (n *Node) StrictConflicts(mode MarkMode, kinds ...MarkConflictKind) error {
+	if mode != MARK_MODE_STRICT {
+		return nil
+	}
+
+	conflicts := MarkConflicts{}
+	for _, markConflict := range n.Conflicts() {
+		if slices.Contains(kinds, markConflict.Kind) {
+			conflicts = append(conflicts, markConflict)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return conflicts
+}
+
+// markCheck collects the mark conflicts while walking the tree
+type markCheck struct {
+	conflicts MarkConflicts
+}
+
+// checkMarks collects the mark conflicts of 'this' node and the nodes below it, `updatedParent`
+// and `projectedParent` are the document paths of the closest parent marked for update and
+// projection, empty if there is none
+func (n *Node) checkMarks(updatedParent, projectedParent string, check *markCheck) {
+	// key attributes are always top level and can't be marked for update
+	if n.kind == NODE_KEY {
+		return
+	}
+
+	updatedParent, projectedParent = check.check(n, updatedParent, projectedParent)
+	for _, child := range n.nodesBelow() {
+		child.checkMarks(updatedParent, projectedParent, check)
+	}
+}
+
+// check collects the conflicts of the marks of a single node, returns the document paths of the
+// closest parents marked for update and projection for the nodes below it
+func (mc *markCheck) check(n *Node, updatedParent, projectedParent string) (string, string) {
+	documentPath := n.DocumentPath()
+	if n.operation != NO_OP {
+		switch {
+		case updatedParent != "":
+			mc.conflicts = append(mc.conflicts, &MarkConflict{
+				Kind:       MARK_CONFLICT_SHADOWED_UPDATE,
+				Path:       documentPath,
+				ShadowedBy: updatedParent,
+				message:    "update of attribute " + documentPath + " is ignored since its parent " + updatedParent + " is updated",
+			})
+		default:
+			updatedParent = documentPath
+		}
+	}
+
+	if n.replacedOperation != NO_OP {
+		mc.conflicts = append(mc.conflicts, &MarkConflict{
+			Kind:    MARK_CONFLICT_REPLACED_UPDATE,
+			Path:    documentPath,
+			message: n.replacedOperation.String() + " of attribute " + documentPath + " is replaced by " + n.operation.String(),
+		})
+	}
+
+	if n.projection {
+		if projectedParent != "" {
+			mc.conflicts = append(mc.conflicts, &MarkConflict{
+				Kind:       MARK_CONFLICT_SHADOWED_PROJECTION,
+				Path:       documentPath,
+				ShadowedBy: projectedParent,
+				message:    "projection of attribute " + documentPath + " is ignored since its parent " + projectedParent + " is projected",
+			})
+		} else {
+			projectedParent = documentPath
+		}
+	}
+
+	return updatedParent, projectedParent
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+// RawValue is a value marshalled by the sdk which is marked for update as it is, the sdk packages
+// convert it back into an attribute value of their sdk
+type RawValue struct {
+	Value ddbexpr.Value
+}
+
+// OldValueCondition asserts that the attribute at Path still holds its old value, Old is nil
+// when the attribute was absent in which case it is asserted to not exist
+type OldValueCondition struct {
+	Path string
+	Old  *ddbexpr.Value
+}
+
+// Diff marks UPDATE_SET/UPDATE_REMOVE on the minimal set of nodes which differ between `oldValue`
+// and `newValue`, nil value represents absence of the attribute. Maps are compared attribute by
+// attribute and lists index by index, list items which are not part of the tree are added to it.
+// Changed attributes of a map which have no node in the tree return ErrUnknownAttribute. With
+// `oldValueConditions` an OldValueCondition is added on every changed node
+//
+// Every change is checked before the tree is changed, on error the tree is left unchanged
+func (n *Node) Diff(oldValue, newValue *ddbexpr.Value, oldValueConditions bool) error {
+	for _, apply := range []bool{false, true} {
+		if err := n.diff(oldValue, newValue, oldValueConditions, apply); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diff checks the changes between `oldValue` and `newValue` and marks them when `apply` is set,
+// list items which are not part of the tree are only added to it when `apply` is set
+func (n *Node) diff(oldValue, newValue *ddbexpr.Value, oldValueConditions, apply bool) error {
+	if equalValues(oldValue, newValue) {
+		return nil
+	}
+
+	switch n.kind {
+	case NODE_KEY:
+		return n.pathError("diff", errKeyAttributeUpdate)
+	case NODE_ATTRIBUTE:
+		// both are maps, diff the child attributes
+		if len(n.children) > 0 && isType(oldValue, ddbexpr.VALUE_M) && isType(newValue, ddbexpr.VALUE_M) {
+			errs := n.unknownDiffAttributes(oldValue, newValue)
+			for _, child := range n.children {
+				if err := child.diff(mapValue(oldValue, child.name), mapValue(newValue, child.name), oldValueConditions, apply); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			return errors.Join(errs...)
+		}
+	case NODE_LIST:
+		// both are lists, diff index by index
+		if isType(oldValue, ddbexpr.VALUE_L) && isType(newValue, ddbexpr.VALUE_L) {
+			var errs []error
+			for index := 0; index < max(len(oldValue.List), len(newValue.List)); index++ {
+				oldItemValue, newItemValue := listValue(oldValue, index), listValue(newValue, index)
+				if equalValues(oldItemValue, newItemValue) {
+					continue
+				}
+
+				listItem, err := n.diffListItem(index, apply)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+
+				if listItem.kind != NODE_ATTRIBUTE {
+					errs = append(errs, listItem.pathError("diff", errors.New("list item is not an attribute")))
+					continue
+				}
+
+				if err := listItem.diff(oldItemValue, newItemValue, oldValueConditions, apply); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			return errors.Join(errs...)
+		}
+	}
+
+	if !apply {
+		return nil
+	}
+
+	if newValue == nil {
+		n.AddValue(UPDATE_REMOVE, nil)
+	} else {
+		n.AddValue(UPDATE_SET, RawValue{*newValue})
+	}
+
+	if oldValueConditions {
+		n.AndWithCondition(OldValueCondition{Path: n.DocumentPath(), Old: oldValue})
+	}
+
+	return nil
+}
+
+// diffListItem returns the node of list item at `index`, a list item which is not part of 'this'
+// list is added to it when `apply` is set and returned detached from the tree otherwise
+func (n *Node) diffListItem(index int, apply bool) (*Node, error) {
+	if apply {
+		return n.EnsureListItem(index)
+	}
+
+	if listItem := n.ListItem(index); listItem != nil {
+		return listItem, nil
+	}
+
+	if err := n.checkListIndices(index); err != nil {
+		return nil, err
+	}
+
+	return n.createListItem(index), nil
+}
+
+// unknownDiffAttributes returns an error for every attribute which differs between the maps
+// `oldValue` and `newValue` and has no child node in 'this' node
+func (n *Node) unknownDiffAttributes(oldValue, newValue *ddbexpr.Value) []error {
+	names := []string{}
+	for _, value := range []*ddbexpr.Value{oldValue, newValue} {
+		for name := range value.Map {
+			if _, ok := n.Child(name); !ok && !slices.Contains(names, name) &&
+				!equalValues(mapValue(oldValue, name), mapValue(newValue, name)) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	errs := []error{}
+	for _, name := range names {
+		errs = append(errs, n.childPathError(name, "diff", ErrUnknownAttribute))
+	}
+
+	return errs
+}
+
+func equalValues(value, otherValue *ddbexpr.Value) bool {
+	if value == nil || otherValue == nil {
+		return value == nil && otherValue == nil
+	}
+
+	return value.Equal(*otherValue)
+}
+
+func isType(value *ddbexpr.Value, valueType ddbexpr.ValueType) bool {
+	return value != nil && value.Type == valueType
+}
+
+// mapValue returns the value of attribute `name` of a map, nil if absent
+func mapValue(value *ddbexpr.Value, name string) *ddbexpr.Value {
+	if attributeValue, ok := value.Map[name]; ok {
+		return &attributeValue
+	}
+
+	return nil
+}
+
+// listValue returns the value at `index` of a list, nil if absent
+func listValue(value *ddbexpr.Value, index int) *ddbexpr.Value {
+	if index < len(value.List) {
+		return &value.List[index]
+	}
+
+	return nil
+}
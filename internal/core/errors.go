@@ -0,0 +1,74 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+var (
+	// ErrUnknownListIndex is returned when a list item is selected by an index which can't be
+	// part of the list
+	ErrUnknownListIndex = errors.New("unknown list index")
+
+	// ErrUnknownAttribute is returned when an attribute doesn't exist in the expression builder
+	ErrUnknownAttribute = errors.New("unknown attribute")
+
+	// ErrKeyAttributeFilter is returned when a filter of a query refers to a key attribute,
+	// DynamoDB expects key attributes in the key condition instead
+	ErrKeyAttributeFilter = errors.New("key attribute cannot be used in filter")
+
+	// ErrMapListItemValue is returned when a list item of a list of maps is marked with an
+	// expression builder as value, mark the attributes of the list item instead
+	ErrMapListItemValue = errors.New("expression builder cannot be used as value of a list item")
+
+	// ErrNotNumber is returned when an attribute is incremented or decremented by a value which
+	// isn't a number
+	ErrNotNumber = errors.New("value is not a number")
+
+	// ErrUnsupportedPartiQL is returned when the marked tree has no PartiQL equivalent, e.g. ADD
+	// of a number or if_not_exists
+	ErrUnsupportedPartiQL = ddbexpr.ErrUnsupportedPartiQL
+
+	// ErrSlotOutsideCompile is returned when a slot is marshalled outside of Compile of the
+	// expression builder it was created by
+	ErrSlotOutsideCompile = errors.New("slot can only be marshalled by Compile of its expression builder")
+
+	errKeyAttributeUpdate = errors.New("key attribute cannot be updated")
+)
+
+// PathError records an error and the operation and document path of the attribute that
+// caused it
+type PathError struct {
+	// Document path of the attribute
+	Path string
+
+	// Operation that failed, e.g. "project" or "update"
+	Op string
+
+	Err error
+}
+
+func (pe *PathError) Error() string {
+	return "cannot " + pe.Op + " attribute [" + pe.Path + "]: " + pe.Err.Error()
+}
+
+func (pe *PathError) Unwrap() error {
+	return pe.Err
+}
+
+// pathError returns a *PathError of `op` on 'this' node
+func (n *Node) pathError(op string, err error) error {
+	return &PathError{Path: n.DocumentPath(), Op: op, Err: err}
+}
+
+// childPathError returns a *PathError of `op` on the child attribute `name` of 'this' node, for
+// attributes which have no node in the tree
+func (n *Node) childPathError(name, op string, err error) error {
+	path := name
+	if documentPath := n.DocumentPath(); documentPath != "" {
+		path = documentPath + DDBAtributeNameCancatenator + name
+	}
+
+	return &PathError{Path: path, Op: op, Err: err}
+}
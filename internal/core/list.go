@@ -0,0 +1,144 @@
+package core
+
+import (
+	"errors"
+	"slices"
+)
+
+// ListAppend is a value of UPDATE_SET which adds Items to the end of a list and PrependedItems
+// to its start. The sdk packages render it as list_append, see UpdateOperand
+type ListAppend struct {
+	Items          []any
+	PrependedItems []any
+}
+
+// AppendListItems marks UPDATE_SET with a ListAppend of `items` on 'this' list, `items` are added
+// to the end of the list or to its start when `prepend` is set. Items of a ListAppend marked
+// before are kept, appended items are added after them and prepended items before them. Any
+// other value is replaced
+func (n *Node) AppendListItems(items []any, prepend bool) error {
+	if n.mapListItems {
+		op := "append to"
+		if prepend {
+			op = "prepend to"
+		}
+
+		return n.pathError(op, ErrMapListItemValue)
+	}
+
+	listAppend, ok := n.value.(ListAppend)
+	if !ok || n.operation != UPDATE_SET {
+		listAppend = ListAppend{}
+	}
+
+	if prepend {
+		listAppend.PrependedItems = append(slices.Clone(items), listAppend.PrependedItems...)
+	} else {
+		listAppend.Items = append(slices.Clone(listAppend.Items), items...)
+	}
+
+	n.AddValue(UPDATE_SET, listAppend)
+	return nil
+}
+
+// RemoveListItems marks UPDATE_REMOVE on the list items at `indices` of 'this' list, list items
+// which are not yet part of the list are added to it. The removed list items are updated in the
+// order of their indices, after the other list items, see Updates
+func (n *Node) RemoveListItems(indices ...int) error {
+	if err := n.checkListIndices(indices...); err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		listItem, _ := n.EnsureListItem(index)
+		listItem.AddValue(UPDATE_REMOVE, nil)
+	}
+
+	n.removedListItems = append(n.removedListItems, indices...)
+	slices.Sort(n.removedListItems)
+	n.removedListItems = slices.Compact(n.removedListItems)
+	return nil
+}
+
+// SetListItem marks UPDATE_SET of `value` on the list item at `index` of 'this' list, the list
+// item is added to the list if not present
+func (n *Node) SetListItem(index int, value any) error {
+	if n.mapListItems {
+		return n.pathError("set list item "+ListItemName(index)+" of", ErrMapListItemValue)
+	}
+
+	listItem, err := n.EnsureListItem(index)
+	if err != nil {
+		return err
+	}
+
+	listItem.AddValue(UPDATE_SET, value)
+	return nil
+}
+
+// AddListItemRange adds the list items from index `from` up to but excluding `to` to 'this' list,
+// list items which are already part of the list are kept as they are
+func (n *Node) AddListItemRange(from, to int) error {
+	indices := make([]int, 0, max(to-from, 0))
+	for index := from; index < to; index++ {
+		indices = append(indices, index)
+	}
+
+	return n.AddListItem(indices...)
+}
+
+// IndexListItem returns the node of list item at `index`, see EnsureListItem. A list item which
+// can't be part of 'this' list is returned detached from the tree, its marks are ignored and its
+// error is reported by IndexErrors
+func (n *Node) IndexListItem(index int) *Node {
+	listItem, err := n.EnsureListItem(index)
+	if err != nil {
+		n.indexErrs = append(n.indexErrs, err)
+		return n.createListItem(index)
+	}
+
+	return listItem
+}
+
+// IndexErrors returns the errors of the list items IndexListItem couldn't add to the lists of the
+// tree below 'this' node, joined
+func (n *Node) IndexErrors() error {
+	return errors.Join(n.addIndexErrors(nil)...)
+}
+
+func (n *Node) addIndexErrors(errs []error) []error {
+	errs = append(errs, n.indexErrs...)
+	for _, child := range n.nodesBelow() {
+		errs = child.addIndexErrors(errs)
+	}
+
+	return errs
+}
+
+// ForEachListItem calls `fn` for every list item of 'this' list in the order they were added,
+// errors returned by `fn` are joined. List items are read while holding the read lock of the
+// tree which is released before `fn` is called, so `fn` can mark the list items
+func (n *Node) ForEachListItem(fn func(index int, listItem *Node) error) error {
+	unlock := n.RLockTree()
+	indices, listItems := n.ListItemIndices(), n.ListItems()
+	unlock()
+
+	var errs []error
+	for idx, index := range indices {
+		if err := fn(index, listItems[idx]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ListItemValues returns `items` as values of list items
+func ListItemValues[T any](items []T) []any {
+	values := make([]any, 0, len(items))
+	for _, item := range items {
+		values = append(values, item)
+	}
+
+	return values
+}
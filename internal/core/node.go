@@ -0,0 +1,398 @@
+// Package core is the expression builder tree shared by pkg/v1 and pkg/v2, it holds the marks,
+// document paths and traversal of the tree independent of the aws sdk version. The packages of
+// the sdks wrap its nodes with their typed attributes and render the marks into the expression
+// builders of their sdk
+//
+// Nodes of core don't lock, the public API of the sdk packages locks the tree in concurrency mode
+package core
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	DDBAtributeNameCancatenator = "."
+)
+
+type NodeKind int
+
+const (
+	// Map or primitive attribute, i.e. DynamoAttribute
+	NODE_ATTRIBUTE NodeKind = iota
+	// List attribute, i.e. DynamoListAttribute
+	NODE_LIST
+	// Primary key attribute, i.e. DynamoKeyAttribute
+	NODE_KEY
+)
+
+func (nk NodeKind) String() string {
+	switch nk {
+	case NODE_ATTRIBUTE:
+		return "ATTRIBUTE"
+	case NODE_LIST:
+		return "LIST"
+	case NODE_KEY:
+		return "KEY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Operation mirrors DynamoOperation of the sdk packages
+type Operation int
+
+const (
+	NO_OP Operation = iota
+	GET
+	UPDATE_SET
+	UPDATE_REMOVE
+	UPDATE_ADD
+	UPDATE_DELETE
+)
+
+func (o Operation) String() string {
+	switch o {
+	case NO_OP:
+		return "NO_OP"
+	case GET:
+		return "GET"
+	case UPDATE_SET:
+		return "UPDATE_SET"
+	case UPDATE_REMOVE:
+		return "UPDATE_REMOVE"
+	case UPDATE_ADD:
+		return "UPDATE_ADD"
+	case UPDATE_DELETE:
+		return "UPDATE_DELETE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Node is a node of the expression builder tree
+type Node struct {
+	kind NodeKind
+
+	// Mark 'this' node for projection
+	projection bool
+
+	// Name of the dynamo attribute as defined in DB
+	name string
+
+	// Attribute or list 'this' node is attached below, nil for the root
+	parent *Node
+
+	// Conditions applied on 'this' node in order, sdk condition builders for attributes and sdk
+	// key condition builders for key attributes, or sdk independent conditions like
+	// OldValueCondition. They are rendered and joined using `AND` by the sdk packages
+	conditions []any
+
+	// Filters applied on 'this' node in order, sdk condition builders which are rendered and
+	// joined using `AND` by the sdk packages. Key attributes can't be filtered
+	filters []any
+
+	// Determines the operation which needs to performed on
+	// 'this' node
+	operation Operation
+
+	// Operation replaced by a later AddValue with a different
+	// operation, reported as a mark conflict
+	replacedOperation Operation
+
+	// Represent the value which needs to be assigned to 'this'
+	// node for update
+	value any
+
+	// Child nodes of an attribute
+	children []*Node
+
+	// Defines the order of index added, this helps in maintaining
+	// order when generating projection
+	orderOfListItems []int
+
+	// List items of a list attribute
+	listItems map[int]*Node
+
+	// Indices of the list items marked by RemoveListItems, sorted and without duplicates
+	removedListItems []int
+
+	// Errors of list items which couldn't be added to 'this' list by IndexListItem
+	indexErrs []error
+
+	// Creates the node of list item at an index, set by the typed list attribute
+	newListItem func(index int) *Node
+
+	// List items are maps, whose expression builders can't be used as values of list items
+	mapListItems bool
+
+	// Typed attribute of the sdk package wrapping 'this' node
+	owner any
+
+	// Lock shared by every node of the tree in concurrency mode, nil otherwise
+	lock *sync.RWMutex
+}
+
+func NewNode(kind NodeKind) *Node {
+	return &Node{
+		kind:      kind,
+		listItems: map[int]*Node{},
+	}
+}
+
+func (n *Node) Kind() NodeKind {
+	return n.kind
+}
+
+func (n *Node) Name() string {
+	return n.name
+}
+
+// SetName sets the name of the dynamo attribute as defined in DB
+func (n *Node) SetName(name string) {
+	n.name = name
+}
+
+// DocumentPath returns the document path of 'this' node, it is computed from the names of the
+// nodes above it on every call
+func (n *Node) DocumentPath() string {
+	if n.parent == nil {
+		return n.constructDocumentPath("")
+	}
+
+	return n.constructDocumentPath(n.parent.DocumentPath())
+}
+
+// Projected returns true if 'this' node is marked for projection
+func (n *Node) Projected() bool {
+	return n.projection
+}
+
+// Operation returns the operation and value 'this' node is marked for update with
+func (n *Node) Operation() (Operation, any) {
+	return n.operation, n.value
+}
+
+// Owner returns the typed attribute of the sdk package wrapping 'this' node
+func (n *Node) Owner() any {
+	return n.owner
+}
+
+func (n *Node) SetOwner(owner any) {
+	n.owner = owner
+}
+
+// AddChild attaches `child` below 'this' attribute
+func (n *Node) AddChild(child *Node) {
+	child.parent = n
+	n.children = append(n.children, child)
+}
+
+// Children returns the child nodes of an attribute
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// SetListItemFactory sets the function creating the node of a list item at an index
+func (n *Node) SetListItemFactory(newListItem func(index int) *Node) {
+	n.newListItem = newListItem
+}
+
+// SetMapListItems records that the list items of 'this' list are maps, AppendListItems and
+// SetListItem return an error wrapping ErrMapListItemValue for such a list
+func (n *Node) SetMapListItems() {
+	n.mapListItems = true
+}
+
+// ListItem returns the node of list item at `index`, nil if it is not part of the list
+func (n *Node) ListItem(index int) *Node {
+	return n.listItems[index]
+}
+
+// ListItems returns the nodes of the list items in the order they were added
+func (n *Node) ListItems() []*Node {
+	listItems := make([]*Node, 0, len(n.orderOfListItems))
+	for _, index := range n.orderOfListItems {
+		listItems = append(listItems, n.listItems[index])
+	}
+
+	return listItems
+}
+
+// ListItemIndices returns the indices of the list items in the order they were added
+func (n *Node) ListItemIndices() []int {
+	return slices.Clone(n.orderOfListItems)
+}
+
+// Project marks 'this' node for projection
+func (n *Node) Project() error {
+	n.projection = true
+	return nil
+}
+
+// AndWithCondition adds `condition` to the conditions of 'this' node
+func (n *Node) AndWithCondition(condition any) {
+	n.conditions = append(n.conditions, condition)
+}
+
+// AndWithFilter adds `filter` to the filters of 'this' node
+func (n *Node) AndWithFilter(filter any) {
+	n.filters = append(n.filters, filter)
+}
+
+// AddValue adds a value which will be used to update 'this' node
+func (n *Node) AddValue(operation Operation, value any) {
+	if n.operation != NO_OP && operation != NO_OP && n.operation != operation {
+		n.replacedOperation = n.operation
+	}
+	n.operation = operation
+
+	// TODO: time.Time will automatically convert to string
+	// add a switch case hee and allow clients to specify
+	// which data type they want time to be in
+	n.value = value
+}
+
+// AddListItem adds the nodes of list items at `indices` to 'this' list, list items which are
+// already part of the list are kept as they are
+func (n *Node) AddListItem(indices ...int) error {
+	if err := n.checkListIndices(indices...); err != nil {
+		return err
+	}
+
+	// currently, we are not allowing nested lists
+	for _, index := range indices {
+		n.EnsureListItem(index)
+	}
+
+	return nil
+}
+
+// EnsureListItem returns the node of list item at `index`, the node is added to the list if not
+// present
+func (n *Node) EnsureListItem(index int) (*Node, error) {
+	if listItem, ok := n.listItems[index]; ok {
+		return listItem, nil
+	}
+
+	if err := n.checkListIndices(index); err != nil {
+		return nil, err
+	}
+
+	listItem := n.createListItem(index)
+	n.orderOfListItems = append(n.orderOfListItems, index)
+	n.listItems[index] = listItem
+	return listItem, nil
+}
+
+// checkListIndices returns an error for every index which can't be part of 'this' list
+func (n *Node) checkListIndices(indices ...int) error {
+	var errs []error
+	for _, index := range indices {
+		if index < 0 {
+			errs = append(errs, n.pathError("add list item "+ListItemName(index)+" to", ErrUnknownListIndex))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// createListItem creates the node of list item at `index` below 'this' list sharing its lock
+func (n *Node) createListItem(index int) *Node {
+	var listItem *Node
+	if n.newListItem != nil {
+		listItem = n.newListItem(index)
+	} else {
+		listItem = NewNode(NODE_ATTRIBUTE)
+		listItem.SetName(ListItemName(index))
+	}
+
+	listItem.parent = n
+	if n.lock != nil {
+		listItem.SetLock(n.lock)
+	}
+
+	return listItem
+}
+
+// ListItemName returns the name of the list item at `index`
+func ListItemName(index int) string {
+	return "[" + strconv.Itoa(index) + "]"
+}
+
+func (n *Node) constructDocumentPath(documentPathOfParent string) string {
+	if n.kind == NODE_KEY {
+		return n.name
+	}
+
+	if strings.HasSuffix(n.name, "]") { // this is a top level element of a list, don't use DDBAtributeNameCancatenator
+		return documentPathOfParent + n.name
+	}
+
+	if documentPathOfParent == "" { // this is top level attribute
+		return n.name
+	}
+
+	return documentPathOfParent + DDBAtributeNameCancatenator + n.name
+}
+
+// Child returns the child node of an attribute with `name`
+func (n *Node) Child(name string) (*Node, bool) {
+	for _, child := range n.children {
+		if child.name == name {
+			return child, true
+		}
+	}
+
+	return nil, false
+}
+
+// SetLock sets the lock shared by every node of the tree on 'this' node and every node below it
+func (n *Node) SetLock(lock *sync.RWMutex) {
+	n.lock = lock
+	for _, child := range n.children {
+		child.SetLock(lock)
+	}
+
+	for _, listItem := range n.listItems {
+		listItem.SetLock(lock)
+	}
+}
+
+// EnsureLock sets a new lock shared by every node of the tree of 'this' root node, a tree which
+// already has a lock keeps it
+func (n *Node) EnsureLock() {
+	if n.lock == nil {
+		n.SetLock(&sync.RWMutex{})
+	}
+}
+
+// Lock returns the lock shared by every node of the tree, nil when not in concurrency mode
+func (n *Node) Lock() *sync.RWMutex {
+	return n.lock
+}
+
+// LockTree locks the tree of 'this' node for writing in concurrency mode, returns the function
+// unlocking it
+func (n *Node) LockTree() func() {
+	if n.lock == nil {
+		return func() {}
+	}
+
+	n.lock.Lock()
+	return n.lock.Unlock
+}
+
+// RLockTree locks the tree of 'this' node for reading in concurrency mode, returns the function
+// unlocking it
+func (n *Node) RLockTree() func() {
+	if n.lock == nil {
+		return func() {}
+	}
+
+	n.lock.RLock()
+	return n.lock.RUnlock
+}
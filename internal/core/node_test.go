@@ -0,0 +1,158 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTree returns root{pk(key), name, details{city}, phones[]}
+func newTestTree() (root, name, city, phones *Node) {
+	root = NewNode(NODE_ATTRIBUTE)
+	pk := NewNode(NODE_KEY)
+	pk.SetName("pk")
+	name = NewNode(NODE_ATTRIBUTE)
+	name.SetName("name")
+	details := NewNode(NODE_ATTRIBUTE)
+	details.SetName("details")
+	city = NewNode(NODE_ATTRIBUTE)
+	city.SetName("city")
+	details.AddChild(city)
+	phones = NewNode(NODE_LIST)
+	phones.SetName("phones")
+
+	root.AddChild(pk)
+	root.AddChild(name)
+	root.AddChild(details)
+	root.AddChild(phones)
+	return root, name, city, phones
+}
+
+func TestNodeTraversal(t *testing.T) {
+	root, name, city, phones := newTestTree()
+	if err := phones.AddListItem(2, 0); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	// list items already part of the list are kept
+	phones.ListItem(2).Project()
+	assert.Nil(t, phones.AddListItem(2))
+	assert.True(t, phones.ListItem(2).Projected())
+
+	_, err := phones.EnsureListItem(-1)
+	assert.EqualError(t, err, "cannot add list item [-1] to attribute [phones]: unknown list index")
+	assert.ErrorIs(t, err, ErrUnknownListIndex)
+	assert.Equal(t, "details.city", city.DocumentPath())
+	assert.Equal(t, "phones[2]", phones.ListItem(2).DocumentPath())
+
+	// list items keep the order they were added in
+	phones.ListItem(0).Project()
+	city.Project()
+	paths := root.ProjectionPaths()
+	assert.Equal(t, []string{"pk", "details.city", "phones[2]", "phones[0]"}, paths)
+
+	name.AddValue(UPDATE_SET, "a")
+	name.AddValue(UPDATE_REMOVE, nil)
+	phones.AddValue(UPDATE_SET, []string{})
+	phones.ListItem(0).AddValue(UPDATE_SET, "b")
+	updates := root.Updates()
+	assert.Equal(t, []Update{{Path: "name", Operation: UPDATE_REMOVE}, {Path: "phones", Operation: UPDATE_SET, Value: []string{}}}, updates)
+
+	name.AndWithCondition("c1")
+	name.AndWithCondition("c2")
+	city.AndWithCondition("c3")
+	assert.Equal(t, [][]any{{"c1", "c2"}, {"c3"}}, root.Conditions())
+
+	conflicts := root.Conflicts()
+	if assert.Equal(t, 2, len(conflicts)) {
+		assert.Equal(t, MARK_CONFLICT_REPLACED_UPDATE, conflicts[0].Kind)
+		assert.Equal(t, MARK_CONFLICT_SHADOWED_UPDATE, conflicts[1].Kind)
+		assert.Equal(t, "phones[0]", conflicts[1].Path)
+	}
+
+	// marks and list items are copied onto a new tree
+	clone, _, _, _ := newTestTree()
+	root.CopyTo(clone)
+	assert.Equal(t, paths, clone.ProjectionPaths())
+
+	root.Reset()
+	assert.Nil(t, phones.ListItem(0))
+	assert.Empty(t, root.Conditions())
+}
+
+func TestNodeDiff(t *testing.T) {
+	root, _, _, phones := newTestTree()
+
+	oldValue := &ddbexpr.Value{Type: ddbexpr.VALUE_M, Map: map[string]ddbexpr.Value{
+		"pk":     {Type: ddbexpr.VALUE_S, String: "1"},
+		"name":   {Type: ddbexpr.VALUE_S, String: "a"},
+		"phones": {Type: ddbexpr.VALUE_L, List: []ddbexpr.Value{{Type: ddbexpr.VALUE_S, String: "x"}}},
+	}}
+	newValue := &ddbexpr.Value{Type: ddbexpr.VALUE_M, Map: map[string]ddbexpr.Value{
+		"pk":     {Type: ddbexpr.VALUE_S, String: "1"},
+		"phones": {Type: ddbexpr.VALUE_L, List: []ddbexpr.Value{{Type: ddbexpr.VALUE_S, String: "x"}, {Type: ddbexpr.VALUE_S, String: "y"}}},
+	}}
+	if err := root.Diff(oldValue, newValue, true); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	updates := root.Updates()
+	assert.Equal(t, []Update{
+		{Path: "name", Operation: UPDATE_REMOVE},
+		{Path: "phones[1]", Operation: UPDATE_SET, Value: RawValue{Value: ddbexpr.Value{Type: ddbexpr.VALUE_S, String: "y"}}},
+	}, updates)
+	assert.NotNil(t, phones.ListItem(1))
+	assert.Equal(t, [][]any{
+		{OldValueCondition{Path: "name", Old: &ddbexpr.Value{Type: ddbexpr.VALUE_S, String: "a"}}},
+		{OldValueCondition{Path: "phones[1]"}},
+	}, root.Conditions())
+
+	newValue.Map["pk"] = ddbexpr.Value{Type: ddbexpr.VALUE_S, String: "2"}
+	var pathErr *PathError
+	if assert.ErrorAs(t, root.Diff(oldValue, newValue, false), &pathErr) {
+		assert.Equal(t, "pk", pathErr.Path)
+		assert.EqualError(t, pathErr, "cannot diff attribute [pk]: key attribute cannot be updated")
+	}
+}
+
+func TestNodeWalk(t *testing.T) {
+	root, name, city, phones := newTestTree()
+	phones.SetListItemFactory(func(index int) *Node {
+		listItem := NewNode(NODE_ATTRIBUTE)
+		listItem.SetName(ListItemName(index))
+		return listItem
+	})
+	if err := phones.AddListItemRange(0, 2); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	kinds := []WalkKind{}
+	err := root.Walk(func(node *Node) error {
+		kinds = append(kinds, node.WalkKind())
+		if node == city.parent {
+			return SkipChildren
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []WalkKind{WALK_STRUCT, WALK_KEY, WALK_LEAF, WALK_STRUCT, WALK_LIST, WALK_LEAF, WALK_LEAF}, kinds)
+
+	name.AddSetOperation(SetOperation{Kind: SET_IF_NOT_EXISTS, Value: 0})
+	name.AddSetOperation(SetOperation{Kind: SET_PLUS, Value: 1})
+	_, value := name.Operation()
+	operand, ok := UpdateOperand("name", value)
+	assert.True(t, ok)
+	assert.Equal(t, FunctionOperand(OPERAND_PLUS,
+		FunctionOperand(OPERAND_IF_NOT_EXISTS, NameOperand("name"), ValueOperand(0)),
+		ValueOperand(1),
+	), operand)
+
+	_, ok = UpdateOperand("name", "John")
+	assert.False(t, ok)
+}
@@ -0,0 +1,89 @@
+package core
+
+type OperandKind int
+
+const (
+	OPERAND_NAME OperandKind = iota
+	OPERAND_VALUE
+	OPERAND_SIZE
+	OPERAND_IF_NOT_EXISTS
+	OPERAND_PLUS
+	OPERAND_MINUS
+	OPERAND_LIST_APPEND
+)
+
+func (ok OperandKind) String() string {
+	switch ok {
+	case OPERAND_NAME:
+		return "NAME"
+	case OPERAND_VALUE:
+		return "VALUE"
+	case OPERAND_SIZE:
+		return "SIZE"
+	case OPERAND_IF_NOT_EXISTS:
+		return "IF_NOT_EXISTS"
+	case OPERAND_PLUS:
+		return "PLUS"
+	case OPERAND_MINUS:
+		return "MINUS"
+	case OPERAND_LIST_APPEND:
+		return "LIST_APPEND"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Operand is a value computed by dynamo db which is marked for UPDATE_SET, e.g.
+// `if_not_exists(a, :d) + :n`. The sdk packages render it into an operand builder of their sdk
+type Operand struct {
+	Kind OperandKind
+
+	// Document path of OPERAND_NAME and OPERAND_SIZE
+	Path string
+
+	// Value of OPERAND_VALUE, a RawValue or a value for the marshaller of the sdk
+	Value any
+
+	// Arguments of OPERAND_IF_NOT_EXISTS (the attribute and its default), OPERAND_PLUS,
+	// OPERAND_MINUS and OPERAND_LIST_APPEND
+	Arguments []Operand
+}
+
+// NameOperand returns the operand of the attribute at `path`
+func NameOperand(path string) Operand {
+	return Operand{Kind: OPERAND_NAME, Path: path}
+}
+
+// ValueOperand returns the operand of `value`
+func ValueOperand(value any) Operand {
+	return Operand{Kind: OPERAND_VALUE, Value: value}
+}
+
+// FunctionOperand returns the operand of `kind` applied on `arguments`
+func FunctionOperand(kind OperandKind, arguments ...Operand) Operand {
+	return Operand{Kind: kind, Arguments: arguments}
+}
+
+// UpdateOperand returns the operand computing `value` marked for UPDATE_SET on the attribute at
+// `path`, false if `value` is set as it is
+func UpdateOperand(path string, value any) (Operand, bool) {
+	switch valueType := value.(type) {
+	case ListAppend:
+		// list_append(:prepended, list_append(path, :appended))
+		operand := NameOperand(path)
+		if len(valueType.Items) > 0 {
+			operand = FunctionOperand(OPERAND_LIST_APPEND, operand, ValueOperand(valueType.Items))
+		}
+		if len(valueType.PrependedItems) > 0 {
+			operand = FunctionOperand(OPERAND_LIST_APPEND, ValueOperand(valueType.PrependedItems), operand)
+		}
+
+		return operand, true
+	case SetOperation:
+		return valueType.operand(path), true
+	case Operand:
+		return valueType, true
+	default:
+		return Operand{}, false
+	}
+}
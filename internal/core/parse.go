@@ -0,0 +1,385 @@
+package core
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+type ExpressionKind int
+
+const (
+	EXPRESSION_KEY_CONDITION ExpressionKind = iota
+	EXPRESSION_CONDITION
+	EXPRESSION_PROJECTION
+	EXPRESSION_UPDATE
+	EXPRESSION_FILTER
+)
+
+func (ek ExpressionKind) String() string {
+	switch ek {
+	case EXPRESSION_KEY_CONDITION:
+		return "KEY_CONDITION"
+	case EXPRESSION_CONDITION:
+		return "CONDITION"
+	case EXPRESSION_PROJECTION:
+		return "PROJECTION"
+	case EXPRESSION_UPDATE:
+		return "UPDATE"
+	case EXPRESSION_FILTER:
+		return "FILTER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParsedCondition is a condition, key condition or filter of a parsed expression marked on a
+// node, value placeholders of Condition are resolved using Values. The sdk packages render it
+// into a condition builder or a key condition builder of their sdk
+type ParsedCondition struct {
+	Condition *ddbexpr.Condition
+	Values    map[string]ddbexpr.Value
+}
+
+// Value returns the value of the value placeholder of `operand`
+func (pc ParsedCondition) Value(operand ddbexpr.Operand) ddbexpr.Value {
+	return pc.Values[operand.ValuePlaceholder]
+}
+
+// ParseExpression parses an expression of `kind` and marks it on the tree of 'this' root node,
+// names and values resolve the placeholders of the expression
+//
+// Every document path of the expression is resolved to a node of the tree, list items which are
+// not yet part of the tree are added to it. If document paths don't exist in the tree an
+// *ddbexpr.UnknownPathsError listing all of them is returned and the tree is left unchanged
+//
+// Top level conjuncts of a condition or filter are marked on the node of their first document
+// path, or on the root when that is a key attribute of a condition
+func (n *Node) ParseExpression(kind ExpressionKind, expr string, names map[string]string, values map[string]ddbexpr.Value) error {
+	parser := &expressionParser{kind: kind, values: values}
+	marks, err := parser.parse(expr, names)
+	if err != nil {
+		return err
+	}
+
+	unknownPaths := []string{}
+	for _, path := range parser.paths {
+		if _, ok := n.Resolve(path, false); !ok && !slices.Contains(unknownPaths, path.String()) {
+			unknownPaths = append(unknownPaths, path.String())
+		}
+	}
+
+	if len(unknownPaths) > 0 {
+		return &ddbexpr.UnknownPathsError{Paths: unknownPaths}
+	}
+
+	// every mark is validated before the tree is changed
+	for _, apply := range []bool{false, true} {
+		for _, mark := range marks {
+			node, _ := n.Resolve(mark.path, apply)
+			if node.Kind() == NODE_KEY && kind == EXPRESSION_CONDITION {
+				node = n
+			}
+
+			if err := mark.markOn(node, kind, apply); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parsedMark is the part of a parsed expression which is marked on the node of `path`
+type parsedMark struct {
+	path ddbexpr.Path
+
+	// Condition of EXPRESSION_KEY_CONDITION, EXPRESSION_CONDITION and EXPRESSION_FILTER
+	condition ParsedCondition
+
+	// Operation and value of EXPRESSION_UPDATE
+	operation Operation
+	value     any
+}
+
+// expressionParser converts a parsed expression into marks of the tree
+type expressionParser struct {
+	kind   ExpressionKind
+	values map[string]ddbexpr.Value
+
+	// Every document path referenced by the expression
+	paths []ddbexpr.Path
+}
+
+func (p *expressionParser) parse(expr string, names map[string]string) ([]parsedMark, error) {
+	marks := []parsedMark{}
+	switch p.kind {
+	case EXPRESSION_PROJECTION:
+		paths, err := ddbexpr.ParseProjection(expr, names)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			p.paths = append(p.paths, path)
+			marks = append(marks, parsedMark{path: path})
+		}
+	case EXPRESSION_KEY_CONDITION, EXPRESSION_CONDITION, EXPRESSION_FILTER:
+		condition, err := ddbexpr.ParseCondition(expr, names)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, conjunct := range conjuncts(condition) {
+			if p.kind == EXPRESSION_KEY_CONDITION {
+				err = p.checkKeyCondition(conjunct)
+			} else {
+				err = p.checkCondition(conjunct)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			paths := conjunct.Paths()
+			if len(paths) == 0 {
+				return nil, errors.New("condition [" + conjunct.Text + "] doesn't use any document path")
+			}
+
+			p.paths = append(p.paths, paths...)
+			marks = append(marks, parsedMark{path: paths[0], condition: ParsedCondition{Condition: conjunct, Values: p.values}})
+		}
+	case EXPRESSION_UPDATE:
+		actions, err := ddbexpr.ParseUpdate(expr, names)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, action := range actions {
+			p.paths = append(p.paths, action.Path)
+			mark := parsedMark{path: action.Path}
+			switch action.Kind {
+			case ddbexpr.UPDATE_ACTION_SET:
+				mark.operation = UPDATE_SET
+				mark.value, err = p.setValue(action.Value)
+			case ddbexpr.UPDATE_ACTION_REMOVE:
+				mark.operation = UPDATE_REMOVE
+			case ddbexpr.UPDATE_ACTION_ADD:
+				mark.operation = UPDATE_ADD
+				mark.value, err = p.actionValue(action)
+			case ddbexpr.UPDATE_ACTION_DELETE:
+				mark.operation = UPDATE_DELETE
+				mark.value, err = p.actionValue(action)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			marks = append(marks, mark)
+		}
+	default:
+		return nil, errors.New("unknown expression kind " + p.kind.String())
+	}
+
+	return marks, nil
+}
+
+// conjuncts splits the top level `AND` of a condition
+func conjuncts(condition *ddbexpr.Condition) []*ddbexpr.Condition {
+	if condition.Kind != ddbexpr.CONDITION_AND {
+		return []*ddbexpr.Condition{condition}
+	}
+
+	out := []*ddbexpr.Condition{}
+	for _, subCondition := range condition.Conditions {
+		out = append(out, conjuncts(subCondition)...)
+	}
+
+	return out
+}
+
+// Comparators which can be used in a condition
+var comparators = []string{"=", "<>", "<", "<=", ">", ">="}
+
+// checkCondition checks that `condition` can be rendered by the builders of the sdks
+func (p *expressionParser) checkCondition(condition *ddbexpr.Condition) error {
+	for _, subCondition := range condition.Conditions {
+		if err := p.checkCondition(subCondition); err != nil {
+			return err
+		}
+	}
+
+	if condition.Kind == ddbexpr.CONDITION_FUNCTION && condition.Operator != "attribute_exists" && condition.Operator != "attribute_not_exists" {
+		// the builders of sdk accept only a string as second argument
+		return p.checkStringValue(condition.Operands[1], condition)
+	}
+
+	if condition.Kind == ddbexpr.CONDITION_COMPARE && !slices.Contains(comparators, condition.Operator) {
+		return errors.New("unsupported comparator " + condition.Operator + " in condition [" + condition.Text + "]")
+	}
+
+	for _, operand := range condition.Operands {
+		if err := p.checkOperand(operand); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkKeyCondition checks that `condition` is a key condition on a single key attribute
+func (p *expressionParser) checkKeyCondition(condition *ddbexpr.Condition) error {
+	invalid := errors.New("condition [" + condition.Text + "] is not a valid key condition")
+	if len(condition.Operands) == 0 || condition.Operands[0].Kind != ddbexpr.OPERAND_PATH || len(condition.Operands[0].Path) != 1 {
+		return invalid
+	}
+
+	if condition.Kind == ddbexpr.CONDITION_FUNCTION && condition.Operator == "begins_with" {
+		return p.checkStringValue(condition.Operands[1], condition)
+	}
+
+	if condition.Kind != ddbexpr.CONDITION_BETWEEN && (condition.Kind != ddbexpr.CONDITION_COMPARE || condition.Operator == "<>") {
+		return invalid
+	}
+
+	for _, operand := range condition.Operands[1:] {
+		if operand.Kind != ddbexpr.OPERAND_VALUE {
+			return invalid
+		}
+
+		if err := p.checkOperand(operand); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *expressionParser) checkOperand(operand ddbexpr.Operand) error {
+	if operand.Kind != ddbexpr.OPERAND_VALUE {
+		return nil
+	}
+
+	_, err := p.value(operand.ValuePlaceholder)
+	return err
+}
+
+// checkStringValue checks that `operand` used as argument of `condition` is a string value
+func (p *expressionParser) checkStringValue(operand ddbexpr.Operand, condition *ddbexpr.Condition) error {
+	if operand.Kind != ddbexpr.OPERAND_VALUE {
+		return errors.New("argument of " + condition.Operator + " must be a value placeholder in condition [" + condition.Text + "]")
+	}
+
+	value, err := p.value(operand.ValuePlaceholder)
+	if err != nil {
+		return err
+	}
+
+	if value.Value.Type != ddbexpr.VALUE_S {
+		return errors.New("only string argument of " + condition.Operator + " is supported in condition [" + condition.Text + "]")
+	}
+
+	return nil
+}
+
+// setValue returns the value marked for UPDATE_SET, either the attribute value itself or an
+// Operand when the value is computed
+func (p *expressionParser) setValue(value *ddbexpr.UpdateValue) (any, error) {
+	if value.Kind == ddbexpr.UPDATE_VALUE_OPERAND && value.Operand.Kind == ddbexpr.OPERAND_VALUE {
+		return p.value(value.Operand.ValuePlaceholder)
+	}
+
+	return p.updateOperand(value)
+}
+
+// actionValue returns the value of ADD and DELETE actions, which is always a value placeholder
+func (p *expressionParser) actionValue(action ddbexpr.UpdateAction) (any, error) {
+	if action.Value.Kind != ddbexpr.UPDATE_VALUE_OPERAND || action.Value.Operand.Kind != ddbexpr.OPERAND_VALUE {
+		return nil, errors.New("value of " + action.Kind.String() + " must be a value placeholder in [" + action.Text + "]")
+	}
+
+	return p.value(action.Value.Operand.ValuePlaceholder)
+}
+
+func (p *expressionParser) updateOperand(value *ddbexpr.UpdateValue) (Operand, error) {
+	if value.Kind == ddbexpr.UPDATE_VALUE_OPERAND {
+		return p.operand(value.Operand)
+	}
+
+	arguments := make([]Operand, 0, len(value.Arguments))
+	for _, argument := range value.Arguments {
+		operand, err := p.updateOperand(argument)
+		if err != nil {
+			return Operand{}, err
+		}
+		arguments = append(arguments, operand)
+	}
+
+	switch value.Kind {
+	case ddbexpr.UPDATE_VALUE_IF_NOT_EXISTS:
+		return FunctionOperand(OPERAND_IF_NOT_EXISTS, arguments...), nil
+	case ddbexpr.UPDATE_VALUE_PLUS:
+		return FunctionOperand(OPERAND_PLUS, arguments...), nil
+	case ddbexpr.UPDATE_VALUE_MINUS:
+		return FunctionOperand(OPERAND_MINUS, arguments...), nil
+	default:
+		return FunctionOperand(OPERAND_LIST_APPEND, arguments...), nil
+	}
+}
+
+func (p *expressionParser) operand(operand ddbexpr.Operand) (Operand, error) {
+	switch operand.Kind {
+	case ddbexpr.OPERAND_PATH:
+		p.paths = append(p.paths, operand.Path)
+		return NameOperand(operand.Path.String()), nil
+	case ddbexpr.OPERAND_SIZE:
+		p.paths = append(p.paths, operand.Path)
+		return Operand{Kind: OPERAND_SIZE, Path: operand.Path.String()}, nil
+	default:
+		value, err := p.value(operand.ValuePlaceholder)
+		if err != nil {
+			return Operand{}, err
+		}
+
+		return ValueOperand(value), nil
+	}
+}
+
+func (p *expressionParser) value(placeholder string) (RawValue, error) {
+	value, ok := p.values[placeholder]
+	if !ok {
+		return RawValue{}, errors.New("value placeholder " + placeholder + " is not defined in expression attribute values")
+	}
+
+	return RawValue{Value: value}, nil
+}
+
+// markOn marks `this` part of a parsed expression of `kind` on `node`, the mark is only
+// validated when `apply` is false
+func (pm parsedMark) markOn(node *Node, kind ExpressionKind, apply bool) error {
+	switch {
+	case kind == EXPRESSION_KEY_CONDITION && node.Kind() != NODE_KEY:
+		return errors.New("attribute " + pm.path.String() + " is not a key attribute, cannot be used in key condition")
+	case kind == EXPRESSION_UPDATE && node.Kind() == NODE_KEY:
+		return errors.New("key attribute " + node.Name() + " cannot be updated")
+	}
+
+	if !apply {
+		return nil
+	}
+
+	switch kind {
+	case EXPRESSION_KEY_CONDITION, EXPRESSION_CONDITION:
+		node.AndWithCondition(pm.condition)
+	case EXPRESSION_FILTER:
+		node.AndWithFilter(pm.condition)
+	case EXPRESSION_PROJECTION:
+		return node.Project()
+	case EXPRESSION_UPDATE:
+		node.AddValue(pm.operation, pm.value)
+	}
+
+	return nil
+}
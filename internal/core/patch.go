@@ -0,0 +1,200 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/utils"
+)
+
+type PatchOptions struct {
+	// Document paths of the attributes set to null
+	NullPaths []string
+
+	// Attributes of NullPaths are marked UPDATE_REMOVE instead of setting null
+	NullAsRemove bool
+
+	// Nested structs replace the whole map instead of being patched field by field
+	ReplaceNested bool
+}
+
+type PatchOption func(*PatchOptions)
+
+// WithNull sets the attributes at document paths `paths` to null, e.g. "name" or
+// "family_details.is_married", whatever their field in the patch holds. Fields left unchanged
+// can't express an explicit null, so nulls are given by their document paths instead
+func WithNull(paths ...string) PatchOption {
+	return func(options *PatchOptions) {
+		options.NullPaths = append(options.NullPaths, paths...)
+	}
+}
+
+// WithNullAsRemove removes the attributes of WithNull instead of setting them to null
+func WithNullAsRemove() PatchOption {
+	return func(options *PatchOptions) {
+		options.NullAsRemove = true
+	}
+}
+
+// WithReplaceNested sets the nested structs of the patch as a whole, replacing the map
+// stored in dynamo db. By default nested structs are patched field by field
+func WithReplaceNested() PatchOption {
+	return func(options *PatchOptions) {
+		options.ReplaceNested = true
+	}
+}
+
+// Patch marks UPDATE_SET on the child attributes of 'this' root node whose field in `patch`, a
+// struct or a pointer to struct, is set and then sets the attributes of WithNull to null, see
+// ApplyPatch of the sdk packages. Every field and null path is checked before the tree is
+// changed, on error the tree is left unchanged
+func (n *Node) Patch(patch any, opts ...PatchOption) error {
+	options := PatchOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	structValue := reflect.ValueOf(patch)
+	for structValue.Kind() == reflect.Pointer && !structValue.IsNil() {
+		structValue = structValue.Elem()
+	}
+
+	if structValue.Kind() != reflect.Struct {
+		return errors.New("patch must be a struct or a pointer to struct")
+	}
+
+	for _, apply := range []bool{false, true} {
+		errs := []error{patchStruct(n, structValue, options, apply)}
+		for _, nullPath := range options.NullPaths {
+			errs = append(errs, n.patchNull(nullPath, options, apply))
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// patchNull sets the attribute at document path `nullPath` below 'this' node to null, or removes
+// it with NullAsRemove. The attribute is only checked unless `apply` is set
+func (n *Node) patchNull(nullPath string, options PatchOptions, apply bool) error {
+	path, err := ddbexpr.ParsePath(nullPath)
+	if err != nil {
+		return &PathError{Path: nullPath, Op: "set null on", Err: err}
+	}
+
+	node, err := n.ResolvePath(path, apply)
+	if err != nil {
+		return err
+	}
+
+	if node.kind == NODE_KEY {
+		return node.pathError("set null on", errKeyAttributeUpdate)
+	}
+
+	if !apply {
+		return nil
+	}
+
+	if options.NullAsRemove {
+		node.AddValue(UPDATE_REMOVE, nil)
+	} else {
+		node.AddValue(UPDATE_SET, nil)
+	}
+
+	return nil
+}
+
+// patchStruct patches the child attributes of map `parent` from the fields of `structValue`, the
+// fields are only checked unless `apply` is set
+func patchStruct(parent *Node, structValue reflect.Value, options PatchOptions, apply bool) error {
+	childrenByName := make(map[string]*Node, len(parent.children))
+	for _, child := range parent.children {
+		childrenByName[child.name] = child
+	}
+
+	structType := structValue.Type()
+	var errs []error
+	for idx := 0; idx < structType.NumField(); idx++ {
+		field, fieldValue := structType.Field(idx), structValue.Field(idx)
+		name, ok := utils.AttributeName(field)
+		if !ok {
+			continue
+		}
+
+		// fields of embedded struct are marshalled as fields of the parent
+		if field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct {
+			for fieldValue.Kind() == reflect.Pointer && !fieldValue.IsNil() {
+				fieldValue = fieldValue.Elem()
+			}
+
+			if fieldValue.Kind() == reflect.Struct {
+				if err := patchStruct(parent, fieldValue, options, apply); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		if isUnchanged(fieldValue) {
+			continue
+		}
+
+		child, ok := childrenByName[name]
+		if !ok {
+			errs = append(errs, parent.childPathError(name, "patch", fmt.Errorf("field %s of patch has no attribute in expression builder: %w", field.Name, ErrUnknownAttribute)))
+			continue
+		}
+
+		if err := child.addPatch(fieldValue, options, apply); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isUnchanged returns true if the field of patch doesn't change the attribute
+func isUnchanged(fieldValue reflect.Value) bool {
+	switch fieldValue.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return fieldValue.IsNil()
+	default:
+		return fieldValue.IsZero()
+	}
+}
+
+// indirectType returns the type `t` points to, following every pointer
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func (n *Node) addPatch(value reflect.Value, options PatchOptions, apply bool) error {
+	// key attributes identify the item and can't be updated
+	if n.kind == NODE_KEY {
+		return nil
+	}
+
+	structValue := value
+	for structValue.Kind() == reflect.Pointer {
+		structValue = structValue.Elem()
+	}
+
+	// attribute has a node for every field, patch them field by field
+	if n.kind == NODE_ATTRIBUTE && len(n.children) > 0 && structValue.Kind() == reflect.Struct && !options.ReplaceNested {
+		return patchStruct(n, structValue, options, apply)
+	}
+
+	if apply {
+		n.AddValue(UPDATE_SET, value.Interface())
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+)
+
+// ProjectFor marks for projection the child attributes of 'this' root node which match the
+// fields of `dtoType`, a struct or a pointer to struct, see ProjectFor of the sdk packages.
+// Every field is matched before marking, nothing is marked if a field has no attribute
+func (n *Node) ProjectFor(dtoType reflect.Type) error {
+	structType := indirectType(dtoType)
+	if structType.Kind() != reflect.Struct {
+		return errors.New("DTO must be a struct or a pointer to struct, got " + structType.String())
+	}
+
+	nodes, err := projectStruct(n, structType, []*Node{})
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if err := node.Project(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// projectStruct appends to `nodes` the child attributes of map `parent` matching the fields of
+// `structType`
+func projectStruct(parent *Node, structType reflect.Type, nodes []*Node) ([]*Node, error) {
+	childrenByName := make(map[string]*Node, len(parent.children))
+	for _, child := range parent.children {
+		childrenByName[child.name] = child
+	}
+
+	var errs []error
+	for idx := 0; idx < structType.NumField(); idx++ {
+		field := structType.Field(idx)
+		name, ok := utils.AttributeName(field)
+		if !ok {
+			continue
+		}
+
+		// fields of embedded struct are marshalled as fields of the parent
+		if field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct {
+			var err error
+			if nodes, err = projectStruct(parent, indirectType(field.Type), nodes); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		child, ok := childrenByName[name]
+		if !ok {
+			errs = append(errs, parent.childPathError(name, "project", fmt.Errorf("field %s of %s has no attribute in expression builder: %w", field.Name, structType, ErrUnknownAttribute)))
+			continue
+		}
+
+		var err error
+		if nodes, err = child.projectFor(field.Type, nodes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return nodes, errors.Join(errs...)
+}
+
+func (n *Node) projectFor(fieldType reflect.Type, nodes []*Node) ([]*Node, error) {
+	// attribute has a node for every field, project only the fields of DTO
+	if n.kind == NODE_ATTRIBUTE && len(n.children) > 0 && indirectType(fieldType).Kind() == reflect.Struct {
+		return projectStruct(n, indirectType(fieldType), nodes)
+	}
+
+	return append(nodes, n), nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+)
+
+// Reset clears every mark and list item of 'this' node and the nodes below it
+func (n *Node) Reset() {
+	n.projection = false
+	n.conditions = nil
+	n.filters = nil
+	n.operation = NO_OP
+	n.replacedOperation = NO_OP
+	n.value = nil
+	n.indexErrs = nil
+	n.orderOfListItems = n.orderOfListItems[:0]
+	clear(n.listItems)
+	n.removedListItems = nil
+	for _, child := range n.children {
+		child.Reset()
+	}
+}
+
+// CopyTo copies the marks and list items of 'this' node and the nodes below it onto `clone`, the
+// same node of a newly created tree of the same item. Conditions, filters and values are deep
+// copied, see utils.DeepCopy
+func (n *Node) CopyTo(clone *Node) {
+	clone.projection = n.projection
+	clone.conditions = deepCopyAll(n.conditions)
+	clone.filters = deepCopyAll(n.filters)
+	clone.operation = n.operation
+	clone.replacedOperation = n.replacedOperation
+	clone.value = utils.DeepCopy(n.value)
+	clone.indexErrs = slices.Clone(n.indexErrs)
+	clone.removedListItems = slices.Clone(n.removedListItems)
+	for idx, child := range n.children {
+		child.CopyTo(clone.children[idx])
+	}
+
+	for _, index := range n.orderOfListItems {
+		listItem := clone.createListItem(index)
+		clone.orderOfListItems = append(clone.orderOfListItems, index)
+		clone.listItems[index] = listItem
+		n.listItems[index].CopyTo(listItem)
+	}
+}
+
+// deepCopyAll returns a deep copy of every value of `values`
+func deepCopyAll(values []any) []any {
+	if values == nil {
+		return nil
+	}
+
+	copies := make([]any, 0, len(values))
+	for _, value := range values {
+		copies = append(copies, utils.DeepCopy(value))
+	}
+
+	return copies
+}
+
+// Pool reuses elements of type E, e.g. expression builders of the sdk packages, elements are
+// reset before they are put back into the pool
+type Pool[E any] struct {
+	pool  sync.Pool
+	reset func(E)
+}
+
+func NewPool[E any](newElement func() E, reset func(E)) *Pool[E] {
+	return &Pool[E]{
+		pool: sync.Pool{
+			New: func() any {
+				element := newElement()
+				return &element
+			},
+		},
+		reset: reset,
+	}
+}
+
+// Get returns an element from the pool, a newly created one if the pool is empty
+func (p *Pool[E]) Get() E {
+	return *p.pool.Get().(*E)
+}
+
+// Put resets `element` and puts it back into the pool
+func (p *Pool[E]) Put(element E) {
+	p.reset(element)
+	p.pool.Put(&element)
+}
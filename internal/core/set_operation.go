@@ -0,0 +1,108 @@
+package core
+
+import (
+	"github.com/gauxs/dynexpr/internal/utils"
+)
+
+type SetOperationKind int
+
+const (
+	// Attribute plus Value, i.e. `SET a = a + :v`
+	SET_PLUS SetOperationKind = iota
+	// Attribute minus Value, i.e. `SET a = a - :v`
+	SET_MINUS
+	// Value if the attribute doesn't exist, i.e. `SET a = if_not_exists(a, :v)`
+	SET_IF_NOT_EXISTS
+	// Attribute at From, i.e. `SET a = b`
+	SET_FROM
+)
+
+func (sok SetOperationKind) String() string {
+	switch sok {
+	case SET_PLUS:
+		return "PLUS"
+	case SET_MINUS:
+		return "MINUS"
+	case SET_IF_NOT_EXISTS:
+		return "IF_NOT_EXISTS"
+	case SET_FROM:
+		return "FROM"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// SetOperation is a value of UPDATE_SET computed from the current value of attributes, the sdk
+// packages render it as an Operand, see UpdateOperand
+type SetOperation struct {
+	Kind SetOperationKind
+
+	// Operand of SET_PLUS and SET_MINUS, default value of SET_IF_NOT_EXISTS
+	Value any
+
+	// SET_PLUS and SET_MINUS use Default when the attribute doesn't exist, i.e.
+	// `SET a = if_not_exists(a, :d) + :v`
+	IfNotExists bool
+	Default     any
+
+	// Document path of the attribute copied by SET_FROM
+	From string
+}
+
+// AddSetOperation marks UPDATE_SET of `operation` on 'this' node. SET_IF_NOT_EXISTS and
+// SET_PLUS/SET_MINUS are combined into a single operation in either order and the default value
+// is kept by a later SET_PLUS/SET_MINUS, any other value is replaced
+func (n *Node) AddSetOperation(operation SetOperation) {
+	if current, ok := n.value.(SetOperation); ok && n.operation == UPDATE_SET {
+		switch {
+		case operation.isArithmetic() && current.Kind == SET_IF_NOT_EXISTS:
+			operation.IfNotExists, operation.Default = true, current.Value
+		case operation.isArithmetic() && current.IfNotExists:
+			operation.IfNotExists, operation.Default = true, current.Default
+		case operation.Kind == SET_IF_NOT_EXISTS && current.isArithmetic():
+			current.IfNotExists, current.Default = true, operation.Value
+			operation = current
+		}
+	}
+
+	n.AddValue(UPDATE_SET, operation)
+}
+
+// AddArithmetic marks UPDATE_SET of the SET_PLUS or SET_MINUS `operation` on 'this' node, see
+// AddSetOperation. ErrNotNumber is returned and 'this' node is left as is when the operand isn't
+// a number
+func (n *Node) AddArithmetic(operation SetOperation) error {
+	if !utils.IsNumber(operation.Value) {
+		op := "increment"
+		if operation.Kind == SET_MINUS {
+			op = "decrement"
+		}
+		return n.pathError(op, ErrNotNumber)
+	}
+
+	n.AddSetOperation(operation)
+	return nil
+}
+
+func (so SetOperation) isArithmetic() bool {
+	return so.Kind == SET_PLUS || so.Kind == SET_MINUS
+}
+
+// operand returns the operand computing `this` operation on the attribute at `path`
+func (so SetOperation) operand(path string) Operand {
+	attribute := NameOperand(path)
+	if so.IfNotExists {
+		attribute = FunctionOperand(OPERAND_IF_NOT_EXISTS, NameOperand(path), ValueOperand(so.Default))
+	}
+
+	switch so.Kind {
+	case SET_PLUS:
+		return FunctionOperand(OPERAND_PLUS, attribute, ValueOperand(so.Value))
+	case SET_MINUS:
+		return FunctionOperand(OPERAND_MINUS, attribute, ValueOperand(so.Value))
+	case SET_IF_NOT_EXISTS:
+		return FunctionOperand(OPERAND_IF_NOT_EXISTS, NameOperand(path), ValueOperand(so.Value))
+	default: // SET_FROM
+		return NameOperand(so.From)
+	}
+}
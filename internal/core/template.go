@@ -0,0 +1,164 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+// SlotScope scopes the tokens of the slots of an expression builder to its running Compile, it
+// is shared by the expression builder and its clones
+type SlotScope struct {
+	// Serialises the compiles of the expression builder, compiles of different expression
+	// builders don't wait for each other
+	lock sync.Mutex
+
+	// Nonce of the tokens of slots marshalled by the running Compile, nil outside of Compile
+	nonce atomic.Pointer[string]
+}
+
+func NewSlotScope() *SlotScope {
+	return &SlotScope{}
+}
+
+// slotTokenPrefix returns the prefix of the tokens of slots marshalled with `nonce`
+func slotTokenPrefix(nonce string) string {
+	return "dynexpr.slot." + nonce + ":"
+}
+
+// SlotToken returns the string value the slot `name` is marshalled into while its expression
+// builder is compiled, the token is unique to the running Compile so that no string value of
+// the tree, not even the token of another compile, is taken for a slot. Returns
+// ErrSlotOutsideCompile when no Compile of the expression builder is running
+func (ss *SlotScope) SlotToken(name string) (string, error) {
+	if ss == nil {
+		return "", ErrSlotOutsideCompile
+	}
+
+	nonce := ss.nonce.Load()
+	if nonce == nil {
+		return "", ErrSlotOutsideCompile
+	}
+
+	return slotTokenPrefix(*nonce) + name, nil
+}
+
+// Template is an expression whose values of slots are bound per request, see Compile
+type Template struct {
+	// Expression having the values which are not slots
+	ddbexpr.Expression
+
+	// Names of the slots in order, a slot can be used more than once
+	slotNames []string
+
+	// Value placeholders of every slot by its name
+	slotPlaceholders map[string][]string
+
+	// Number of value placeholders, including the ones bound to slots
+	valueCount int
+}
+
+// Compile builds the expression using `build` and splits its values into the values of slots,
+// string values which are the token of a slot marshalled by `build`, and the rest which are kept
+// in the template
+func (ss *SlotScope) Compile(build func() (ddbexpr.Expression, error)) (*Template, error) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, errors.New("cannot generate nonce of slot tokens, " + err.Error())
+	}
+
+	nonce := hex.EncodeToString(nonceBytes)
+	ss.nonce.Store(&nonce)
+	expr, err := build()
+	ss.nonce.Store(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &Template{
+		Expression: ddbexpr.Expression{
+			Expressions: expr.Expressions,
+			Names:       expr.Names,
+			Values:      map[string]ddbexpr.Value{},
+		},
+		slotPlaceholders: map[string][]string{},
+		valueCount:       len(expr.Values),
+	}
+
+	prefix := slotTokenPrefix(nonce)
+	for placeholder, value := range expr.Values {
+		if name, ok := strings.CutPrefix(value.String, prefix); ok && value.Type == ddbexpr.VALUE_S {
+			template.slotPlaceholders[name] = append(template.slotPlaceholders[name], placeholder)
+		} else {
+			template.Values[placeholder] = value
+		}
+	}
+
+	for name := range template.slotPlaceholders {
+		template.slotNames = append(template.slotNames, name)
+	}
+	slices.Sort(template.slotNames)
+
+	return template, nil
+}
+
+// Slots returns the names of the slots of 'this' template in order
+func (t *Template) Slots() []string {
+	return slices.Clone(t.slotNames)
+}
+
+// SlotPlaceholders returns the value placeholders the slot `name` is bound to
+func (t *Template) SlotPlaceholders(name string) []string {
+	return t.slotPlaceholders[name]
+}
+
+// ValueCount returns the number of values of 'this' template once bound, including the values
+// of slots
+func (t *Template) ValueCount() int {
+	return t.valueCount
+}
+
+// CheckSlots returns an error if a slot of 'this' template is not bound by `values` or
+// `values` binds an unknown slot
+func (t *Template) CheckSlots(values map[string]any) error {
+	if len(values) == len(t.slotNames) {
+		for name := range values {
+			if _, ok := t.slotPlaceholders[name]; !ok {
+				return t.slotsMismatch(values)
+			}
+		}
+
+		return nil
+	}
+
+	return t.slotsMismatch(values)
+}
+
+// slotsMismatch returns the error listing the slots of 'this' template which are not bound
+// and the bound slots which are unknown
+func (t *Template) slotsMismatch(values map[string]any) error {
+	unbound, unknown := []string{}, []string{}
+	for _, name := range t.slotNames {
+		if _, ok := values[name]; !ok {
+			unbound = append(unbound, name)
+		}
+	}
+
+	for name := range values {
+		if _, ok := t.slotPlaceholders[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+
+	return errors.New("slots [" + strings.Join(unbound, ", ") + "] are not bound and slots [" + strings.Join(unknown, ", ") + "] are unknown")
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+type TransactWriteOperation int
+
+const (
+	TRANSACT_PUT TransactWriteOperation = iota
+	TRANSACT_UPDATE
+	TRANSACT_DELETE
+	TRANSACT_CONDITION_CHECK
+)
+
+func (o TransactWriteOperation) String() string {
+	switch o {
+	case TRANSACT_PUT:
+		return "Put"
+	case TRANSACT_UPDATE:
+		return "Update"
+	case TRANSACT_DELETE:
+		return "Delete"
+	case TRANSACT_CONDITION_CHECK:
+		return "ConditionCheck"
+	default:
+		return "TransactWriteOperation(" + strconv.Itoa(int(o)) + ")"
+	}
+}
+
+// TransactWriteItem is a single operation of a TransactWriteItems request
+type TransactWriteItem struct {
+	// Table in which the item is stored
+	TableName string
+
+	// Whole item for TRANSACT_PUT and key of the item for rest of the operations
+	Item map[string]ddbexpr.Value
+
+	// Names of the key attributes of the table
+	KeyAttributeNames []string
+}
+
+// TransactWriteKeys returns the key of every item of a transaction. Returns an error if the
+// transaction has no item or more than MaxTransactWriteItems items, or if more than one
+// operation targets the same item
+func TransactWriteKeys(items []TransactWriteItem) ([]map[string]ddbexpr.Value, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no item added in transaction")
+	}
+
+	if len(items) > MaxTransactWriteItems {
+		return nil, fmt.Errorf("transaction has %d items, a transaction can have at most %d items", len(items), MaxTransactWriteItems)
+	}
+
+	keys := make([]map[string]ddbexpr.Value, 0, len(items))
+	itemIndexByKey := map[string]int{}
+	for idx, item := range items {
+		key, err := ExtractKey(item.Item, item.KeyAttributeNames)
+		if err != nil {
+			return nil, fmt.Errorf("item %d of transaction: %w", idx, err)
+		}
+
+		identity := KeyIdentity(item.TableName, key)
+		if firstIdx, ok := itemIndexByKey[identity]; ok {
+			return nil, fmt.Errorf("item %d and %d of transaction target the same item [%s]", firstIdx, idx, identity)
+		}
+		itemIndexByKey[identity] = idx
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
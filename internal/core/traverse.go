@@ -0,0 +1,266 @@
+package core
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+// Update is an update operation of the tree on the attribute at Path
+type Update struct {
+	Path      string
+	Operation Operation
+	Value     any
+}
+
+// ProjectionPaths returns the document paths of all the nodes marked for projection, children of
+// projected nodes are skipped. Key attributes are always projected
+func (n *Node) ProjectionPaths() []string {
+	return n.addProjectionPaths([]string{})
+}
+
+func (n *Node) addProjectionPaths(paths []string) []string {
+	if n.kind == NODE_KEY || n.projection { // skipping projection of child nodes
+		return append(paths, n.DocumentPath())
+	}
+
+	for _, child := range n.nodesBelow() {
+		paths = child.addProjectionPaths(paths)
+	}
+
+	return paths
+}
+
+// Updates returns the update operations of all the nodes marked for update, children of updated
+// nodes are skipped. Key attributes can't be updated. List items are visited in the order they
+// were added, except the list items removed by RemoveListItems which are visited last in the
+// order of their indices
+func (n *Node) Updates() []Update {
+	return n.addUpdates([]Update{})
+}
+
+func (n *Node) addUpdates(updates []Update) []Update {
+	if n.kind == NODE_KEY {
+		return updates
+	}
+
+	switch n.operation {
+	case UPDATE_SET, UPDATE_REMOVE, UPDATE_ADD, UPDATE_DELETE:
+		return append(updates, Update{Path: n.DocumentPath(), Operation: n.operation, Value: n.value})
+	case NO_OP:
+		if n.kind == NODE_LIST {
+			return n.addListItemUpdates(updates)
+		}
+
+		for _, child := range n.children {
+			updates = child.addUpdates(updates)
+		}
+	}
+
+	return updates
+}
+
+// addListItemUpdates adds the updates of the list items of 'this' list in the order they were
+// added, the list items removed by RemoveListItems are added last in the order of their indices
+func (n *Node) addListItemUpdates(updates []Update) []Update {
+	for _, index := range n.orderOfListItems {
+		if !n.isRemovedListItem(index) {
+			updates = n.listItems[index].addUpdates(updates)
+		}
+	}
+
+	for _, index := range n.removedListItems {
+		if n.isRemovedListItem(index) {
+			updates = n.listItems[index].addUpdates(updates)
+		}
+	}
+
+	return updates
+}
+
+// isRemovedListItem reports whether the list item at `index` of 'this' list is still marked by
+// RemoveListItems, a later mark of another operation updates it in the order it was added
+func (n *Node) isRemovedListItem(index int) bool {
+	_, found := slices.BinarySearch(n.removedListItems, index)
+	return found && n.listItems[index].operation == UPDATE_REMOVE
+}
+
+// Conditions returns the conditions of every node of the tree which is not a key attribute, the
+// conditions of a single node are grouped together in the order they were added
+func (n *Node) Conditions() [][]any {
+	return n.addConditions([][]any{})
+}
+
+func (n *Node) addConditions(conditions [][]any) [][]any {
+	if n.kind == NODE_KEY {
+		return conditions
+	}
+
+	if len(n.conditions) > 0 {
+		conditions = append(conditions, n.conditions)
+	}
+
+	for _, child := range n.nodesBelow() {
+		conditions = child.addConditions(conditions)
+	}
+
+	return conditions
+}
+
+// MarkedConditions returns the conditions marked on 'this' node only, in the order they were added
+func (n *Node) MarkedConditions() []any {
+	return n.conditions
+}
+
+// Filters returns the filters of every node of the tree including key attributes, the
+// filters of a single node are grouped together in the order they were added
+func (n *Node) Filters() [][]any {
+	return n.addFilters([][]any{})
+}
+
+func (n *Node) addFilters(filters [][]any) [][]any {
+	if len(n.filters) > 0 {
+		filters = append(filters, n.filters)
+	}
+
+	for _, child := range n.nodesBelow() {
+		filters = child.addFilters(filters)
+	}
+
+	return filters
+}
+
+// MarkedFilters returns the filters marked on 'this' node only, in the order they were added
+func (n *Node) MarkedFilters() []any {
+	return n.filters
+}
+
+// CheckQueryFilters returns an error for every key attribute of 'this' root node marked with a
+// filter, DynamoDB doesn't allow key attributes in the filter expression of a query
+func (n *Node) CheckQueryFilters() error {
+	errs := []error{}
+	// key attributes will always be top level attribute
+	for _, child := range n.children {
+		if child.kind == NODE_KEY && len(child.filters) > 0 {
+			errs = append(errs, child.pathError("filter", ErrKeyAttributeFilter))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// KeyConditions returns the key conditions of the key attributes of 'this' root node grouped
+// by key attribute
+func (n *Node) KeyConditions() [][]any {
+	keyConditions := [][]any{}
+	// key attributes will always be top level attribute
+	for _, child := range n.children {
+		if child.kind == NODE_KEY && len(child.conditions) > 0 {
+			keyConditions = append(keyConditions, child.conditions)
+		}
+	}
+
+	return keyConditions
+}
+
+// KeyAttributeNames returns the names of the key attributes of 'this' root node
+func (n *Node) KeyAttributeNames() []string {
+	keyAttributeNames := []string{}
+	// key attributes will always be top level attribute
+	for _, child := range n.children {
+		if child.kind == NODE_KEY {
+			keyAttributeNames = append(keyAttributeNames, child.name)
+		}
+	}
+
+	return keyAttributeNames
+}
+
+// ResolveChild returns the node of `element` below 'this' node, list items which are not yet
+// part of the tree are created and only attached to it when `attach` is true
+func (n *Node) ResolveChild(element ddbexpr.PathElement, attach bool) (*Node, bool) {
+	switch {
+	case n.kind == NODE_ATTRIBUTE && !element.IsIndex:
+		return n.Child(element.Name)
+	case n.kind == NODE_LIST && element.IsIndex:
+		if listItem, ok := n.listItems[element.Index]; ok {
+			return listItem, true
+		}
+
+		if attach {
+			listItem, err := n.EnsureListItem(element.Index)
+			return listItem, err == nil
+		}
+
+		return n.createListItem(element.Index), true
+	default:
+		return nil, false
+	}
+}
+
+// Resolve returns the node of `path` below 'this' node, see ResolveChild
+func (n *Node) Resolve(path ddbexpr.Path, attach bool) (*Node, bool) {
+	node := n
+	for _, element := range path {
+		child, ok := node.ResolveChild(element, attach)
+		if !ok {
+			return nil, false
+		}
+		node = child
+	}
+
+	return node, true
+}
+
+// ResolvePath returns the node of `path` below 'this' node, list items which are not yet part
+// of the tree are created and only added to it when `attach` is true and the whole of `path`
+// exists. The *PathError names the first element of `path` which doesn't exist
+func (n *Node) ResolvePath(path ddbexpr.Path, attach bool) (*Node, error) {
+	node := n
+	for idx, element := range path {
+		switch {
+		case node.kind == NODE_LIST && element.IsIndex:
+			if err := node.checkListIndices(element.Index); err != nil {
+				return nil, err
+			}
+			node, _ = node.ResolveChild(element, false)
+		case node.kind == NODE_ATTRIBUTE && !element.IsIndex:
+			child, ok := node.Child(element.Name)
+			if !ok {
+				return nil, &PathError{Path: path[:idx+1].String(), Op: "resolve", Err: ErrUnknownAttribute}
+			}
+			node = child
+		case element.IsIndex:
+			return nil, &PathError{Path: path[:idx+1].String(), Op: "resolve", Err: ErrUnknownListIndex}
+		default:
+			return nil, &PathError{Path: path[:idx+1].String(), Op: "resolve", Err: ErrUnknownAttribute}
+		}
+	}
+
+	if attach {
+		node, _ = n.Resolve(path, true)
+	}
+
+	return node, nil
+}
+
+// PathNode returns the node of document path `path` below 'this' node, see ResolvePath. An
+// invalid `path` is returned as *PathError of the whole `path`
+func (n *Node) PathNode(path string) (*Node, error) {
+	documentPath, err := ddbexpr.ParsePath(path)
+	if err != nil {
+		return nil, &PathError{Path: path, Op: "path", Err: err}
+	}
+
+	return n.ResolvePath(documentPath, true)
+}
+
+// nodesBelow returns the child nodes of an attribute or the list items of a list in order
+func (n *Node) nodesBelow() []*Node {
+	if n.kind == NODE_LIST {
+		return n.ListItems()
+	}
+
+	return n.children
+}
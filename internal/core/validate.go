@@ -0,0 +1,100 @@
+package core
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+// ValidationErrorKind is the limit exceeded by a ValidationError, kinds mirror ddbexpr.ViolationKind
+type ValidationErrorKind int
+
+const (
+	VALIDATION_EXPRESSION_TOO_LONG ValidationErrorKind = iota
+	VALIDATION_TOO_MANY_IN_OPERANDS
+	VALIDATION_ITEM_TOO_LARGE
+	VALIDATION_OVERLAPPING_PATHS
+	VALIDATION_DUPLICATE_UPDATE_PATH
+)
+
+func (vek ValidationErrorKind) String() string {
+	switch vek {
+	case VALIDATION_EXPRESSION_TOO_LONG:
+		return "EXPRESSION_TOO_LONG"
+	case VALIDATION_TOO_MANY_IN_OPERANDS:
+		return "TOO_MANY_IN_OPERANDS"
+	case VALIDATION_ITEM_TOO_LARGE:
+		return "ITEM_TOO_LARGE"
+	case VALIDATION_OVERLAPPING_PATHS:
+		return "OVERLAPPING_PATHS"
+	case VALIDATION_DUPLICATE_UPDATE_PATH:
+		return "DUPLICATE_UPDATE_PATH"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ValidationError is a limit of dynamo db exceeded by an expression of the tree
+type ValidationError struct {
+	Kind ValidationErrorKind
+
+	// Kind of the violating expression, one of KeyCondition, Condition, Filter, Projection or Update
+	Expression string
+
+	// Offending document paths, empty when the limit concerns the whole expression
+	Paths []string
+
+	message string
+}
+
+func (ve *ValidationError) Error() string {
+	return ve.Expression + " expression is invalid, " + ve.message
+}
+
+// ValidationErrors are all the limits of dynamo db exceeded by the tree, errors.As can be used
+// to get the first *ValidationError
+type ValidationErrors []*ValidationError
+
+func (ves ValidationErrors) Error() string {
+	messages := make([]string, 0, len(ves))
+	for _, validationError := range ves {
+		messages = append(messages, validationError.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (ves ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(ves))
+	for _, validationError := range ves {
+		errs = append(errs, validationError)
+	}
+
+	return errs
+}
+
+// ValidateExpression checks `expr` against the limits of dynamo db and returns every exceeded
+// limit as ValidationErrors, nil if none is exceeded
+func ValidateExpression(expr ddbexpr.Expression) error {
+	violations, err := expr.Validate()
+	if err != nil {
+		return errors.New("cannot validate expression, " + err.Error())
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	validationErrors := make(ValidationErrors, 0, len(violations))
+	for _, violation := range violations {
+		validationErrors = append(validationErrors, &ValidationError{
+			Kind:       ValidationErrorKind(violation.Kind),
+			Expression: violation.Expression,
+			Paths:      violation.Paths,
+			message:    violation.Message,
+		})
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+)
+
+// WalkKind is the kind of a node as exposed by Walk of the sdk packages
+type WalkKind int
+
+const (
+	// Attribute holding a primitive, a set or a map without a node for its attributes
+	WALK_LEAF WalkKind = iota
+	// Attribute holding a map with a node for each of its attributes
+	WALK_STRUCT
+	WALK_LIST
+	WALK_KEY
+)
+
+func (wk WalkKind) String() string {
+	switch wk {
+	case WALK_LEAF:
+		return "LEAF"
+	case WALK_STRUCT:
+		return "STRUCT"
+	case WALK_LIST:
+		return "LIST"
+	case WALK_KEY:
+		return "KEY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Marks are the marks of a single node, Value is the value as marked e.g. a RawValue or an
+// Operand which the sdk packages convert for their sdk
+type Marks struct {
+	Projection bool
+	Operation  Operation
+	Value      any
+	Conditions int
+	Filters    int
+}
+
+// SkipChildren is returned by the function passed to Walk to skip the children of a node
+var SkipChildren = errors.New("skip children")
+
+// WalkKind returns the kind of 'this' node
+func (n *Node) WalkKind() WalkKind {
+	switch {
+	case n.kind == NODE_KEY:
+		return WALK_KEY
+	case n.kind == NODE_LIST:
+		return WALK_LIST
+	case len(n.children) > 0:
+		return WALK_STRUCT
+	default:
+		return WALK_LEAF
+	}
+}
+
+// Marks returns the marks of 'this' node
+func (n *Node) Marks() Marks {
+	return Marks{
+		Projection: n.projection,
+		Operation:  n.operation,
+		Value:      n.value,
+		Conditions: len(n.conditions),
+		Filters:    len(n.filters),
+	}
+}
+
+// WalkChildren returns the child attributes of a map or the list items of a list in the order
+// they were added, nil for key attributes
+func (n *Node) WalkChildren() []*Node {
+	return n.nodesBelow()
+}
+
+// Walk calls `fn` for 'this' node and every node below it, depth first with parents before their
+// children. Walk stops at the first error returned by `fn` and returns it, unless it is
+// SkipChildren in which case the children of that node are skipped
+//
+// Children are read while holding the read lock of the tree which is released before `fn` is
+// called, so `fn` can mark the nodes in concurrency mode
+func (n *Node) Walk(fn func(*Node) error) error {
+	if err := fn(n); err == SkipChildren {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	unlock := n.RLockTree()
+	children := n.WalkChildren()
+	unlock()
+
+	for _, child := range children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,451 @@
+package ddbexpr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathElement is a single element of a document path, either a map key or a list index
+type PathElement struct {
+	Name    string
+	Index   int
+	IsIndex bool
+}
+
+// Path is a document path like `bank_details.accounts[3]`
+type Path []PathElement
+
+func (p Path) String() string {
+	out := strings.Builder{}
+	for idx, element := range p {
+		switch {
+		case element.IsIndex:
+			out.WriteString("[" + strconv.Itoa(element.Index) + "]")
+		case idx > 0:
+			out.WriteString("." + element.Name)
+		default:
+			out.WriteString(element.Name)
+		}
+	}
+
+	return out.String()
+}
+
+// Resolve returns the value at `this` path of the item, returns false if the path doesn't exist
+func (p Path) Resolve(item map[string]Value) (Value, bool) {
+	current := Value{Type: VALUE_M, Map: item}
+	for _, element := range p {
+		if element.IsIndex {
+			if current.Type != VALUE_L || element.Index >= len(current.List) {
+				return Value{}, false
+			}
+			current = current.List[element.Index]
+		} else {
+			if current.Type != VALUE_M {
+				return Value{}, false
+			}
+
+			value, ok := current.Map[element.Name]
+			if !ok {
+				return Value{}, false
+			}
+			current = value
+		}
+	}
+
+	return current, true
+}
+
+type OperandKind int
+
+const (
+	OPERAND_PATH OperandKind = iota
+	OPERAND_VALUE
+	OPERAND_SIZE
+)
+
+// Operand is an operand of a condition or an update action
+type Operand struct {
+	Kind OperandKind
+
+	// Document path of OPERAND_PATH and OPERAND_SIZE
+	Path Path
+
+	// Value placeholder of OPERAND_VALUE
+	ValuePlaceholder string
+}
+
+type ConditionKind int
+
+const (
+	CONDITION_AND ConditionKind = iota
+	CONDITION_OR
+	CONDITION_NOT
+	CONDITION_COMPARE
+	CONDITION_BETWEEN
+	CONDITION_IN
+	CONDITION_FUNCTION
+)
+
+// Condition is a node of a parsed condition expression
+type Condition struct {
+	Kind ConditionKind
+
+	// Text of `this` condition in the expression
+	Text string
+
+	// Conditions of CONDITION_AND, CONDITION_OR and CONDITION_NOT
+	Conditions []*Condition
+
+	// Comparator of CONDITION_COMPARE and function name of CONDITION_FUNCTION
+	Operator string
+
+	// Operands of CONDITION_COMPARE, CONDITION_BETWEEN (operand, lower and upper bound),
+	// CONDITION_IN (operand followed by the candidates) and CONDITION_FUNCTION (arguments)
+	Operands []Operand
+}
+
+// Paths returns the document paths of the operands of `this` condition and its sub conditions,
+// in order of appearance
+func (c *Condition) Paths() []Path {
+	paths := []Path{}
+	for _, condition := range c.Conditions {
+		paths = append(paths, condition.Paths()...)
+	}
+
+	for _, operand := range c.Operands {
+		if operand.Kind == OPERAND_PATH || operand.Kind == OPERAND_SIZE {
+			paths = append(paths, operand.Path)
+		}
+	}
+
+	return paths
+}
+
+// Number of arguments of the functions which can be used as a condition
+var conditionFunctionArity = map[string]int{
+	"attribute_exists":     1,
+	"attribute_not_exists": 1,
+	"attribute_type":       2,
+	"begins_with":          2,
+	"contains":             2,
+}
+
+// ParseCondition parses a condition, key condition or filter expression, name placeholders
+// are resolved using `names` while value placeholders are kept as is
+func ParseCondition(expr string, names map[string]string) (*Condition, error) {
+	parser, err := newParser(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.Kind != TOKEN_EOF {
+		return nil, parser.unexpected(token)
+	}
+
+	return condition, nil
+}
+
+type parser struct {
+	expr   string
+	tokens []Token
+	pos    int
+	names  map[string]string
+}
+
+func newParser(expr string, names map[string]string) (*parser, error) {
+	tokens, err := Tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parser{expr: expr, tokens: tokens, names: names}, nil
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) Token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() Token {
+	token := p.tokens[p.pos]
+	if token.Kind != TOKEN_EOF {
+		p.pos++
+	}
+
+	return token
+}
+
+func (p *parser) expect(text string) error {
+	if token := p.next(); !token.Is(text) {
+		return fmt.Errorf("expected [%s] at position %d of expression [%s]", text, token.Pos, p.expr)
+	}
+
+	return nil
+}
+
+func (p *parser) unexpected(token Token) error {
+	if token.Kind == TOKEN_EOF {
+		return errors.New("unexpected end of expression [" + p.expr + "]")
+	}
+
+	return fmt.Errorf("unexpected [%s] at position %d of expression [%s]", token.Text, token.Pos, p.expr)
+}
+
+// text returns the text of the expression from `start` till the last consumed token
+func (p *parser) text(start int) string {
+	last := p.tokens[p.pos-1]
+	return p.expr[start : last.Pos+len(last.Text)]
+}
+
+func (p *parser) parseOr() (*Condition, error) {
+	start := p.peek().Pos
+	condition, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().Is("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		condition = &Condition{Kind: CONDITION_OR, Conditions: []*Condition{condition, right}, Text: p.text(start)}
+	}
+
+	return condition, nil
+}
+
+func (p *parser) parseAnd() (*Condition, error) {
+	start := p.peek().Pos
+	condition, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().Is("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		condition = &Condition{Kind: CONDITION_AND, Conditions: []*Condition{condition, right}, Text: p.text(start)}
+	}
+
+	return condition, nil
+}
+
+func (p *parser) parseNot() (*Condition, error) {
+	if !p.peek().Is("NOT") {
+		return p.parsePrimary()
+	}
+
+	start := p.next().Pos
+	condition, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Condition{Kind: CONDITION_NOT, Conditions: []*Condition{condition}, Text: p.text(start)}, nil
+}
+
+func (p *parser) parsePrimary() (*Condition, error) {
+	start := p.peek().Pos
+	if p.peek().Is("(") {
+		p.next()
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		return condition, p.expect(")")
+	}
+
+	if token := p.peek(); token.Kind == TOKEN_IDENTIFIER && p.peekAt(1).Is("(") {
+		if arity, ok := conditionFunctionArity[strings.ToLower(token.Text)]; ok {
+			return p.parseFunction(start, arity)
+		}
+	}
+
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	token := p.next()
+	switch {
+	case token.Is("=") || token.Is("<>") || token.Is("<") || token.Is("<=") || token.Is(">") || token.Is(">="):
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Condition{Kind: CONDITION_COMPARE, Operator: token.Text, Operands: []Operand{operand, right}, Text: p.text(start)}, nil
+	case token.Is("BETWEEN"):
+		lower, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+
+		upper, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Condition{Kind: CONDITION_BETWEEN, Operands: []Operand{operand, lower, upper}, Text: p.text(start)}, nil
+	case token.Is("IN"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		operands := []Operand{operand}
+		for {
+			candidate, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, candidate)
+
+			if !p.peek().Is(",") {
+				break
+			}
+			p.next()
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return &Condition{Kind: CONDITION_IN, Operands: operands, Text: p.text(start)}, nil
+	default:
+		return nil, p.unexpected(token)
+	}
+}
+
+func (p *parser) parseFunction(start int, arity int) (*Condition, error) {
+	name := strings.ToLower(p.next().Text)
+	p.next() // (
+
+	operands := []Operand{}
+	for {
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+
+		if !p.peek().Is(",") {
+			break
+		}
+		p.next()
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(operands) != arity {
+		return nil, fmt.Errorf("function %s takes %d arguments but got %d in expression [%s]", name, arity, len(operands), p.expr)
+	}
+
+	if operands[0].Kind != OPERAND_PATH {
+		return nil, fmt.Errorf("first argument of function %s must be a document path in expression [%s]", name, p.expr)
+	}
+
+	return &Condition{Kind: CONDITION_FUNCTION, Operator: name, Operands: operands, Text: p.text(start)}, nil
+}
+
+func (p *parser) parseOperand() (Operand, error) {
+	token := p.peek()
+	switch {
+	case token.Kind == TOKEN_VALUE_PLACEHOLDER:
+		p.next()
+		return Operand{Kind: OPERAND_VALUE, ValuePlaceholder: token.Text}, nil
+	case token.Is("size") && p.peekAt(1).Is("("):
+		p.next()
+		p.next()
+		path, err := p.parsePath()
+		if err != nil {
+			return Operand{}, err
+		}
+
+		return Operand{Kind: OPERAND_SIZE, Path: path}, p.expect(")")
+	case token.Kind == TOKEN_NAME_PLACEHOLDER || (token.Kind == TOKEN_IDENTIFIER && !isKeyword(token)):
+		path, err := p.parsePath()
+		if err != nil {
+			return Operand{}, err
+		}
+
+		return Operand{Kind: OPERAND_PATH, Path: path}, nil
+	default:
+		return Operand{}, p.unexpected(token)
+	}
+}
+
+func (p *parser) parsePath() (Path, error) {
+	path := Path{}
+	name, err := p.parsePathName()
+	if err != nil {
+		return nil, err
+	}
+	path = append(path, PathElement{Name: name})
+
+	for {
+		switch {
+		case p.peek().Is("."):
+			p.next()
+			name, err := p.parsePathName()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, PathElement{Name: name})
+		case p.peek().Is("[") && p.peekAt(1).Kind == TOKEN_NUMBER && p.peekAt(2).Is("]"):
+			p.next()
+			index, err := strconv.Atoi(p.next().Text)
+			if err != nil {
+				return nil, err
+			}
+			p.next()
+			path = append(path, PathElement{Index: index, IsIndex: true})
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) parsePathName() (string, error) {
+	token := p.next()
+	switch token.Kind {
+	case TOKEN_NAME_PLACEHOLDER:
+		name, ok := p.names[token.Text]
+		if !ok {
+			return "", errors.New("unknown name placeholder [" + token.Text + "] in expression [" + p.expr + "]")
+		}
+		return name, nil
+	case TOKEN_IDENTIFIER:
+		return token.Text, nil
+	default:
+		return "", p.unexpected(token)
+	}
+}
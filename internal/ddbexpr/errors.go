@@ -0,0 +1,21 @@
+package ddbexpr
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnsupportedPartiQL is returned when an expression has no PartiQL equivalent, e.g. ADD of a
+// number or if_not_exists which DynamoDB doesn't support in PartiQL
+var ErrUnsupportedPartiQL = errors.New("not supported by PartiQL")
+
+// UnknownPathsError is returned when document paths of a parsed expression don't exist in the
+// expression builder tree
+type UnknownPathsError struct {
+	// Document paths with names substituted, in order of appearance
+	Paths []string
+}
+
+func (upe *UnknownPathsError) Error() string {
+	return "document paths [" + strings.Join(upe.Paths, ", ") + "] don't exist in expression builder"
+}
@@ -0,0 +1,271 @@
+package ddbexpr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EvaluateCondition evaluates `condition` against `item` following the semantics of dynamo db,
+// `values` holds the values of value placeholders
+//
+// When the condition is not satisfied the innermost sub condition responsible for it is also
+// returned. Error is returned for conditions which dynamo db rejects, e.g. unknown value
+// placeholder or BETWEEN having lower bound greater than upper bound
+func EvaluateCondition(condition *Condition, item map[string]Value, values map[string]Value) (bool, *Condition, error) {
+	switch condition.Kind {
+	case CONDITION_AND:
+		for _, subCondition := range condition.Conditions {
+			matched, failed, err := EvaluateCondition(subCondition, item, values)
+			if err != nil || !matched {
+				return false, failed, err
+			}
+		}
+		return true, nil, nil
+	case CONDITION_OR:
+		for _, subCondition := range condition.Conditions {
+			matched, _, err := EvaluateCondition(subCondition, item, values)
+			if err != nil || matched {
+				return matched, nil, err
+			}
+		}
+		// none of the alternatives is satisfied
+		return false, condition, nil
+	case CONDITION_NOT:
+		matched, _, err := EvaluateCondition(condition.Conditions[0], item, values)
+		if err != nil {
+			return false, nil, err
+		}
+
+		if matched {
+			return false, condition, nil
+		}
+		return true, nil, nil
+	}
+
+	matched, err := evaluateLeaf(condition, item, values)
+	if err != nil || !matched {
+		return false, condition, err
+	}
+
+	return true, nil, nil
+}
+
+func evaluateLeaf(condition *Condition, item map[string]Value, values map[string]Value) (bool, error) {
+	operands := make([]Value, len(condition.Operands))
+	found := make([]bool, len(condition.Operands))
+	for idx, operand := range condition.Operands {
+		var err error
+		if operands[idx], found[idx], err = ResolveOperand(operand, item, values); err != nil {
+			return false, err
+		}
+	}
+
+	switch condition.Kind {
+	case CONDITION_COMPARE:
+		// a missing attribute isn't equal to anything, every other comparison with it is false
+		if !found[0] || !found[1] {
+			return condition.Operator == "<>", nil
+		}
+
+		return compare(condition.Operator, operands[0], operands[1]), nil
+	case CONDITION_BETWEEN:
+		if found[1] && found[2] && isOrdered(operands[1], operands[2]) && compare(">", operands[1], operands[2]) {
+			return false, errors.New("lower bound of BETWEEN is greater than upper bound in [" + condition.Text + "]")
+		}
+
+		if !found[0] || !found[1] || !found[2] {
+			return false, nil
+		}
+
+		return compare(">=", operands[0], operands[1]) && compare("<=", operands[0], operands[2]), nil
+	case CONDITION_IN:
+		if !found[0] {
+			return false, nil
+		}
+
+		for idx := 1; idx < len(operands); idx++ {
+			if found[idx] && operands[0].Equal(operands[idx]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case CONDITION_FUNCTION:
+		return evaluateFunction(condition, operands, found)
+	default:
+		return false, fmt.Errorf("unsupported condition kind %d", condition.Kind)
+	}
+}
+
+func evaluateFunction(condition *Condition, operands []Value, found []bool) (bool, error) {
+	switch condition.Operator {
+	case "attribute_exists":
+		return found[0], nil
+	case "attribute_not_exists":
+		return !found[0], nil
+	case "attribute_type":
+		if !found[1] || operands[1].Type != VALUE_S || !isValueTypeName(operands[1].String) {
+			return false, errors.New("second argument of attribute_type must be one of S, SS, N, NS, B, BS, BOOL, NULL, L, M in [" + condition.Text + "]")
+		}
+
+		return found[0] && operands[0].Type.String() == operands[1].String, nil
+	case "begins_with":
+		if !found[0] || !found[1] {
+			return false, nil
+		}
+
+		switch {
+		case operands[0].Type == VALUE_S && operands[1].Type == VALUE_S:
+			return strings.HasPrefix(operands[0].String, operands[1].String), nil
+		case operands[0].Type == VALUE_B && operands[1].Type == VALUE_B:
+			return bytes.HasPrefix(operands[0].Binary, operands[1].Binary), nil
+		default:
+			return false, nil
+		}
+	case "contains":
+		if !found[0] || !found[1] {
+			return false, nil
+		}
+
+		return contains(operands[0], operands[1]), nil
+	default:
+		return false, errors.New("unsupported function [" + condition.Operator + "]")
+	}
+}
+
+// ResolveOperand returns the value of an operand, returns false if the operand refers
+// to an attribute which doesn't exist
+func ResolveOperand(operand Operand, item map[string]Value, values map[string]Value) (Value, bool, error) {
+	switch operand.Kind {
+	case OPERAND_VALUE:
+		value, ok := values[operand.ValuePlaceholder]
+		if !ok {
+			return Value{}, false, errors.New("unknown value placeholder [" + operand.ValuePlaceholder + "]")
+		}
+		return value, true, nil
+	case OPERAND_PATH:
+		value, ok := operand.Path.Resolve(item)
+		return value, ok, nil
+	case OPERAND_SIZE:
+		value, ok := operand.Path.Resolve(item)
+		if !ok {
+			return Value{}, false, nil
+		}
+
+		size, ok := Size(value)
+		if !ok {
+			return Value{}, false, nil
+		}
+		return Value{Type: VALUE_N, String: strconv.Itoa(size)}, true, nil
+	default:
+		return Value{}, false, fmt.Errorf("unsupported operand kind %d", operand.Kind)
+	}
+}
+
+// Size returns the size of a value as defined by the size function of dynamo db, length in
+// bytes for strings and binaries and number of elements for sets, lists and maps. Returns
+// false for the types which don't have a size
+func Size(value Value) (int, bool) {
+	switch value.Type {
+	case VALUE_S:
+		return len(value.String), true
+	case VALUE_B:
+		return len(value.Binary), true
+	case VALUE_SS, VALUE_NS:
+		return len(value.Strings), true
+	case VALUE_BS:
+		return len(value.Binaries), true
+	case VALUE_L:
+		return len(value.List), true
+	case VALUE_M:
+		return len(value.Map), true
+	default:
+		return 0, false
+	}
+}
+
+// isOrdered returns true if both values can be compared using <, <=, > and >=
+func isOrdered(value, otherValue Value) bool {
+	return value.Type == otherValue.Type && (value.Type == VALUE_S || value.Type == VALUE_N || value.Type == VALUE_B)
+}
+
+func compare(comparator string, value, otherValue Value) bool {
+	switch comparator {
+	case "=":
+		return value.Equal(otherValue)
+	case "<>":
+		return !value.Equal(otherValue)
+	}
+
+	cmp, ok := CompareOrdered(value, otherValue)
+	if !ok {
+		return false
+	}
+
+	switch comparator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// CompareOrdered compares two strings, numbers or binaries of the same type, returns -1, 0 or
+// +1 like strings.Compare. Returns false if the values are not ordered
+func CompareOrdered(value, otherValue Value) (int, bool) {
+	if !isOrdered(value, otherValue) {
+		return 0, false
+	}
+
+	switch value.Type {
+	case VALUE_S:
+		return strings.Compare(value.String, otherValue.String), true
+	case VALUE_N:
+		cmp, err := CompareNumbers(value.String, otherValue.String)
+		return cmp, err == nil
+	default:
+		return bytes.Compare(value.Binary, otherValue.Binary), true
+	}
+}
+
+func contains(value, element Value) bool {
+	switch value.Type {
+	case VALUE_S:
+		return element.Type == VALUE_S && strings.Contains(value.String, element.String)
+	case VALUE_SS, VALUE_NS, VALUE_BS:
+		elementKey, ok := setElementKey(value.Type, element)
+		if !ok {
+			return false
+		}
+
+		_, ok = value.setElements()[elementKey]
+		return ok
+	case VALUE_L:
+		for _, item := range value.List {
+			if item.Equal(element) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func isValueTypeName(name string) bool {
+	for valueType := VALUE_NULL; valueType <= VALUE_M; valueType++ {
+		if valueType.String() == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package ddbexpr
+
+import "testing"
+
+func TestEvaluateCondition(t *testing.T) {
+	item := map[string]Value{
+		"name":  {Type: VALUE_S, String: "John"},
+		"age":   {Type: VALUE_N, String: "30"},
+		"tags":  {Type: VALUE_SS, Strings: []string{"a", "b"}},
+		"codes": {Type: VALUE_NS, Strings: []string{"1", "2.50"}},
+		"children": {Type: VALUE_L, List: []Value{
+			{Type: VALUE_M, Map: map[string]Value{"name": {Type: VALUE_S, String: "Child"}}},
+		}},
+		"is_married": {Type: VALUE_BOOL, Bool: true},
+	}
+	names := map[string]string{"#0": "name", "#1": "age", "#2": "children", "#3": "missing"}
+	values := map[string]Value{
+		":s":   {Type: VALUE_S, String: "John"},
+		":j":   {Type: VALUE_S, String: "Jo"},
+		":n":   {Type: VALUE_N, String: "30.0"},
+		":lo":  {Type: VALUE_N, String: "18"},
+		":hi":  {Type: VALUE_N, String: "65"},
+		":two": {Type: VALUE_N, String: "2.5"},
+		":t":   {Type: VALUE_S, String: "SS"},
+		":m":   {Type: VALUE_S, String: "M"},
+		":b":   {Type: VALUE_S, String: "b"},
+	}
+	tests := map[string]struct {
+		expr       string
+		want       bool
+		wantFailed string
+		wantErr    bool
+	}{
+		"equal number compared numerically": {expr: "#1 = :n", want: true},
+		"not equal different types":         {expr: "#0 <> :n", want: true},
+		"less than":                         {expr: "#1 < :lo", want: false, wantFailed: "#1 < :lo"},
+		"greater than equal":                {expr: "#1 >= :lo", want: true},
+		"compare different types":           {expr: "#0 > :lo", want: false, wantFailed: "#0 > :lo"},
+		"compare missing attribute":         {expr: "#3 < :s", want: false, wantFailed: "#3 < :s"},
+		"not equal missing attribute":       {expr: "#3 <> :s", want: true},
+		"between":                           {expr: "#1 BETWEEN :lo AND :hi", want: true},
+		"between with invalid bounds":       {expr: "#1 BETWEEN :hi AND :lo", wantErr: true},
+		"in":                                {expr: "#0 IN (:j, :s)", want: true},
+		"attribute exists on nested path":   {expr: "attribute_exists (#2[0].#0)", want: true},
+		"attribute not exists":              {expr: "attribute_not_exists (#2[1])", want: true},
+		"attribute type":                    {expr: "attribute_type (tags, :t) AND attribute_type (#2[0], :m)", want: true},
+		"attribute type with invalid type":  {expr: "attribute_type (tags, :s)", wantErr: true},
+		"begins with":                       {expr: "begins_with (#0, :j)", want: true},
+		"contains in string set":            {expr: "contains (tags, :b)", want: true},
+		"contains in number set":            {expr: "contains (codes, :two)", want: true},
+		"contains in list":                  {expr: "contains (#2, :s)", want: false, wantFailed: "contains (#2, :s)"},
+		"size":                              {expr: "size (#0) = :lo OR size (tags) < :lo", want: true},
+		"and reports failed sub condition":  {expr: "(#0 = :s) AND ((#1 > :hi) OR (size (#2) > :lo))", want: false, wantFailed: "(#1 > :hi) OR (size (#2) > :lo)"},
+		"not":                               {expr: "NOT (#0 = :s)", want: false, wantFailed: "NOT (#0 = :s)"},
+		"not of missing attribute":          {expr: "NOT #3 = :s AND is_married = :s OR #0 = :s", want: true},
+		"unknown value placeholder":         {expr: "#0 = :unknown", wantErr: true},
+	}
+	for name := range tests {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			condition, err := ParseCondition(tt.expr, names)
+			if err != nil {
+				t.Errorf("ParseCondition() error = %v", err)
+				return
+			}
+
+			got, failed, err := EvaluateCondition(condition, item, values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EvaluateCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			gotFailed := ""
+			if failed != nil {
+				gotFailed = failed.Text
+			}
+			if got != tt.want || gotFailed != tt.wantFailed {
+				t.Errorf("EvaluateCondition() = %v, failed [%s], want %v, failed [%s]", got, gotFailed, tt.want, tt.wantFailed)
+			}
+		})
+	}
+}
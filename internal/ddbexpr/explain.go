@@ -0,0 +1,95 @@
+package ddbexpr
+
+import (
+	"strings"
+)
+
+const (
+	// Maximum length of a value rendered by Explain, longer values are truncated
+	MaxExplainedValueLength = 64
+)
+
+// Expressions are the expressions of a single built expression, nil if not present
+type Expressions struct {
+	KeyCondition *string
+	Condition    *string
+	Filter       *string
+	Projection   *string
+	Update       *string
+}
+
+// ExplainExpressions renders every present expression on its own line prefixed by its kind,
+// placeholders are substituted inline
+func ExplainExpressions(exprs Expressions, names map[string]string, values map[string]Value) string {
+	lines := []string{}
+	for _, expr := range []struct {
+		kind string
+		expr *string
+	}{
+		{"KeyCondition", exprs.KeyCondition},
+		{"Condition", exprs.Condition},
+		{"Filter", exprs.Filter},
+		{"Projection", exprs.Projection},
+		{"Update", exprs.Update},
+	} {
+		if expr.expr == nil {
+			continue
+		}
+
+		// update expression has every action on its own line
+		explained := strings.Join(strings.Split(strings.TrimSpace(Explain(*expr.expr, names, values)), "\n"), " ")
+		lines = append(lines, expr.kind+": "+explained)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Explain substitutes name and value placeholders of `expr` inline, rest of the expression is
+// kept as is. Values are pretty printed and truncated to MaxExplainedValueLength, unknown
+// placeholders are not substituted
+func Explain(expr string, names map[string]string, values map[string]Value) string {
+	tokens, err := Tokenize(expr)
+	if err != nil {
+		return expr
+	}
+
+	out := strings.Builder{}
+	copiedTill := 0
+	for _, token := range tokens {
+		substitute := ""
+		switch token.Kind {
+		case TOKEN_NAME_PLACEHOLDER:
+			name, ok := names[token.Text]
+			if !ok {
+				continue
+			}
+			substitute = explainName(name)
+		case TOKEN_VALUE_PLACEHOLDER:
+			value, ok := values[token.Text]
+			if !ok {
+				continue
+			}
+			substitute = value.Format(MaxExplainedValueLength)
+		default:
+			continue
+		}
+
+		out.WriteString(expr[copiedTill:token.Pos])
+		out.WriteString(substitute)
+		copiedTill = token.Pos + len(token.Text)
+	}
+	out.WriteString(expr[copiedTill:])
+
+	return out.String()
+}
+
+// explainName quotes names which can't be written unquoted in a document path
+func explainName(name string) string {
+	for idx := 0; idx < len(name); idx++ {
+		if !isWordChar(name[idx]) && !(idx > 0 && isDigit(name[idx])) {
+			return "`" + name + "`"
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,198 @@
+package ddbexpr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Expression is a single expression built by the sdk along with its names and values,
+// independent of the aws sdk version
+type Expression struct {
+	Expressions
+
+	// Maps name placeholders to attribute names
+	Names map[string]string
+
+	// Maps value placeholders to values
+	Values map[string]Value
+}
+
+// EvaluationResult is the outcome of evaluating a condition against an item
+type EvaluationResult struct {
+	// True if the item satisfies the condition
+	Matched bool
+
+	// Sub condition which is not satisfied, with placeholders substituted inline
+	// e.g. `age > 60`, empty when the condition is matched
+	FailedCondition string
+}
+
+// Explain renders every present expression of `this` expression, see ExplainExpressions
+func (e Expression) Explain() string {
+	return ExplainExpressions(e.Expressions, e.Names, e.Values)
+}
+
+// Validate checks `this` expression against the limits of dynamo db, see Validate
+func (e Expression) Validate() ([]Violation, error) {
+	return Validate(e.Expressions, e.Names, e.Values)
+}
+
+// Evaluate evaluates `conditionExpr`, either the condition or the filter of `this` expression,
+// against `item`. An item always matches a nil condition
+func (e Expression) Evaluate(conditionExpr *string, item map[string]Value) (EvaluationResult, error) {
+	if conditionExpr == nil {
+		return EvaluationResult{Matched: true}, nil
+	}
+
+	condition, err := ParseCondition(*conditionExpr, e.Names)
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	matched, failed, err := EvaluateCondition(condition, item, e.Values)
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	result := EvaluationResult{Matched: matched}
+	if failed != nil {
+		result.FailedCondition = Explain(failed.Text, e.Names, e.Values)
+	}
+
+	return result, nil
+}
+
+// ApplyUpdate applies the update of `this` expression on `item` and returns the updated item,
+// `item` is returned as is when there is no update
+func (e Expression) ApplyUpdate(item map[string]Value) (map[string]Value, error) {
+	if e.Update == nil {
+		return item, nil
+	}
+
+	actions, err := ParseUpdate(*e.Update, e.Names)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyUpdate(actions, item, e.Values)
+}
+
+type PartiQLStatementKind int
+
+const (
+	PARTIQL_SELECT PartiQLStatementKind = iota
+	PARTIQL_UPDATE
+	PARTIQL_DELETE
+)
+
+func (k PartiQLStatementKind) String() string {
+	switch k {
+	case PARTIQL_SELECT:
+		return "SELECT"
+	case PARTIQL_UPDATE:
+		return "UPDATE"
+	case PARTIQL_DELETE:
+		return "DELETE"
+	default:
+		return "PartiQLStatementKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// PartiQL renders `this` expression as a parameterised PartiQL statement of `kind` on
+// `tableName`, parameters are returned in the order of their `?` in the statement
+//
+// Projection becomes the SELECT columns, key condition and condition become the WHERE clause and
+// update becomes the SET/REMOVE clauses of UPDATE. The filter is part of the WHERE clause of
+// SELECT only, like the filter of a query or scan. SELECT requires a projection and UPDATE and
+// DELETE require a WHERE clause
+func (e Expression) PartiQL(kind PartiQLStatementKind, tableName string) (string, []Value, error) {
+	params := []Value{}
+	statement := ""
+	switch kind {
+	case PARTIQL_SELECT:
+		if e.Projection == nil {
+			return "", nil, errors.New(kind.String() + " statement requires a projection")
+		}
+
+		columns, err := e.translatePartiQL(*e.Projection, &params, (*PartiQLTranslator).Projection)
+		if err != nil {
+			return "", nil, err
+		}
+
+		statement = "SELECT " + columns + " FROM " + QuoteIdentifier(tableName)
+	case PARTIQL_UPDATE:
+		if e.Update == nil {
+			return "", nil, errors.New(kind.String() + " statement requires an update")
+		}
+
+		clauses, err := e.translatePartiQL(*e.Update, &params, (*PartiQLTranslator).Update)
+		if err != nil {
+			return "", nil, err
+		}
+
+		statement = "UPDATE " + QuoteIdentifier(tableName) + " " + clauses
+	case PARTIQL_DELETE:
+		statement = "DELETE FROM " + QuoteIdentifier(tableName)
+	default:
+		return "", nil, errors.New("unsupported PartiQL statement kind " + kind.String())
+	}
+
+	conditionExprs := []*string{e.KeyCondition, e.Condition}
+	if kind == PARTIQL_SELECT {
+		conditionExprs = append(conditionExprs, e.Filter)
+	}
+
+	predicates := []string{}
+	for _, conditionExpr := range conditionExprs {
+		if conditionExpr == nil {
+			continue
+		}
+
+		predicate, err := e.translatePartiQL(*conditionExpr, &params, (*PartiQLTranslator).Condition)
+		if err != nil {
+			return "", nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+
+	switch len(predicates) {
+	case 0:
+		if kind != PARTIQL_SELECT {
+			return "", nil, errors.New(kind.String() + " statement requires a key condition or condition")
+		}
+	case 1:
+		statement += " WHERE " + predicates[0]
+	default:
+		statement += " WHERE (" + strings.Join(predicates, ") AND (") + ")"
+	}
+
+	return statement, params, nil
+}
+
+// translatePartiQL translates a single expression of `this` expression using `translate` and
+// appends the values of its parameters to `params`
+func (e Expression) translatePartiQL(expr string, params *[]Value, translate func(*PartiQLTranslator, string) (string, error)) (string, error) {
+	translator := &PartiQLTranslator{
+		Names: e.Names,
+		IsNumber: func(valuePlaceholder string) bool {
+			value, ok := e.Values[valuePlaceholder]
+			return ok && value.Type == VALUE_N
+		},
+	}
+
+	translated, err := translate(translator, expr)
+	if err != nil {
+		return "", err
+	}
+
+	for _, valuePlaceholder := range translator.ValuePlaceholders {
+		value, ok := e.Values[valuePlaceholder]
+		if !ok {
+			return "", errors.New("unknown value placeholder [" + valuePlaceholder + "]")
+		}
+		*params = append(*params, value)
+	}
+
+	return translated, nil
+}
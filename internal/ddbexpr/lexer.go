@@ -0,0 +1,113 @@
+// Package ddbexpr understands the dynamo db expression language, it is independent
+// of the aws sdk version and works on the expression strings built by the sdk.
+package ddbexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type TokenKind int
+
+const (
+	TOKEN_EOF TokenKind = iota
+	// #name
+	TOKEN_NAME_PLACEHOLDER
+	// :value
+	TOKEN_VALUE_PLACEHOLDER
+	// attribute names, function names and keywords
+	TOKEN_IDENTIFIER
+	// list index
+	TOKEN_NUMBER
+	// ( ) [ ] , . = <> < <= > >= + -
+	TOKEN_SYMBOL
+)
+
+// Token is a single lexical unit of an expression
+type Token struct {
+	Kind TokenKind
+
+	// Text of the token as it appears in the expression
+	Text string
+
+	// Byte offset of the token in the expression
+	Pos int
+}
+
+// Is returns true if `this` token is the identifier or symbol `text`,
+// identifiers are matched case insensitively since keywords are case insensitive
+func (t Token) Is(text string) bool {
+	switch t.Kind {
+	case TOKEN_IDENTIFIER:
+		return strings.EqualFold(t.Text, text)
+	case TOKEN_SYMBOL:
+		return t.Text == text
+	default:
+		return false
+	}
+}
+
+// Tokenize splits the expression into tokens, the last token is always TOKEN_EOF
+func Tokenize(expr string) ([]Token, error) {
+	tokens := []Token{}
+	for pos := 0; pos < len(expr); {
+		c := expr[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '#' || c == ':':
+			end := scanWord(expr, pos+1)
+			if end == pos+1 {
+				return nil, fmt.Errorf("invalid placeholder at position %d of expression [%s]", pos, expr)
+			}
+
+			kind := TOKEN_NAME_PLACEHOLDER
+			if c == ':' {
+				kind = TOKEN_VALUE_PLACEHOLDER
+			}
+			tokens = append(tokens, Token{Kind: kind, Text: expr[pos:end], Pos: pos})
+			pos = end
+		case isDigit(c):
+			end := pos
+			for end < len(expr) && isDigit(expr[end]) {
+				end++
+			}
+			tokens = append(tokens, Token{Kind: TOKEN_NUMBER, Text: expr[pos:end], Pos: pos})
+			pos = end
+		case isWordChar(c):
+			end := scanWord(expr, pos)
+			tokens = append(tokens, Token{Kind: TOKEN_IDENTIFIER, Text: expr[pos:end], Pos: pos})
+			pos = end
+		case c == '<' || c == '>':
+			end := pos + 1
+			if end < len(expr) && (expr[end] == '=' || (c == '<' && expr[end] == '>')) {
+				end++
+			}
+			tokens = append(tokens, Token{Kind: TOKEN_SYMBOL, Text: expr[pos:end], Pos: pos})
+			pos = end
+		case strings.IndexByte("()[],.=+-", c) >= 0:
+			tokens = append(tokens, Token{Kind: TOKEN_SYMBOL, Text: expr[pos : pos+1], Pos: pos})
+			pos++
+		default:
+			return nil, fmt.Errorf("unexpected character [%c] at position %d of expression [%s]", c, pos, expr)
+		}
+	}
+
+	return append(tokens, Token{Kind: TOKEN_EOF, Pos: len(expr)}), nil
+}
+
+func scanWord(expr string, pos int) int {
+	for pos < len(expr) && (isWordChar(expr[pos]) || isDigit(expr[pos])) {
+		pos++
+	}
+
+	return pos
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
@@ -0,0 +1,343 @@
+package ddbexpr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PartiQLTranslator translates expressions built by the sdk into PartiQL, name placeholders
+// are replaced by the quoted attribute names and value placeholders are replaced by `?`
+type PartiQLTranslator struct {
+	// Maps name placeholders to attribute names
+	Names map[string]string
+
+	// Reports whether the value of a value placeholder is a number, required for rejecting
+	// ADD action of update expression on a number
+	IsNumber func(valuePlaceholder string) bool
+
+	// Value placeholders in the order in which their `?` appear in the translated expressions
+	ValuePlaceholders []string
+}
+
+// Projection translates a projection expression into the column list of SELECT
+func (t *PartiQLTranslator) Projection(expr string) (string, error) {
+	tokens, err := Tokenize(expr)
+	if err != nil {
+		return "", err
+	}
+
+	columns := []string{}
+	for _, paths := range splitTopLevel(tokens[:len(tokens)-1], ",") {
+		column, err := t.path(paths)
+		if err != nil {
+			return "", err
+		}
+		columns = append(columns, column)
+	}
+
+	return strings.Join(columns, ", "), nil
+}
+
+// Condition translates a condition or key condition expression into a WHERE clause predicate
+func (t *PartiQLTranslator) Condition(expr string) (string, error) {
+	tokens, err := Tokenize(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return t.operands(tokens[:len(tokens)-1])
+}
+
+// Update translates an update expression into SET and REMOVE clauses of UPDATE, every action
+// gets its own clause. ADD on a set becomes set_add and DELETE becomes set_delete. ADD on a
+// number and if_not_exists return ErrUnsupportedPartiQL, ADD treats a missing attribute as 0
+// which needs if_not_exists and DynamoDB doesn't support it in PartiQL
+func (t *PartiQLTranslator) Update(expr string) (string, error) {
+	tokens, err := Tokenize(expr)
+	if err != nil {
+		return "", err
+	}
+	tokens = tokens[:len(tokens)-1]
+
+	clauses := []string{}
+	for start := 0; start < len(tokens); {
+		action := tokens[start]
+		end := start + 1
+		for end < len(tokens) && !isUpdateAction(tokens[end]) {
+			end++
+		}
+
+		for _, actionTokens := range splitTopLevel(tokens[start+1:end], ",") {
+			clause, err := t.updateAction(strings.ToUpper(action.Text), actionTokens)
+			if err != nil {
+				return "", err
+			}
+			clauses = append(clauses, clause)
+		}
+		start = end
+	}
+
+	return strings.Join(clauses, " "), nil
+}
+
+func (t *PartiQLTranslator) updateAction(action string, tokens []Token) (string, error) {
+	switch action {
+	case "SET":
+		equalIdx := indexOfTopLevel(tokens, "=")
+		if equalIdx < 0 {
+			return "", errors.New("missing [=] in SET action")
+		}
+
+		path, err := t.path(tokens[:equalIdx])
+		if err != nil {
+			return "", err
+		}
+
+		value, err := t.operands(tokens[equalIdx+1:])
+		if err != nil {
+			return "", err
+		}
+
+		return "SET " + path + " = " + value, nil
+	case "REMOVE":
+		path, err := t.path(tokens)
+		if err != nil {
+			return "", err
+		}
+
+		return "REMOVE " + path, nil
+	case "ADD", "DELETE":
+		if len(tokens) < 2 || tokens[len(tokens)-1].Kind != TOKEN_VALUE_PLACEHOLDER {
+			return "", errors.New("missing value in " + action + " action")
+		}
+
+		path, err := t.path(tokens[:len(tokens)-1])
+		if err != nil {
+			return "", err
+		}
+
+		valuePlaceholder := tokens[len(tokens)-1].Text
+		if action == "ADD" && t.IsNumber != nil && t.IsNumber(valuePlaceholder) {
+			return "", fmt.Errorf("ADD of a number on %s: %w", path, ErrUnsupportedPartiQL)
+		}
+
+		t.ValuePlaceholders = append(t.ValuePlaceholders, valuePlaceholder)
+		switch {
+		case action == "DELETE":
+			return "SET " + path + " = set_delete(" + path + ", ?)", nil
+		default:
+			return "SET " + path + " = set_add(" + path + ", ?)", nil
+		}
+	default:
+		return "", errors.New("unsupported update action [" + action + "]")
+	}
+}
+
+// operands translates a sequence of operands, operators and function calls
+func (t *PartiQLTranslator) operands(tokens []Token) (string, error) {
+	out := strings.Builder{}
+	write := func(text string) {
+		if out.Len() > 0 && !strings.HasSuffix(out.String(), "(") && !strings.HasSuffix(out.String(), "[") &&
+			text != ")" && text != "]" && text != "," {
+			out.WriteString(" ")
+		}
+		out.WriteString(text)
+	}
+
+	for idx := 0; idx < len(tokens); {
+		token := tokens[idx]
+		switch {
+		case token.Kind == TOKEN_NAME_PLACEHOLDER || (token.Kind == TOKEN_IDENTIFIER && !isKeyword(token) && !isFunctionCall(tokens, idx)):
+			end := pathEnd(tokens, idx)
+			path, err := t.path(tokens[idx:end])
+			if err != nil {
+				return "", err
+			}
+			write(path)
+			idx = end
+		case token.Kind == TOKEN_VALUE_PLACEHOLDER:
+			t.ValuePlaceholders = append(t.ValuePlaceholders, token.Text)
+			write("?")
+			idx++
+		case token.Is("attribute_exists") || token.Is("attribute_not_exists"):
+			closeIdx, err := matchingParen(tokens, idx+1)
+			if err != nil {
+				return "", err
+			}
+
+			path, err := t.path(tokens[idx+2 : closeIdx])
+			if err != nil {
+				return "", err
+			}
+
+			if token.Is("attribute_exists") {
+				write("(" + path + " IS NOT MISSING)")
+			} else {
+				write("(" + path + " IS MISSING)")
+			}
+			idx = closeIdx + 1
+		case token.Is("IN") && idx+1 < len(tokens) && tokens[idx+1].Is("("):
+			closeIdx, err := matchingParen(tokens, idx+1)
+			if err != nil {
+				return "", err
+			}
+
+			values, err := t.operands(tokens[idx+2 : closeIdx])
+			if err != nil {
+				return "", err
+			}
+
+			write("IN [" + values + "]")
+			idx = closeIdx + 1
+		case token.Is("if_not_exists"):
+			return "", fmt.Errorf("%s: %w", token.Text, ErrUnsupportedPartiQL)
+		case isFunctionCall(tokens, idx):
+			write(token.Text + "(")
+			idx += 2
+		case token.Kind == TOKEN_IDENTIFIER:
+			write(strings.ToUpper(token.Text))
+			idx++
+		case token.Is("("):
+			write("(")
+			idx++
+		default:
+			write(token.Text)
+			idx++
+		}
+	}
+
+	return out.String(), nil
+}
+
+// path translates a document path like `#0.#1[2]` into `"name"."name"[2]`
+func (t *PartiQLTranslator) path(tokens []Token) (string, error) {
+	if len(tokens) == 0 {
+		return "", errors.New("empty document path")
+	}
+
+	out := strings.Builder{}
+	for idx := 0; idx < len(tokens); idx++ {
+		token := tokens[idx]
+		switch {
+		case token.Kind == TOKEN_NAME_PLACEHOLDER:
+			name, ok := t.Names[token.Text]
+			if !ok {
+				return "", errors.New("unknown name placeholder [" + token.Text + "]")
+			}
+			out.WriteString(QuoteIdentifier(name))
+		case token.Kind == TOKEN_IDENTIFIER:
+			out.WriteString(QuoteIdentifier(token.Text))
+		case token.Is("."):
+			out.WriteString(".")
+		case token.Is("[") && idx+2 < len(tokens) && tokens[idx+1].Kind == TOKEN_NUMBER && tokens[idx+2].Is("]"):
+			out.WriteString("[" + tokens[idx+1].Text + "]")
+			idx += 2
+		default:
+			return "", fmt.Errorf("unexpected [%s] at position %d of document path", token.Text, token.Pos)
+		}
+	}
+
+	return out.String(), nil
+}
+
+// QuoteIdentifier quotes an attribute or table name for PartiQL
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// pathEnd returns the index right after the document path starting at `start`
+func pathEnd(tokens []Token, start int) int {
+	idx := start + 1
+	for idx < len(tokens) {
+		switch {
+		case tokens[idx].Is(".") && idx+1 < len(tokens):
+			idx += 2
+		case tokens[idx].Is("[") && idx+2 < len(tokens) && tokens[idx+2].Is("]"):
+			idx += 3
+		default:
+			return idx
+		}
+	}
+
+	return idx
+}
+
+// matchingParen returns the index of `)` closing the `(` at `openIdx`
+func matchingParen(tokens []Token, openIdx int) (int, error) {
+	if openIdx >= len(tokens) || !tokens[openIdx].Is("(") {
+		return 0, errors.New("missing [(]")
+	}
+
+	depth := 0
+	for idx := openIdx; idx < len(tokens); idx++ {
+		switch {
+		case tokens[idx].Is("("):
+			depth++
+		case tokens[idx].Is(")"):
+			depth--
+			if depth == 0 {
+				return idx, nil
+			}
+		}
+	}
+
+	return 0, errors.New("missing [)]")
+}
+
+// splitTopLevel splits tokens on `separator` which is not enclosed in parentheses
+func splitTopLevel(tokens []Token, separator string) [][]Token {
+	parts := [][]Token{}
+	depth, start := 0, 0
+	for idx, token := range tokens {
+		switch {
+		case token.Is("("):
+			depth++
+		case token.Is(")"):
+			depth--
+		case token.Is(separator) && depth == 0:
+			parts = append(parts, tokens[start:idx])
+			start = idx + 1
+		}
+	}
+
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+
+	return parts
+}
+
+func indexOfTopLevel(tokens []Token, symbol string) int {
+	depth := 0
+	for idx, token := range tokens {
+		switch {
+		case token.Is("("):
+			depth++
+		case token.Is(")"):
+			depth--
+		case token.Is(symbol) && depth == 0:
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func isFunctionCall(tokens []Token, idx int) bool {
+	return tokens[idx].Kind == TOKEN_IDENTIFIER && !isKeyword(tokens[idx]) && idx+1 < len(tokens) && tokens[idx+1].Is("(")
+}
+
+func isKeyword(token Token) bool {
+	for _, keyword := range []string{"AND", "OR", "NOT", "BETWEEN", "IN"} {
+		if token.Is(keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isUpdateAction(token Token) bool {
+	return token.Is("SET") || token.Is("REMOVE") || token.Is("ADD") || token.Is("DELETE")
+}
@@ -0,0 +1,68 @@
+package ddbexpr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPartiQLTranslator(t *testing.T) {
+	names := map[string]string{"#0": "name", "#1": "tags", "#2": "count", "#3": `quoted"name`}
+	tests := map[string]struct {
+		translate func(*PartiQLTranslator, string) (string, error)
+		expr      string
+		want      string
+		wantPlhs  int
+		wantErr   error
+	}{
+		"functions and between": {
+			translate: (*PartiQLTranslator).Condition,
+			expr:      "(begins_with (#0, :0)) AND (size (#1) BETWEEN :1 AND :2) OR (NOT attribute_not_exists (#3))",
+			want:      `(begins_with("name", ?)) AND (size("tags") BETWEEN ? AND ?) OR (NOT ("quoted""name" IS MISSING))`,
+			wantPlhs:  3,
+		},
+		"set add and set delete": {
+			translate: (*PartiQLTranslator).Update,
+			expr:      "ADD #1 :0\nDELETE #1 :2\nSET #0 = :3\n",
+			want:      `SET "tags" = set_add("tags", ?) SET "tags" = set_delete("tags", ?) SET "name" = ?`,
+			wantPlhs:  3,
+		},
+		"number add": {
+			translate: (*PartiQLTranslator).Update,
+			expr:      "ADD #1 :0, #2 :1\n",
+			wantErr:   ErrUnsupportedPartiQL,
+		},
+		"if_not_exists": {
+			translate: (*PartiQLTranslator).Update,
+			expr:      "SET #0 = if_not_exists(#0, :3)\n",
+			wantErr:   ErrUnsupportedPartiQL,
+		},
+		"projection with list index": {
+			translate: (*PartiQLTranslator).Projection,
+			expr:      "#0, #1[2].#2",
+			want:      `"name", "tags"[2]."count"`,
+		},
+	}
+	for name := range tests {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			translator := &PartiQLTranslator{
+				Names:    names,
+				IsNumber: func(valuePlaceholder string) bool { return valuePlaceholder == ":1" },
+			}
+			got, err := tt.translate(translator, tt.expr)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("translate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("translate() error = %v", err)
+				return
+			}
+			if got != tt.want || len(translator.ValuePlaceholders) != tt.wantPlhs {
+				t.Errorf("translate() = %v with %d placeholders, want %v with %d placeholders", got, len(translator.ValuePlaceholders), tt.want, tt.wantPlhs)
+			}
+		})
+	}
+}
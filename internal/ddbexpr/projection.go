@@ -0,0 +1,170 @@
+package ddbexpr
+
+import (
+	"errors"
+	"sort"
+)
+
+// ParseProjection parses a projection expression into its document paths, name
+// placeholders are resolved using `names`
+func ParseProjection(expr string, names map[string]string) ([]Path, error) {
+	paths, err := parseProjectionPaths(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	if overlapping := OverlappingPaths(paths); len(overlapping) > 0 {
+		return nil, errors.New("two document paths overlap with each other: [" + overlapping[0][0].String() + "] and [" + overlapping[0][1].String() + "]")
+	}
+
+	return paths, nil
+}
+
+// ParsePath parses a single document path without name placeholders
+func ParsePath(expr string) (Path, error) {
+	paths, err := parseProjectionPaths(expr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) != 1 {
+		return nil, errors.New("expected a single document path: " + expr)
+	}
+
+	return paths[0], nil
+}
+
+// parseProjectionPaths parses the document paths of a projection expression, overlapping
+// paths are kept as is
+func parseProjectionPaths(expr string, names map[string]string) ([]Path, error) {
+	parser, err := newParser(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []Path{}
+	for {
+		path, err := parser.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+
+		if !parser.peek().Is(",") {
+			break
+		}
+		parser.next()
+	}
+
+	if token := parser.peek(); token.Kind != TOKEN_EOF {
+		return nil, parser.unexpected(token)
+	}
+
+	return paths, nil
+}
+
+// OverlappingPaths returns every pair of `paths` where one path is equal to or a prefix of the
+// other, in the order of their appearance
+func OverlappingPaths(paths []Path) [][2]Path {
+	overlapping := [][2]Path{}
+	for idx, path := range paths {
+		for _, otherPath := range paths[idx+1:] {
+			if path.overlaps(otherPath) {
+				overlapping = append(overlapping, [2]Path{path, otherPath})
+			}
+		}
+	}
+
+	return overlapping
+}
+
+// Project returns the attributes of `item` at `paths` following the semantics of dynamo db,
+// nested attributes keep their parent maps and lists while projected list items are
+// compacted in the order of their index. Paths which don't exist are skipped
+func Project(item map[string]Value, paths []Path) map[string]Value {
+	root := &projectionNode{}
+	for _, path := range paths {
+		root.add(path)
+	}
+
+	projected, ok := root.apply(Value{Type: VALUE_M, Map: item})
+	if !ok {
+		return map[string]Value{}
+	}
+
+	return projected.Map
+}
+
+// projectionNode is a node of the tree formed by projected document paths
+type projectionNode struct {
+	// `this` attribute is projected as a whole
+	whole bool
+
+	children map[string]*projectionNode
+	indexes  map[int]*projectionNode
+}
+
+func (pn *projectionNode) add(path Path) {
+	if len(path) == 0 {
+		pn.whole = true
+		return
+	}
+
+	var child *projectionNode
+	if path[0].IsIndex {
+		if pn.indexes == nil {
+			pn.indexes = map[int]*projectionNode{}
+		}
+		if child = pn.indexes[path[0].Index]; child == nil {
+			child = &projectionNode{}
+			pn.indexes[path[0].Index] = child
+		}
+	} else {
+		if pn.children == nil {
+			pn.children = map[string]*projectionNode{}
+		}
+		if child = pn.children[path[0].Name]; child == nil {
+			child = &projectionNode{}
+			pn.children[path[0].Name] = child
+		}
+	}
+
+	child.add(path[1:])
+}
+
+func (pn *projectionNode) apply(value Value) (Value, bool) {
+	if pn.whole {
+		return value, true
+	}
+
+	switch value.Type {
+	case VALUE_M:
+		projected := map[string]Value{}
+		for name, child := range pn.children {
+			if childValue, ok := value.Map[name]; ok {
+				if projectedValue, ok := child.apply(childValue); ok {
+					projected[name] = projectedValue
+				}
+			}
+		}
+		return Value{Type: VALUE_M, Map: projected}, len(projected) > 0
+	case VALUE_L:
+		indexes := make([]int, 0, len(pn.indexes))
+		for index := range pn.indexes {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		projected := []Value{}
+		for _, index := range indexes {
+			if index < len(value.List) {
+				if projectedValue, ok := pn.indexes[index].apply(value.List[index]); ok {
+					projected = append(projected, projectedValue)
+				}
+			}
+		}
+		return Value{Type: VALUE_L, List: projected}, len(projected) > 0
+	default:
+		return Value{}, false
+	}
+}
@@ -0,0 +1,131 @@
+// Package sdkv1 converts between attribute values of aws-sdk-go and ddbexpr.Value
+package sdkv1
+
+import (
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// FromAttributeValue converts an aws-sdk-go attribute value into ddbexpr.Value,
+// nil attribute value is converted into VALUE_NULL
+func FromAttributeValue(attributeValue *dynamodb.AttributeValue) ddbexpr.Value {
+	switch {
+	case attributeValue == nil || attributeValue.NULL != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_NULL}
+	case attributeValue.S != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_S, String: *attributeValue.S}
+	case attributeValue.N != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_N, String: *attributeValue.N}
+	case attributeValue.B != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_B, Binary: attributeValue.B}
+	case attributeValue.BOOL != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_BOOL, Bool: *attributeValue.BOOL}
+	case attributeValue.SS != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_SS, Strings: aws.StringValueSlice(attributeValue.SS)}
+	case attributeValue.NS != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_NS, Strings: aws.StringValueSlice(attributeValue.NS)}
+	case attributeValue.BS != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_BS, Binaries: attributeValue.BS}
+	case attributeValue.L != nil:
+		list := make([]ddbexpr.Value, 0, len(attributeValue.L))
+		for _, item := range attributeValue.L {
+			list = append(list, FromAttributeValue(item))
+		}
+		return ddbexpr.Value{Type: ddbexpr.VALUE_L, List: list}
+	case attributeValue.M != nil:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_M, Map: FromItem(attributeValue.M)}
+	default:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_NULL}
+	}
+}
+
+// FromItem converts an aws-sdk-go item into a map of ddbexpr.Value
+func FromItem(item map[string]*dynamodb.AttributeValue) map[string]ddbexpr.Value {
+	values := make(map[string]ddbexpr.Value, len(item))
+	for name, attributeValue := range item {
+		values[name] = FromAttributeValue(attributeValue)
+	}
+
+	return values
+}
+
+// ToAttributeValue converts a ddbexpr.Value into an aws-sdk-go attribute value
+func ToAttributeValue(value ddbexpr.Value) *dynamodb.AttributeValue {
+	switch value.Type {
+	case ddbexpr.VALUE_S:
+		return &dynamodb.AttributeValue{S: aws.String(value.String)}
+	case ddbexpr.VALUE_N:
+		return &dynamodb.AttributeValue{N: aws.String(value.String)}
+	case ddbexpr.VALUE_B:
+		return &dynamodb.AttributeValue{B: value.Binary}
+	case ddbexpr.VALUE_BOOL:
+		return &dynamodb.AttributeValue{BOOL: aws.Bool(value.Bool)}
+	case ddbexpr.VALUE_SS:
+		return &dynamodb.AttributeValue{SS: aws.StringSlice(value.Strings)}
+	case ddbexpr.VALUE_NS:
+		return &dynamodb.AttributeValue{NS: aws.StringSlice(value.Strings)}
+	case ddbexpr.VALUE_BS:
+		return &dynamodb.AttributeValue{BS: value.Binaries}
+	case ddbexpr.VALUE_L:
+		list := make([]*dynamodb.AttributeValue, 0, len(value.List))
+		for _, item := range value.List {
+			list = append(list, ToAttributeValue(item))
+		}
+		return &dynamodb.AttributeValue{L: list}
+	case ddbexpr.VALUE_M:
+		return &dynamodb.AttributeValue{M: ToItem(value.Map)}
+	default:
+		return &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	}
+}
+
+// ToItem converts a map of ddbexpr.Value into an aws-sdk-go item
+func ToItem(values map[string]ddbexpr.Value) map[string]*dynamodb.AttributeValue {
+	item := make(map[string]*dynamodb.AttributeValue, len(values))
+	for name, value := range values {
+		item[name] = ToAttributeValue(value)
+	}
+
+	return item
+}
+
+// CopyItem deep copies the attribute values of `item` into `copies`. Copies of strings, numbers,
+// booleans and nulls share a constant number of allocations, so that copying an item of scalars
+// doesn't allocate per attribute value
+func CopyItem(copies, item map[string]*dynamodb.AttributeValue) {
+	stringCount, boolCount := 0, 0
+	for _, attributeValue := range item {
+		switch {
+		case attributeValue.S != nil || attributeValue.N != nil:
+			stringCount++
+		case attributeValue.BOOL != nil || attributeValue.NULL != nil:
+			boolCount++
+		}
+	}
+
+	attributeValues := make([]dynamodb.AttributeValue, 0, stringCount+boolCount)
+	strings, bools := make([]string, 0, stringCount), make([]bool, 0, boolCount)
+	for name, attributeValue := range item {
+		switch {
+		case attributeValue.S != nil:
+			strings = append(strings, *attributeValue.S)
+			attributeValues = append(attributeValues, dynamodb.AttributeValue{S: &strings[len(strings)-1]})
+		case attributeValue.N != nil:
+			strings = append(strings, *attributeValue.N)
+			attributeValues = append(attributeValues, dynamodb.AttributeValue{N: &strings[len(strings)-1]})
+		case attributeValue.BOOL != nil:
+			bools = append(bools, *attributeValue.BOOL)
+			attributeValues = append(attributeValues, dynamodb.AttributeValue{BOOL: &bools[len(bools)-1]})
+		case attributeValue.NULL != nil:
+			bools = append(bools, *attributeValue.NULL)
+			attributeValues = append(attributeValues, dynamodb.AttributeValue{NULL: &bools[len(bools)-1]})
+		default:
+			copies[name] = ToAttributeValue(FromAttributeValue(attributeValue).Clone())
+			continue
+		}
+
+		copies[name] = &attributeValues[len(attributeValues)-1]
+	}
+}
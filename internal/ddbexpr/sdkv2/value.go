@@ -0,0 +1,131 @@
+// Package sdkv2 converts between attribute values of aws-sdk-go-v2 and ddbexpr.Value
+package sdkv2
+
+import (
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// FromAttributeValue converts an aws-sdk-go-v2 attribute value into ddbexpr.Value,
+// nil attribute value is converted into VALUE_NULL
+func FromAttributeValue(attributeValue types.AttributeValue) ddbexpr.Value {
+	switch attributeValueType := attributeValue.(type) {
+	case *types.AttributeValueMemberS:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_S, String: attributeValueType.Value}
+	case *types.AttributeValueMemberN:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_N, String: attributeValueType.Value}
+	case *types.AttributeValueMemberB:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_B, Binary: attributeValueType.Value}
+	case *types.AttributeValueMemberBOOL:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_BOOL, Bool: attributeValueType.Value}
+	case *types.AttributeValueMemberSS:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_SS, Strings: attributeValueType.Value}
+	case *types.AttributeValueMemberNS:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_NS, Strings: attributeValueType.Value}
+	case *types.AttributeValueMemberBS:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_BS, Binaries: attributeValueType.Value}
+	case *types.AttributeValueMemberL:
+		list := make([]ddbexpr.Value, 0, len(attributeValueType.Value))
+		for _, item := range attributeValueType.Value {
+			list = append(list, FromAttributeValue(item))
+		}
+		return ddbexpr.Value{Type: ddbexpr.VALUE_L, List: list}
+	case *types.AttributeValueMemberM:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_M, Map: FromItem(attributeValueType.Value)}
+	default:
+		return ddbexpr.Value{Type: ddbexpr.VALUE_NULL}
+	}
+}
+
+// FromItem converts an aws-sdk-go-v2 item into a map of ddbexpr.Value
+func FromItem(item map[string]types.AttributeValue) map[string]ddbexpr.Value {
+	values := make(map[string]ddbexpr.Value, len(item))
+	for name, attributeValue := range item {
+		values[name] = FromAttributeValue(attributeValue)
+	}
+
+	return values
+}
+
+// ToAttributeValue converts a ddbexpr.Value into an aws-sdk-go-v2 attribute value
+func ToAttributeValue(value ddbexpr.Value) types.AttributeValue {
+	switch value.Type {
+	case ddbexpr.VALUE_S:
+		return &types.AttributeValueMemberS{Value: value.String}
+	case ddbexpr.VALUE_N:
+		return &types.AttributeValueMemberN{Value: value.String}
+	case ddbexpr.VALUE_B:
+		return &types.AttributeValueMemberB{Value: value.Binary}
+	case ddbexpr.VALUE_BOOL:
+		return &types.AttributeValueMemberBOOL{Value: value.Bool}
+	case ddbexpr.VALUE_SS:
+		return &types.AttributeValueMemberSS{Value: value.Strings}
+	case ddbexpr.VALUE_NS:
+		return &types.AttributeValueMemberNS{Value: value.Strings}
+	case ddbexpr.VALUE_BS:
+		return &types.AttributeValueMemberBS{Value: value.Binaries}
+	case ddbexpr.VALUE_L:
+		list := make([]types.AttributeValue, 0, len(value.List))
+		for _, item := range value.List {
+			list = append(list, ToAttributeValue(item))
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case ddbexpr.VALUE_M:
+		return &types.AttributeValueMemberM{Value: ToItem(value.Map)}
+	default:
+		return &types.AttributeValueMemberNULL{Value: true}
+	}
+}
+
+// ToItem converts a map of ddbexpr.Value into an aws-sdk-go-v2 item
+func ToItem(values map[string]ddbexpr.Value) map[string]types.AttributeValue {
+	item := make(map[string]types.AttributeValue, len(values))
+	for name, value := range values {
+		item[name] = ToAttributeValue(value)
+	}
+
+	return item
+}
+
+// CopyItem deep copies the attribute values of `item` into `copies`. Copies of strings, numbers,
+// booleans and nulls share a constant number of allocations, so that copying an item of scalars
+// doesn't allocate per attribute value
+func CopyItem(copies, item map[string]types.AttributeValue) {
+	stringCount, numberCount, boolCount, nullCount := 0, 0, 0, 0
+	for _, attributeValue := range item {
+		switch attributeValue.(type) {
+		case *types.AttributeValueMemberS:
+			stringCount++
+		case *types.AttributeValueMemberN:
+			numberCount++
+		case *types.AttributeValueMemberBOOL:
+			boolCount++
+		case *types.AttributeValueMemberNULL:
+			nullCount++
+		}
+	}
+
+	strings := make([]types.AttributeValueMemberS, 0, stringCount)
+	numbers := make([]types.AttributeValueMemberN, 0, numberCount)
+	bools := make([]types.AttributeValueMemberBOOL, 0, boolCount)
+	nulls := make([]types.AttributeValueMemberNULL, 0, nullCount)
+	for name, attributeValue := range item {
+		switch attributeValueType := attributeValue.(type) {
+		case *types.AttributeValueMemberS:
+			strings = append(strings, types.AttributeValueMemberS{Value: attributeValueType.Value})
+			copies[name] = &strings[len(strings)-1]
+		case *types.AttributeValueMemberN:
+			numbers = append(numbers, types.AttributeValueMemberN{Value: attributeValueType.Value})
+			copies[name] = &numbers[len(numbers)-1]
+		case *types.AttributeValueMemberBOOL:
+			bools = append(bools, types.AttributeValueMemberBOOL{Value: attributeValueType.Value})
+			copies[name] = &bools[len(bools)-1]
+		case *types.AttributeValueMemberNULL:
+			nulls = append(nulls, types.AttributeValueMemberNULL{Value: attributeValueType.Value})
+			copies[name] = &nulls[len(nulls)-1]
+		default:
+			copies[name] = ToAttributeValue(FromAttributeValue(attributeValue).Clone())
+		}
+	}
+}
@@ -0,0 +1,569 @@
+package ddbexpr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type UpdateActionKind int
+
+const (
+	UPDATE_ACTION_SET UpdateActionKind = iota
+	UPDATE_ACTION_REMOVE
+	UPDATE_ACTION_ADD
+	UPDATE_ACTION_DELETE
+)
+
+func (uak UpdateActionKind) String() string {
+	switch uak {
+	case UPDATE_ACTION_SET:
+		return "SET"
+	case UPDATE_ACTION_REMOVE:
+		return "REMOVE"
+	case UPDATE_ACTION_ADD:
+		return "ADD"
+	case UPDATE_ACTION_DELETE:
+		return "DELETE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type UpdateValueKind int
+
+const (
+	UPDATE_VALUE_OPERAND UpdateValueKind = iota
+	UPDATE_VALUE_PLUS
+	UPDATE_VALUE_MINUS
+	UPDATE_VALUE_IF_NOT_EXISTS
+	UPDATE_VALUE_LIST_APPEND
+)
+
+// UpdateValue is the value assigned by SET, ADD or DELETE action
+type UpdateValue struct {
+	Kind UpdateValueKind
+
+	// Text of `this` value in the expression
+	Text string
+
+	// Operand of UPDATE_VALUE_OPERAND
+	Operand Operand
+
+	// Arguments of UPDATE_VALUE_PLUS, UPDATE_VALUE_MINUS, UPDATE_VALUE_IF_NOT_EXISTS
+	// and UPDATE_VALUE_LIST_APPEND
+	Arguments []*UpdateValue
+}
+
+// UpdateAction is a single action of a parsed update expression
+type UpdateAction struct {
+	Kind UpdateActionKind
+
+	// Text of `this` action in the expression
+	Text string
+
+	// Document path updated by `this` action
+	Path Path
+
+	// Value of UPDATE_ACTION_SET, UPDATE_ACTION_ADD and UPDATE_ACTION_DELETE
+	Value *UpdateValue
+}
+
+var updateActionKinds = map[string]UpdateActionKind{
+	"SET":    UPDATE_ACTION_SET,
+	"REMOVE": UPDATE_ACTION_REMOVE,
+	"ADD":    UPDATE_ACTION_ADD,
+	"DELETE": UPDATE_ACTION_DELETE,
+}
+
+// ParseUpdate parses an update expression into its actions, name placeholders are
+// resolved using `names` while value placeholders are kept as is
+func ParseUpdate(expr string, names map[string]string) ([]UpdateAction, error) {
+	parser, err := newParser(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []UpdateAction{}
+	parsedKinds := map[UpdateActionKind]bool{}
+	for parser.peek().Kind != TOKEN_EOF {
+		token := parser.next()
+		kind, ok := updateActionKinds[strings.ToUpper(token.Text)]
+		if token.Kind != TOKEN_IDENTIFIER || !ok {
+			return nil, parser.unexpected(token)
+		}
+
+		if parsedKinds[kind] {
+			return nil, errors.New("clause " + kind.String() + " appears more than once in expression [" + expr + "]")
+		}
+		parsedKinds[kind] = true
+
+		for {
+			action, err := parser.parseUpdateAction(kind)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, action)
+
+			if !parser.peek().Is(",") {
+				break
+			}
+			parser.next()
+		}
+	}
+
+	if len(actions) == 0 {
+		return nil, errors.New("empty update expression")
+	}
+
+	return actions, nil
+}
+
+func (p *parser) parseUpdateAction(kind UpdateActionKind) (UpdateAction, error) {
+	start := p.peek().Pos
+	path, err := p.parsePath()
+	if err != nil {
+		return UpdateAction{}, err
+	}
+
+	action := UpdateAction{Kind: kind, Path: path}
+	switch kind {
+	case UPDATE_ACTION_SET:
+		if err := p.expect("="); err != nil {
+			return UpdateAction{}, err
+		}
+
+		if action.Value, err = p.parseUpdateValue(); err != nil {
+			return UpdateAction{}, err
+		}
+	case UPDATE_ACTION_ADD, UPDATE_ACTION_DELETE:
+		valueStart := p.peek().Pos
+		operand, err := p.parseOperand()
+		if err != nil {
+			return UpdateAction{}, err
+		}
+
+		if operand.Kind != OPERAND_VALUE {
+			return UpdateAction{}, fmt.Errorf("%s action takes a value placeholder at position %d of expression [%s]", kind, valueStart, p.expr)
+		}
+		action.Value = &UpdateValue{Kind: UPDATE_VALUE_OPERAND, Operand: operand, Text: p.text(valueStart)}
+	}
+
+	action.Text = p.text(start)
+	return action, nil
+}
+
+// parseUpdateValue parses the value of SET action, an operand optionally followed by + or - operand
+func (p *parser) parseUpdateValue() (*UpdateValue, error) {
+	start := p.peek().Pos
+	value, err := p.parseUpdateOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.peek().Is("+") && !p.peek().Is("-") {
+		return value, nil
+	}
+
+	kind := UPDATE_VALUE_PLUS
+	if p.next().Is("-") {
+		kind = UPDATE_VALUE_MINUS
+	}
+
+	right, err := p.parseUpdateOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateValue{Kind: kind, Arguments: []*UpdateValue{value, right}, Text: p.text(start)}, nil
+}
+
+func (p *parser) parseUpdateOperand() (*UpdateValue, error) {
+	start := p.peek().Pos
+	if token := p.peek(); token.Kind == TOKEN_IDENTIFIER && p.peekAt(1).Is("(") && (token.Is("if_not_exists") || token.Is("list_append")) {
+		kind := UPDATE_VALUE_LIST_APPEND
+		if p.next().Is("if_not_exists") {
+			kind = UPDATE_VALUE_IF_NOT_EXISTS
+		}
+		p.next() // (
+
+		first, err := p.parseUpdateOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+
+		second, err := p.parseUpdateOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		if kind == UPDATE_VALUE_IF_NOT_EXISTS && (first.Kind != UPDATE_VALUE_OPERAND || first.Operand.Kind != OPERAND_PATH) {
+			return nil, errors.New("first argument of function if_not_exists must be a document path in expression [" + p.expr + "]")
+		}
+
+		return &UpdateValue{Kind: kind, Arguments: []*UpdateValue{first, second}, Text: p.text(start)}, nil
+	}
+
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if operand.Kind == OPERAND_SIZE {
+		return nil, fmt.Errorf("function size is not allowed at position %d of update expression [%s]", start, p.expr)
+	}
+
+	return &UpdateValue{Kind: UPDATE_VALUE_OPERAND, Operand: operand, Text: p.text(start)}, nil
+}
+
+// ApplyUpdate applies `actions` on `item` following the semantics of dynamo db and returns
+// the updated item, `item` itself is left unchanged. `values` holds the values of value
+// placeholders
+//
+// Like dynamo db, every value is evaluated against the item as it was before the update,
+// actions cannot update overlapping document paths and REMOVE of multiple list items refers
+// to the indexes before any of them is removed
+func ApplyUpdate(actions []UpdateAction, item map[string]Value, values map[string]Value) (map[string]Value, error) {
+	for idx, action := range actions {
+		for _, otherAction := range actions[idx+1:] {
+			if action.Path.overlaps(otherAction.Path) {
+				return nil, errors.New("two document paths overlap with each other: [" + action.Path.String() + "] and [" + otherAction.Path.String() + "]")
+			}
+		}
+	}
+
+	updates := make([]func(Value, bool) (Value, bool, error), len(actions))
+	for idx, action := range actions {
+		var value Value
+		if action.Value != nil {
+			var err error
+			if value, err = evaluateUpdateValue(action.Value, item, values); err != nil {
+				return nil, err
+			}
+		}
+
+		updates[idx] = updateFunc(action, value)
+	}
+
+	// removing a list item shifts the ones after it, so removals are applied last
+	// in decreasing order of index
+	order := make([]int, len(actions))
+	for idx := range order {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		action, otherAction := actions[order[i]], actions[order[j]]
+		if (action.Kind == UPDATE_ACTION_REMOVE) != (otherAction.Kind == UPDATE_ACTION_REMOVE) {
+			return otherAction.Kind == UPDATE_ACTION_REMOVE
+		}
+
+		return action.Kind == UPDATE_ACTION_REMOVE && action.Path.compare(otherAction.Path) < 0
+	})
+
+	updated := Value{Type: VALUE_M, Map: item}
+	for _, idx := range order {
+		var err error
+		if updated, err = updatePath(updated, actions[idx].Path, updates[idx]); err != nil {
+			return nil, errors.New(err.Error() + " in [" + actions[idx].Text + "]")
+		}
+	}
+
+	return updated.Map, nil
+}
+
+// updateFunc returns the function computing the new value of the attribute updated by
+// `action` from its old value, returning false removes the attribute
+func updateFunc(action UpdateAction, value Value) func(Value, bool) (Value, bool, error) {
+	switch action.Kind {
+	case UPDATE_ACTION_SET:
+		return func(Value, bool) (Value, bool, error) {
+			return value, true, nil
+		}
+	case UPDATE_ACTION_REMOVE:
+		return func(Value, bool) (Value, bool, error) {
+			return Value{}, false, nil
+		}
+	case UPDATE_ACTION_ADD:
+		return func(old Value, found bool) (Value, bool, error) {
+			added, err := add(old, found, value)
+			return added, err == nil, err
+		}
+	default:
+		return func(old Value, found bool) (Value, bool, error) {
+			if !found {
+				return Value{}, false, nil
+			}
+
+			// dynamo db doesn't store empty sets
+			remaining, err := deleteFromSet(old, value)
+			return remaining, err == nil && setSize(remaining) > 0, err
+		}
+	}
+}
+
+func evaluateUpdateValue(value *UpdateValue, item map[string]Value, values map[string]Value) (Value, error) {
+	switch value.Kind {
+	case UPDATE_VALUE_OPERAND:
+		operand, found, err := ResolveOperand(value.Operand, item, values)
+		if err != nil {
+			return Value{}, err
+		}
+
+		if !found {
+			return Value{}, errors.New("attribute [" + value.Operand.Path.String() + "] used in update expression does not exist")
+		}
+		return operand, nil
+	case UPDATE_VALUE_IF_NOT_EXISTS:
+		if existing, found := value.Arguments[0].Operand.Path.Resolve(item); found {
+			return existing, nil
+		}
+		return evaluateUpdateValue(value.Arguments[1], item, values)
+	}
+
+	left, err := evaluateUpdateValue(value.Arguments[0], item, values)
+	if err != nil {
+		return Value{}, err
+	}
+
+	right, err := evaluateUpdateValue(value.Arguments[1], item, values)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch value.Kind {
+	case UPDATE_VALUE_PLUS, UPDATE_VALUE_MINUS:
+		if left.Type != VALUE_N || right.Type != VALUE_N {
+			return Value{}, errors.New("operands of + and - must be numbers in [" + value.Text + "]")
+		}
+
+		number, err := addNumbers(left.String, right.String, value.Kind == UPDATE_VALUE_MINUS)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Type: VALUE_N, String: number}, nil
+	case UPDATE_VALUE_LIST_APPEND:
+		if left.Type != VALUE_L || right.Type != VALUE_L {
+			return Value{}, errors.New("arguments of list_append must be lists in [" + value.Text + "]")
+		}
+
+		list := make([]Value, 0, len(left.List)+len(right.List))
+		return Value{Type: VALUE_L, List: append(append(list, left.List...), right.List...)}, nil
+	default:
+		return Value{}, fmt.Errorf("unsupported update value kind %d", value.Kind)
+	}
+}
+
+// add adds a number to a number or elements to a set like ADD action of dynamo db,
+// a missing attribute is treated as 0 or an empty set
+func add(old Value, found bool, value Value) (Value, error) {
+	switch value.Type {
+	case VALUE_N:
+		if !found {
+			return value, nil
+		}
+
+		if old.Type != VALUE_N {
+			return Value{}, errors.New("cannot add a number to an attribute of type " + old.Type.String())
+		}
+
+		number, err := addNumbers(old.String, value.String, false)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Type: VALUE_N, String: number}, nil
+	case VALUE_SS, VALUE_NS, VALUE_BS:
+		if !found {
+			return value, nil
+		}
+
+		if old.Type != value.Type {
+			return Value{}, errors.New("cannot add a " + value.Type.String() + " to an attribute of type " + old.Type.String())
+		}
+
+		union, elements := old, old.setElements()
+		union.Strings, union.Binaries = append([]string{}, old.Strings...), append([][]byte{}, old.Binaries...)
+		for _, element := range setValues(value) {
+			key, _ := setElementKey(value.Type, element)
+			if _, ok := elements[key]; !ok {
+				elements[key] = struct{}{}
+				union = appendToSet(union, element)
+			}
+		}
+		return union, nil
+	default:
+		return Value{}, errors.New("ADD action supports only numbers and sets but got " + value.Type.String())
+	}
+}
+
+// deleteFromSet removes the elements of `value` from the set `old` like DELETE action of dynamo db
+func deleteFromSet(old Value, value Value) (Value, error) {
+	if value.Type != VALUE_SS && value.Type != VALUE_NS && value.Type != VALUE_BS {
+		return Value{}, errors.New("DELETE action supports only sets but got " + value.Type.String())
+	}
+
+	if old.Type != value.Type {
+		return Value{}, errors.New("cannot delete a " + value.Type.String() + " from an attribute of type " + old.Type.String())
+	}
+
+	deleted := value.setElements()
+	remaining := Value{Type: old.Type}
+	for _, element := range setValues(old) {
+		if key, _ := setElementKey(old.Type, element); !hasKey(deleted, key) {
+			remaining = appendToSet(remaining, element)
+		}
+	}
+
+	return remaining, nil
+}
+
+// setValues returns the elements of a set as values
+func setValues(set Value) []Value {
+	elements := []Value{}
+	switch set.Type {
+	case VALUE_SS, VALUE_NS:
+		for _, element := range set.Strings {
+			elements = append(elements, Value{Type: setElementType(set.Type), String: element})
+		}
+	case VALUE_BS:
+		for _, element := range set.Binaries {
+			elements = append(elements, Value{Type: VALUE_B, Binary: element})
+		}
+	}
+
+	return elements
+}
+
+func appendToSet(set Value, element Value) Value {
+	if set.Type == VALUE_BS {
+		set.Binaries = append(set.Binaries, element.Binary)
+	} else {
+		set.Strings = append(set.Strings, element.String)
+	}
+
+	return set
+}
+
+func setSize(set Value) int {
+	return len(set.Strings) + len(set.Binaries)
+}
+
+func hasKey(set map[string]struct{}, key string) bool {
+	_, ok := set[key]
+	return ok
+}
+
+// updatePath returns a copy of `container` in which the value at `path` is replaced by the
+// result of `update`, only the maps and lists along the path are copied
+func updatePath(container Value, path Path, update func(Value, bool) (Value, bool, error)) (Value, error) {
+	element := path[0]
+	if element.IsIndex {
+		if container.Type != VALUE_L {
+			return Value{}, errors.New("document path is invalid for update, parent of [" + element.String() + "] is not a list")
+		}
+
+		old, found := Value{}, element.Index < len(container.List)
+		if found {
+			old = container.List[element.Index]
+		}
+
+		newValue, keep, err := updateChild(old, found, path, update)
+		if err != nil {
+			return Value{}, err
+		}
+
+		list := append([]Value{}, container.List...)
+		switch {
+		case found && keep:
+			list[element.Index] = newValue
+		case found:
+			list = append(list[:element.Index], list[element.Index+1:]...)
+		case keep: // setting an index past the end of list appends to it
+			list = append(list, newValue)
+		}
+		return Value{Type: VALUE_L, List: list}, nil
+	}
+
+	if container.Type != VALUE_M {
+		return Value{}, errors.New("document path is invalid for update, parent of [" + element.Name + "] is not a map")
+	}
+
+	old, found := container.Map[element.Name]
+	newValue, keep, err := updateChild(old, found, path, update)
+	if err != nil {
+		return Value{}, err
+	}
+
+	updatedMap := make(map[string]Value, len(container.Map)+1)
+	for name, value := range container.Map {
+		updatedMap[name] = value
+	}
+
+	if keep {
+		updatedMap[element.Name] = newValue
+	} else {
+		delete(updatedMap, element.Name)
+	}
+	return Value{Type: VALUE_M, Map: updatedMap}, nil
+}
+
+// updateChild updates the value of first element of `path`, recursing for the rest of the path
+func updateChild(old Value, found bool, path Path, update func(Value, bool) (Value, bool, error)) (Value, bool, error) {
+	if len(path) == 1 {
+		return update(old, found)
+	}
+
+	if !found {
+		return Value{}, false, errors.New("document path is invalid for update, [" + path[0].String() + "] does not exist")
+	}
+
+	newValue, err := updatePath(old, path[1:], update)
+	return newValue, true, err
+}
+
+// String returns the path element as it appears in a document path
+func (pe PathElement) String() string {
+	return Path{pe}.String()
+}
+
+// overlaps returns true if one of the paths is a prefix of the other
+func (p Path) overlaps(other Path) bool {
+	for idx := 0; idx < min(len(p), len(other)); idx++ {
+		if p[idx] != other[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare orders the paths element by element, names are ordered alphabetically and
+// before indexes while indexes are ordered in decreasing order
+func (p Path) compare(other Path) int {
+	for idx := 0; idx < min(len(p), len(other)); idx++ {
+		element, otherElement := p[idx], other[idx]
+		switch {
+		case element == otherElement:
+			continue
+		case element.IsIndex && otherElement.IsIndex:
+			return otherElement.Index - element.Index
+		case element.IsIndex != otherElement.IsIndex:
+			if element.IsIndex {
+				return 1
+			}
+			return -1
+		default:
+			return strings.Compare(element.Name, otherElement.Name)
+		}
+	}
+
+	return len(p) - len(other)
+}
@@ -0,0 +1,142 @@
+package ddbexpr
+
+import "testing"
+
+func TestApplyUpdate(t *testing.T) {
+	newItem := func() map[string]Value {
+		return map[string]Value{
+			"name":  {Type: VALUE_S, String: "John"},
+			"age":   {Type: VALUE_N, String: "30"},
+			"tags":  {Type: VALUE_SS, Strings: []string{"a", "b"}},
+			"phone": {Type: VALUE_L, List: []Value{{Type: VALUE_S, String: "1"}, {Type: VALUE_S, String: "2"}, {Type: VALUE_S, String: "3"}}},
+			"bank":  {Type: VALUE_M, Map: map[string]Value{"balance": {Type: VALUE_N, String: "10.5"}}},
+		}
+	}
+	names := map[string]string{"#0": "name", "#1": "age", "#2": "bank", "#3": "missing"}
+	values := map[string]Value{
+		":s":    {Type: VALUE_S, String: "Doe"},
+		":n":    {Type: VALUE_N, String: "0.25"},
+		":one":  {Type: VALUE_N, String: "1"},
+		":ss":   {Type: VALUE_SS, Strings: []string{"b", "c"}},
+		":all":  {Type: VALUE_SS, Strings: []string{"a", "b"}},
+		":l":    {Type: VALUE_L, List: []Value{{Type: VALUE_S, String: "4"}}},
+		":null": {Type: VALUE_NULL},
+	}
+	tests := map[string]struct {
+		expr    string
+		want    map[string]Value
+		wantErr bool
+	}{
+		"set and remove": {
+			expr: "SET #0 = :s, #2.#3 = :null REMOVE #1",
+			want: map[string]Value{
+				"name":  {Type: VALUE_S, String: "Doe"},
+				"tags":  newItem()["tags"],
+				"phone": newItem()["phone"],
+				"bank":  {Type: VALUE_M, Map: map[string]Value{"balance": {Type: VALUE_N, String: "10.5"}, "missing": {Type: VALUE_NULL}}},
+			},
+		},
+		"arithmetic": {
+			expr: "SET #1 = #1 - :one, #2.balance = #2.balance + :n",
+			want: map[string]Value{
+				"name":  newItem()["name"],
+				"age":   {Type: VALUE_N, String: "29"},
+				"tags":  newItem()["tags"],
+				"phone": newItem()["phone"],
+				"bank":  {Type: VALUE_M, Map: map[string]Value{"balance": {Type: VALUE_N, String: "10.75"}}},
+			},
+		},
+		"if_not_exists and list_append": {
+			expr: "SET #3 = if_not_exists (#3, :one), phone = list_append (phone, :l), #0 = if_not_exists (#0, :s)",
+			want: map[string]Value{
+				"name":    newItem()["name"],
+				"age":     newItem()["age"],
+				"tags":    newItem()["tags"],
+				"missing": {Type: VALUE_N, String: "1"},
+				"phone":   {Type: VALUE_L, List: []Value{{Type: VALUE_S, String: "1"}, {Type: VALUE_S, String: "2"}, {Type: VALUE_S, String: "3"}, {Type: VALUE_S, String: "4"}}},
+				"bank":    newItem()["bank"],
+			},
+		},
+		"remove list items refers to original indexes": {
+			expr: "REMOVE phone[0], phone[2]",
+			want: map[string]Value{
+				"name":  newItem()["name"],
+				"age":   newItem()["age"],
+				"tags":  newItem()["tags"],
+				"phone": {Type: VALUE_L, List: []Value{{Type: VALUE_S, String: "2"}}},
+				"bank":  newItem()["bank"],
+			},
+		},
+		"set past the end of list appends": {
+			expr: "SET phone[10] = :s",
+			want: map[string]Value{
+				"name":  newItem()["name"],
+				"age":   newItem()["age"],
+				"tags":  newItem()["tags"],
+				"phone": {Type: VALUE_L, List: []Value{{Type: VALUE_S, String: "1"}, {Type: VALUE_S, String: "2"}, {Type: VALUE_S, String: "3"}, {Type: VALUE_S, String: "Doe"}}},
+				"bank":  newItem()["bank"],
+			},
+		},
+		"add": {
+			expr: "ADD #1 :n, tags :ss, #3 :one",
+			want: map[string]Value{
+				"name":    newItem()["name"],
+				"age":     {Type: VALUE_N, String: "30.25"},
+				"tags":    {Type: VALUE_SS, Strings: []string{"a", "b", "c"}},
+				"missing": {Type: VALUE_N, String: "1"},
+				"phone":   newItem()["phone"],
+				"bank":    newItem()["bank"],
+			},
+		},
+		"delete every element removes the set": {
+			expr: "DELETE tags :all",
+			want: map[string]Value{
+				"name":  newItem()["name"],
+				"age":   newItem()["age"],
+				"phone": newItem()["phone"],
+				"bank":  newItem()["bank"],
+			},
+		},
+		"arithmetic on missing attribute":  {expr: "SET #1 = #3 + :one", wantErr: true},
+		"arithmetic on string":             {expr: "SET #1 = #0 + :one", wantErr: true},
+		"set nested in missing map":        {expr: "SET #3.#0 = :s", wantErr: true},
+		"overlapping paths":                {expr: "SET #2 = :s REMOVE #2.balance", wantErr: true},
+		"add string":                       {expr: "ADD #0 :s", wantErr: true},
+		"delete from map":                  {expr: "DELETE #2 :ss", wantErr: true},
+		"list_append on non list":          {expr: "SET phone = list_append (phone, :s)", wantErr: true},
+		"clause appearing twice":           {expr: "SET #0 = :s SET #1 = :n", wantErr: true},
+		"add takes value placeholder only": {expr: "ADD #1 #1", wantErr: true},
+	}
+	for name := range tests {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			item := newItem()
+			actions, err := ParseUpdate(tt.expr, names)
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("ParseUpdate() error = %v", err)
+				}
+				return
+			}
+
+			got, err := ApplyUpdate(actions, item, values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !(Value{Type: VALUE_M, Map: got}).Equal(Value{Type: VALUE_M, Map: tt.want}) {
+				t.Errorf("ApplyUpdate() = %s, want %s", Value{Type: VALUE_M, Map: got}.Format(0), Value{Type: VALUE_M, Map: tt.want}.Format(0))
+			}
+
+			// item passed is left unchanged
+			if !(Value{Type: VALUE_M, Map: item}).Equal(Value{Type: VALUE_M, Map: newItem()}) {
+				t.Errorf("ApplyUpdate() modified the item")
+			}
+		})
+	}
+}
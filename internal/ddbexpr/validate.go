@@ -0,0 +1,253 @@
+package ddbexpr
+
+import (
+	"strconv"
+)
+
+const (
+	// Maximum length in bytes of any expression
+	MaxExpressionLength = 4 * 1024
+
+	// Maximum number of operands on the right hand side of IN, 100 as documented by dynamo db
+	// which rejects longer lists
+	MaxInOperands = 100
+
+	// Maximum size in bytes of an item, including the names of its attributes
+	MaxItemSize = 400 * 1024
+)
+
+type ViolationKind int
+
+const (
+	VIOLATION_EXPRESSION_TOO_LONG ViolationKind = iota
+	VIOLATION_TOO_MANY_IN_OPERANDS
+	VIOLATION_ITEM_TOO_LARGE
+	VIOLATION_OVERLAPPING_PATHS
+	VIOLATION_DUPLICATE_UPDATE_PATH
+)
+
+// Violation is a limit of dynamo db exceeded by an expression
+type Violation struct {
+	Kind ViolationKind
+
+	// Kind of the violating expression as used by ExplainExpressions, e.g. `Update`
+	Expression string
+
+	// Offending document paths, empty when the limit concerns the whole expression
+	Paths []string
+
+	Message string
+}
+
+// Validate checks `exprs` against the limits of dynamo db which are known before the request
+// is sent and returns every violation, an error is returned only if an expression can't be
+// parsed. Size of the item is estimated from the values assigned by the update expression
+func Validate(exprs Expressions, names map[string]string, values map[string]Value) ([]Violation, error) {
+	violations := []Violation{}
+	for _, expr := range []struct {
+		kind string
+		expr *string
+	}{
+		{"KeyCondition", exprs.KeyCondition},
+		{"Condition", exprs.Condition},
+		{"Filter", exprs.Filter},
+		{"Projection", exprs.Projection},
+		{"Update", exprs.Update},
+	} {
+		if expr.expr == nil {
+			continue
+		}
+
+		if len(*expr.expr) > MaxExpressionLength {
+			violations = append(violations, Violation{
+				Kind:       VIOLATION_EXPRESSION_TOO_LONG,
+				Expression: expr.kind,
+				Message:    "expression is " + strconv.Itoa(len(*expr.expr)) + " bytes long, maximum allowed is " + strconv.Itoa(MaxExpressionLength),
+			})
+		}
+
+		var exprViolations []Violation
+		var err error
+		switch expr.kind {
+		case "Projection":
+			exprViolations, err = validateProjection(*expr.expr, names)
+		case "Update":
+			exprViolations, err = validateUpdate(*expr.expr, names, values)
+		default:
+			exprViolations, err = validateCondition(*expr.expr, names)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, violation := range exprViolations {
+			violation.Expression = expr.kind
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations, nil
+}
+
+func validateProjection(expr string, names map[string]string) ([]Violation, error) {
+	paths, err := parseProjectionPaths(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []Violation{}
+	for _, overlapping := range OverlappingPaths(paths) {
+		violations = append(violations, overlappingViolation(overlapping, false))
+	}
+
+	return violations, nil
+}
+
+func validateCondition(expr string, names map[string]string) ([]Violation, error) {
+	condition, err := ParseCondition(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []Violation{}
+	var walk func(condition *Condition)
+	walk = func(condition *Condition) {
+		for _, subCondition := range condition.Conditions {
+			walk(subCondition)
+		}
+
+		if condition.Kind == CONDITION_IN && len(condition.Operands)-1 > MaxInOperands {
+			violation := Violation{
+				Kind:    VIOLATION_TOO_MANY_IN_OPERANDS,
+				Message: "IN has " + strconv.Itoa(len(condition.Operands)-1) + " operands, maximum allowed is " + strconv.Itoa(MaxInOperands),
+			}
+
+			if condition.Operands[0].Kind != OPERAND_VALUE {
+				violation.Paths = []string{condition.Operands[0].Path.String()}
+			}
+			violations = append(violations, violation)
+		}
+	}
+	walk(condition)
+
+	return violations, nil
+}
+
+func validateUpdate(expr string, names map[string]string, values map[string]Value) ([]Violation, error) {
+	actions, err := ParseUpdate(expr, names)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]Path, 0, len(actions))
+	updatedPaths := []string{}
+	itemSize := 0
+	for _, action := range actions {
+		paths = append(paths, action.Path)
+		if action.Value == nil {
+			continue
+		}
+
+		updatedPaths = append(updatedPaths, action.Path.String())
+		itemSize += len(action.Path[0].Name) + updateValueSize(action.Value, values)
+	}
+
+	violations := []Violation{}
+	for _, overlapping := range OverlappingPaths(paths) {
+		violations = append(violations, overlappingViolation(overlapping, true))
+	}
+
+	if itemSize > MaxItemSize {
+		violations = append(violations, Violation{
+			Kind:    VIOLATION_ITEM_TOO_LARGE,
+			Paths:   updatedPaths,
+			Message: "values assigned by update take about " + strconv.Itoa(itemSize) + " bytes, maximum allowed size of an item is " + strconv.Itoa(MaxItemSize),
+		})
+	}
+
+	return violations, nil
+}
+
+// overlappingViolation returns the violation of two overlapping paths, a path appearing in
+// two actions of an update is reported as VIOLATION_DUPLICATE_UPDATE_PATH
+func overlappingViolation(overlapping [2]Path, update bool) Violation {
+	paths := []string{overlapping[0].String(), overlapping[1].String()}
+	if update && len(overlapping[0]) == len(overlapping[1]) {
+		return Violation{
+			Kind:    VIOLATION_DUPLICATE_UPDATE_PATH,
+			Paths:   paths,
+			Message: "document path [" + paths[0] + "] appears more than once",
+		}
+	}
+
+	return Violation{
+		Kind:    VIOLATION_OVERLAPPING_PATHS,
+		Paths:   paths,
+		Message: "two document paths overlap with each other: [" + paths[0] + "] and [" + paths[1] + "]",
+	}
+}
+
+// updateValueSize returns the size of the values used by an update value, values of
+// document paths are unknown and not counted
+func updateValueSize(value *UpdateValue, values map[string]Value) int {
+	if value.Kind != UPDATE_VALUE_OPERAND {
+		size := 0
+		for _, argument := range value.Arguments {
+			size += updateValueSize(argument, values)
+		}
+
+		return size
+	}
+
+	if value.Operand.Kind != OPERAND_VALUE {
+		return 0
+	}
+
+	return EncodedSize(values[value.Operand.ValuePlaceholder])
+}
+
+// EncodedSize returns the approximate size of `value` in bytes as accounted by dynamo db
+func EncodedSize(value Value) int {
+	switch value.Type {
+	case VALUE_S:
+		return len(value.String)
+	case VALUE_N:
+		// numbers take roughly a byte per two significant digits and one extra byte
+		return (len(value.String)+1)/2 + 1
+	case VALUE_B:
+		return len(value.Binary)
+	case VALUE_SS:
+		size := 0
+		for _, str := range value.Strings {
+			size += len(str)
+		}
+		return size
+	case VALUE_NS:
+		size := 0
+		for _, number := range value.Strings {
+			size += EncodedSize(Value{Type: VALUE_N, String: number})
+		}
+		return size
+	case VALUE_BS:
+		size := 0
+		for _, binary := range value.Binaries {
+			size += len(binary)
+		}
+		return size
+	case VALUE_L:
+		size := 3
+		for _, element := range value.List {
+			size += 1 + EncodedSize(element)
+		}
+		return size
+	case VALUE_M:
+		size := 3
+		for key, element := range value.Map {
+			size += 1 + len(key) + EncodedSize(element)
+		}
+		return size
+	default: // VALUE_NULL and VALUE_BOOL
+		return 1
+	}
+}
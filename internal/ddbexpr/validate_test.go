@@ -0,0 +1,49 @@
+package ddbexpr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	projection := "#0, #0.#1"
+	update := "SET #1 = :v, #1 = :v"
+	violations, err := Validate(Expressions{Projection: &projection, Update: &update},
+		map[string]string{"#0": "bank", "#1": "name"},
+		map[string]Value{":v": {Type: VALUE_S, String: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []ViolationKind{}
+	for _, violation := range violations {
+		kinds = append(kinds, violation.Kind)
+	}
+
+	want := []ViolationKind{VIOLATION_OVERLAPPING_PATHS, VIOLATION_DUPLICATE_UPDATE_PATH}
+	if len(kinds) != len(want) || kinds[0] != want[0] || kinds[1] != want[1] {
+		t.Errorf("Validate() kinds = %v, want %v", kinds, want)
+	}
+}
+
+func TestValidateInOperands(t *testing.T) {
+	for _, operands := range []int{MaxInOperands, MaxInOperands + 1} {
+		condition := "#0 IN (" + strings.Repeat(":v, ", operands-1) + ":v)"
+		violations, err := Validate(Expressions{Condition: &condition},
+			map[string]string{"#0": "name"},
+			map[string]Value{":v": {Type: VALUE_S, String: "a"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantViolations := 0
+		if operands > MaxInOperands {
+			wantViolations = 1
+		}
+		if len(violations) != wantViolations {
+			t.Errorf("Validate() of IN with %d operands = %v, want %d violations", operands, violations, wantViolations)
+		} else if wantViolations > 0 && violations[0].Kind != VIOLATION_TOO_MANY_IN_OPERANDS {
+			t.Errorf("Validate() of IN with %d operands kind = %v, want %v", operands, violations[0].Kind, VIOLATION_TOO_MANY_IN_OPERANDS)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package ddbexpr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type ValueType int
+
+const (
+	VALUE_NULL ValueType = iota
+	VALUE_S
+	VALUE_N
+	VALUE_B
+	VALUE_BOOL
+	VALUE_SS
+	VALUE_NS
+	VALUE_BS
+	VALUE_L
+	VALUE_M
+)
+
+func (vt ValueType) String() string {
+	switch vt {
+	case VALUE_NULL:
+		return "NULL"
+	case VALUE_S:
+		return "S"
+	case VALUE_N:
+		return "N"
+	case VALUE_B:
+		return "B"
+	case VALUE_BOOL:
+		return "BOOL"
+	case VALUE_SS:
+		return "SS"
+	case VALUE_NS:
+		return "NS"
+	case VALUE_BS:
+		return "BS"
+	case VALUE_L:
+		return "L"
+	case VALUE_M:
+		return "M"
+	default:
+		return "ValueType(" + strconv.Itoa(int(vt)) + ")"
+	}
+}
+
+// Value is a dynamo db attribute value independent of the aws sdk version
+type Value struct {
+	Type ValueType
+
+	// Value of VALUE_S and VALUE_N, numbers are kept as string to not lose precision
+	String string
+
+	// Value of VALUE_B
+	Binary []byte
+
+	// Value of VALUE_BOOL
+	Bool bool
+
+	// Value of VALUE_SS and VALUE_NS
+	Strings []string
+
+	// Value of VALUE_BS
+	Binaries [][]byte
+
+	// Value of VALUE_L
+	List []Value
+
+	// Value of VALUE_M
+	Map map[string]Value
+}
+
+// Format pretty prints `this` value, strings and binaries longer than `maxLength` are truncated
+// and so are the sets, lists and maps. A non positive `maxLength` disables truncation
+func (v Value) Format(maxLength int) string {
+	out := strings.Builder{}
+	v.format(&out, maxLength)
+	switch v.Type {
+	case VALUE_SS, VALUE_NS, VALUE_BS, VALUE_L, VALUE_M:
+		return truncate(out.String(), maxLength)
+	default:
+		return out.String()
+	}
+}
+
+// Equal returns true if `this` value is equal to `other`, numbers are compared numerically
+// and sets are compared irrespective of the order of their elements
+func (v Value) Equal(other Value) bool {
+	if v.Type != other.Type {
+		return false
+	}
+
+	switch v.Type {
+	case VALUE_NULL:
+		return true
+	case VALUE_S:
+		return v.String == other.String
+	case VALUE_N:
+		cmp, err := CompareNumbers(v.String, other.String)
+		return err == nil && cmp == 0
+	case VALUE_B:
+		return bytes.Equal(v.Binary, other.Binary)
+	case VALUE_BOOL:
+		return v.Bool == other.Bool
+	case VALUE_SS, VALUE_NS, VALUE_BS:
+		return equalSets(v.setElements(), other.setElements())
+	case VALUE_L:
+		if len(v.List) != len(other.List) {
+			return false
+		}
+
+		for idx := range v.List {
+			if !v.List[idx].Equal(other.List[idx]) {
+				return false
+			}
+		}
+		return true
+	case VALUE_M:
+		if len(v.Map) != len(other.Map) {
+			return false
+		}
+
+		for key, value := range v.Map {
+			otherValue, ok := other.Map[key]
+			if !ok || !value.Equal(otherValue) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// Clone returns a deep copy of `this` value which shares no slice or map with it
+func (v Value) Clone() Value {
+	clone := Value{Type: v.Type, String: v.String, Bool: v.Bool, Binary: bytes.Clone(v.Binary), Strings: slices.Clone(v.Strings)}
+	if v.Binaries != nil {
+		clone.Binaries = make([][]byte, 0, len(v.Binaries))
+		for _, binary := range v.Binaries {
+			clone.Binaries = append(clone.Binaries, bytes.Clone(binary))
+		}
+	}
+
+	if v.List != nil {
+		clone.List = make([]Value, 0, len(v.List))
+		for _, item := range v.List {
+			clone.List = append(clone.List, item.Clone())
+		}
+	}
+
+	if v.Map != nil {
+		clone.Map = make(map[string]Value, len(v.Map))
+		for key, value := range v.Map {
+			clone.Map[key] = value.Clone()
+		}
+	}
+
+	return clone
+}
+
+// setElements returns the elements of a set in their canonical form
+func (v Value) setElements() map[string]struct{} {
+	elements := map[string]struct{}{}
+	switch v.Type {
+	case VALUE_SS, VALUE_NS:
+		for _, str := range v.Strings {
+			key, _ := setElementKey(v.Type, Value{Type: setElementType(v.Type), String: str})
+			elements[key] = struct{}{}
+		}
+	case VALUE_BS:
+		for _, binary := range v.Binaries {
+			elements[string(binary)] = struct{}{}
+		}
+	}
+
+	return elements
+}
+
+// setElementType returns the type of elements of a set type
+func setElementType(setType ValueType) ValueType {
+	switch setType {
+	case VALUE_SS:
+		return VALUE_S
+	case VALUE_NS:
+		return VALUE_N
+	case VALUE_BS:
+		return VALUE_B
+	default:
+		return VALUE_NULL
+	}
+}
+
+// setElementKey returns the canonical form of `element` in a set of type `setType`, returns
+// false if element can't be stored in such set
+func setElementKey(setType ValueType, element Value) (string, bool) {
+	if setType != VALUE_SS && setType != VALUE_NS && setType != VALUE_BS || element.Type != setElementType(setType) {
+		return "", false
+	}
+
+	switch element.Type {
+	case VALUE_N:
+		if rat, ok := new(big.Rat).SetString(element.String); ok {
+			return rat.RatString(), true
+		}
+		return element.String, true
+	case VALUE_B:
+		return string(element.Binary), true
+	default:
+		return element.String, true
+	}
+}
+
+// ScalarKey returns a string which is the same for equal strings, numbers or binaries and
+// different otherwise, e.g. to identify items by their key attributes. Returns false for
+// values of other types
+func ScalarKey(value Value) (string, bool) {
+	setType := map[ValueType]ValueType{VALUE_S: VALUE_SS, VALUE_N: VALUE_NS, VALUE_B: VALUE_BS}[value.Type]
+	key, ok := setElementKey(setType, value)
+	if !ok {
+		return "", false
+	}
+
+	return value.Type.String() + ":" + key, true
+}
+
+func equalSets(set, otherSet map[string]struct{}) bool {
+	if len(set) != len(otherSet) {
+		return false
+	}
+
+	for element := range set {
+		if _, ok := otherSet[element]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CompareNumbers compares two dynamo db numbers, returns -1, 0 or +1 like strings.Compare
+func CompareNumbers(number, otherNumber string) (int, error) {
+	rat, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return 0, errors.New("invalid number [" + number + "]")
+	}
+
+	otherRat, ok := new(big.Rat).SetString(otherNumber)
+	if !ok {
+		return 0, errors.New("invalid number [" + otherNumber + "]")
+	}
+
+	return rat.Cmp(otherRat), nil
+}
+
+// addNumbers adds two dynamo db numbers, `otherNumber` is subtracted instead when `subtract` is true
+func addNumbers(number, otherNumber string, subtract bool) (string, error) {
+	rat, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return "", errors.New("invalid number [" + number + "]")
+	}
+
+	otherRat, ok := new(big.Rat).SetString(otherNumber)
+	if !ok {
+		return "", errors.New("invalid number [" + otherNumber + "]")
+	}
+
+	if subtract {
+		otherRat.Neg(otherRat)
+	}
+
+	return formatNumber(rat.Add(rat, otherRat)), nil
+}
+
+// formatNumber formats a decimal number without exponent and trailing zeros
+func formatNumber(rat *big.Rat) string {
+	if rat.IsInt() {
+		return rat.Num().String()
+	}
+
+	// numbers of dynamo db are decimals, so scaling by 10 eventually yields an integer
+	scale, ten := 0, big.NewRat(10, 1)
+	for scaled := new(big.Rat).Set(rat); !scaled.IsInt(); scale++ {
+		scaled.Mul(scaled, ten)
+	}
+
+	return rat.FloatString(scale)
+}
+
+func (v Value) format(out *strings.Builder, maxLength int) {
+	switch v.Type {
+	case VALUE_NULL:
+		out.WriteString("null")
+	case VALUE_S:
+		out.WriteString(strconv.Quote(truncate(v.String, maxLength)))
+	case VALUE_N:
+		out.WriteString(v.String)
+	case VALUE_B:
+		out.WriteString(formatBinary(v.Binary, maxLength))
+	case VALUE_BOOL:
+		out.WriteString(strconv.FormatBool(v.Bool))
+	case VALUE_SS, VALUE_NS:
+		out.WriteString("<<")
+		for idx, str := range v.Strings {
+			if idx > 0 {
+				out.WriteString(", ")
+			}
+
+			if v.Type == VALUE_SS {
+				out.WriteString(strconv.Quote(truncate(str, maxLength)))
+			} else {
+				out.WriteString(str)
+			}
+		}
+		out.WriteString(">>")
+	case VALUE_BS:
+		out.WriteString("<<")
+		for idx, binary := range v.Binaries {
+			if idx > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(formatBinary(binary, maxLength))
+		}
+		out.WriteString(">>")
+	case VALUE_L:
+		out.WriteString("[")
+		for idx, item := range v.List {
+			if idx > 0 {
+				out.WriteString(", ")
+			}
+			item.format(out, maxLength)
+		}
+		out.WriteString("]")
+	case VALUE_M:
+		keys := make([]string, 0, len(v.Map))
+		for key := range v.Map {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		out.WriteString("{")
+		for idx, key := range keys {
+			if idx > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(strconv.Quote(key) + ": ")
+			v.Map[key].format(out, maxLength)
+		}
+		out.WriteString("}")
+	}
+}
+
+func formatBinary(binary []byte, maxLength int) string {
+	return "b64'" + truncate(base64.StdEncoding.EncodeToString(binary), maxLength) + "'"
+}
+
+// truncate cuts `str` down to `maxLength` bytes without splitting a rune
+func truncate(str string, maxLength int) string {
+	if maxLength <= 0 || len(str) <= maxLength {
+		return str
+	}
+
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(str[end]) {
+		end--
+	}
+
+	return str[:end] + "..."
+}
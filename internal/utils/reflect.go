@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+)
+
+// IsNumber returns true if `value`, after following pointers, is an integer or a floating point
+// number i.e. a value which the marshallers encode as a dynamo db number
+func IsNumber(value any) bool {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// AttributeName returns the dynamo db attribute name of a struct field as used by the
+// marshallers i.e. from dynamodbav tag then json tag and then the field name, returns
+// false if the field is skipped by the marshallers
+func AttributeName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false
+	}
+
+	for _, tagKey := range []string{"dynamodbav", "json"} {
+		tag, ok := field.Tag.Lookup(tagKey)
+		if !ok {
+			continue
+		}
+
+		if tag == "-" {
+			return "", false
+		}
+
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name, true
+		}
+	}
+
+	return field.Name, true
+}
+
+// DeepCopy returns a copy of `value` which shares no pointer, slice or map with it, values
+// referred by more than one pointer stay shared within the copy. Unexported fields of structs
+// can't be set and are copied as they are, so values reachable only through them are shared
+func DeepCopy(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	return deepCopy(reflect.ValueOf(value), map[uintptr]reflect.Value{}).Interface()
+}
+
+// deepCopy copies `value`, `copies` maps the pointers already copied to their copy
+func deepCopy(value reflect.Value, copies map[uintptr]reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return value
+		}
+
+		if copied, ok := copies[value.Pointer()]; ok {
+			return copied
+		}
+
+		copied := reflect.New(value.Type().Elem())
+		copies[value.Pointer()] = copied
+		copied.Elem().Set(deepCopy(value.Elem(), copies))
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(deepCopy(value.Elem(), copies))
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for idx := 0; idx < value.Len(); idx++ {
+			copied.Index(idx).Set(deepCopy(value.Index(idx), copies))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(value.Type()).Elem()
+		for idx := 0; idx < value.Len(); idx++ {
+			copied.Index(idx).Set(deepCopy(value.Index(idx), copies))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(deepCopy(iter.Key(), copies), deepCopy(iter.Value(), copies))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for idx := 0; idx < value.NumField(); idx++ {
+			if value.Type().Field(idx).IsExported() {
+				copied.Field(idx).Set(deepCopy(value.Field(idx), copies))
+			}
+		}
+		return copied
+	default:
+		return value
+	}
+}
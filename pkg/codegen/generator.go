@@ -90,7 +90,9 @@ func (g *Generator) Run(out io.Writer) error {
 		fmt.Fprintln(g.out, "func New"+rootStructName+"_ExpressionBuilder() dynexpr.DDBItemExpressionBuilder[*"+rootStructName+"_ExpressionBuilder] {")
 		fmt.Fprintln(g.out, "\treturn dynexpr.NewDDBItemExpressionBuilder(&"+rootStructName+"_ExpressionBuilder{})")
 		fmt.Fprintln(g.out, "}")
-		fmt.Println()
+		fmt.Fprintln(g.out, "func New"+rootStructName+"_ExpressionBuilderPool() *dynexpr.DDBItemExpressionBuilderPool[*"+rootStructName+"_ExpressionBuilder] {")
+		fmt.Fprintln(g.out, "\treturn dynexpr.NewDDBItemExpressionBuilderPool(&"+rootStructName+"_ExpressionBuilder{})")
+		fmt.Fprintln(g.out, "}")
 	}
 
 	g.printHeader()
@@ -210,7 +212,6 @@ func (g *Generator) genStructExpressionBuilder(t reflect.Type) error {
 		}
 	}
 	fmt.Fprintln(g.out, "}")
-	fmt.Println()
 
 	return nil
 }
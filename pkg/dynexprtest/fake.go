@@ -0,0 +1,841 @@
+// Package dynexprtest provides an in-memory fake of dynamo db which evaluates the expressions
+// built by dynexpr, including projections, key conditions, conditions, filters and updates,
+// so that code using dynamo db can be tested hermetically
+//
+// Only the operations GetItem, PutItem, UpdateItem, DeleteItem, Query, Scan, BatchGetItem,
+// BatchWriteItem, TransactGetItems and TransactWriteItems are supported, the PartiQL operations
+// return an UnsupportedOperationException, see ClientV1 and ClientV2
+package dynexprtest
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+)
+
+const (
+	// Maximum number of items in a single transaction
+	maxTransactionItems int = 100
+	// Maximum number of keys in a single BatchGetItem
+	maxBatchGetItems int = 100
+	// Maximum number of writes in a single BatchWriteItem
+	maxBatchWriteItems int = 25
+)
+
+// Error codes of dynamo db returned by the fake
+const (
+	errorCodeValidation             = "ValidationException"
+	errorCodeResourceNotFound       = "ResourceNotFoundException"
+	errorCodeConditionalCheckFailed = "ConditionalCheckFailedException"
+	errorCodeTransactionCanceled    = "TransactionCanceledException"
+	errorCodeUnsupportedOperation   = "UnsupportedOperationException"
+)
+
+// fakeError is an error of the fake independent of the aws sdk version, clients convert it
+// into the error types of their sdk
+type fakeError struct {
+	code    string
+	message string
+
+	// Cancellation reason of every item of a canceled transaction
+	cancellationReasons []string
+}
+
+func (fe *fakeError) Error() string {
+	return fe.code + ": " + fe.message
+}
+
+func validationError(message string) error {
+	return &fakeError{code: errorCodeValidation, message: message}
+}
+
+// unsupportedError is returned by the operations of dynamo db which the fake doesn't support
+func unsupportedError(operation string) error {
+	return &fakeError{code: errorCodeUnsupportedOperation, message: "operation [" + operation + "] is not supported by the fake"}
+}
+
+// Fake is an in-memory dynamo db, safe for concurrent use. Tables must be created with
+// CreateTable before use
+type Fake struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+func New() *Fake {
+	return &Fake{
+		tables: map[string]*table{},
+	}
+}
+
+// CreateTable creates an empty table whose items are identified by `partitionKey` and
+// `sortKey`, pass an empty sort key for a table having only a partition key
+func (f *Fake) CreateTable(tableName, partitionKey, sortKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.tables[tableName]; ok {
+		return errors.New("table [" + tableName + "] already exists")
+	}
+
+	if partitionKey == "" {
+		return errors.New("partition key of table [" + tableName + "] cannot be empty")
+	}
+
+	f.tables[tableName] = &table{
+		name:         tableName,
+		partitionKey: partitionKey,
+		sortKey:      sortKey,
+		items:        map[string]map[string]ddbexpr.Value{},
+	}
+	return nil
+}
+
+type table struct {
+	name         string
+	partitionKey string
+	sortKey      string
+
+	// Items of the table by their key, see itemKey
+	items map[string]map[string]ddbexpr.Value
+}
+
+// keyAttributeNames returns the names of key attributes of `this` table
+func (t *table) keyAttributeNames() []string {
+	if t.sortKey == "" {
+		return []string{t.partitionKey}
+	}
+
+	return []string{t.partitionKey, t.sortKey}
+}
+
+// itemKey returns the string identifying the item having key attributes of `item`
+func (t *table) itemKey(item map[string]ddbexpr.Value) (string, error) {
+	keys := []string{}
+	for _, keyAttributeName := range t.keyAttributeNames() {
+		value, ok := item[keyAttributeName]
+		if !ok {
+			return "", validationError("one of the required keys was not given a value, missing [" + keyAttributeName + "] of table [" + t.name + "]")
+		}
+
+		key, ok := ddbexpr.ScalarKey(value)
+		if !ok {
+			return "", validationError("key attribute [" + keyAttributeName + "] must be a string, number or binary but got " + value.Type.String())
+		}
+		keys = append(keys, key)
+	}
+
+	return strings.Join(keys, "|"), nil
+}
+
+// validateKey returns the item key of `key` which must hold only the key attributes
+func (t *table) validateKey(key map[string]ddbexpr.Value) (string, error) {
+	itemKey, err := t.itemKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) != len(t.keyAttributeNames()) {
+		return "", validationError("the provided key element does not match the schema of table [" + t.name + "]")
+	}
+
+	return itemKey, nil
+}
+
+// extractKey returns the key attributes of `item`
+func (t *table) extractKey(item map[string]ddbexpr.Value) map[string]ddbexpr.Value {
+	key := map[string]ddbexpr.Value{}
+	for _, keyAttributeName := range t.keyAttributeNames() {
+		key[keyAttributeName] = item[keyAttributeName]
+	}
+
+	return key
+}
+
+// sortedItems returns the items of `this` table, ordered by partition key and sort key
+func (t *table) sortedItems() []map[string]ddbexpr.Value {
+	items := make([]map[string]ddbexpr.Value, 0, len(t.items))
+	for _, item := range t.items {
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, keyAttributeName := range t.keyAttributeNames() {
+			if cmp, _ := ddbexpr.CompareOrdered(items[i][keyAttributeName], items[j][keyAttributeName]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	return items
+}
+
+// expressions holds the expressions of a request with their names and values
+type expressions struct {
+	keyCondition *string
+	condition    *string
+	filter       *string
+	projection   *string
+	update       *string
+	names        map[string]string
+	values       map[string]ddbexpr.Value
+}
+
+// matches evaluates a condition, key condition or filter expression against `item`
+func (e expressions) matches(expr *string, item map[string]ddbexpr.Value) (bool, error) {
+	if expr == nil {
+		return true, nil
+	}
+
+	condition, err := ddbexpr.ParseCondition(*expr, e.names)
+	if err != nil {
+		return false, validationError(err.Error())
+	}
+
+	matched, _, err := ddbexpr.EvaluateCondition(condition, item, e.values)
+	if err != nil {
+		return false, validationError(err.Error())
+	}
+
+	return matched, nil
+}
+
+// checkCondition returns ConditionalCheckFailedException if `item` doesn't satisfy the condition
+func (e expressions) checkCondition(item map[string]ddbexpr.Value) error {
+	matched, err := e.matches(e.condition, item)
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return &fakeError{code: errorCodeConditionalCheckFailed, message: "the conditional request failed"}
+	}
+
+	return nil
+}
+
+// project applies the projection expression on `item`
+func (e expressions) project(item map[string]ddbexpr.Value) (map[string]ddbexpr.Value, error) {
+	if e.projection == nil {
+		return item, nil
+	}
+
+	paths, err := ddbexpr.ParseProjection(*e.projection, e.names)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	return ddbexpr.Project(item, paths), nil
+}
+
+// write is a validated write of a single item, it is applied only after the conditions of
+// every write of the request are satisfied
+type write struct {
+	table   *table
+	itemKey string
+
+	// Item before and after the write, nil if the item doesn't exist
+	oldItem map[string]ddbexpr.Value
+	newItem map[string]ddbexpr.Value
+}
+
+func (w write) apply() {
+	if w.newItem == nil {
+		delete(w.table.items, w.itemKey)
+	} else {
+		w.table.items[w.itemKey] = w.newItem
+	}
+}
+
+func (f *Fake) lookupTable(tableName string) (*table, error) {
+	t, ok := f.tables[tableName]
+	if !ok {
+		return nil, &fakeError{code: errorCodeResourceNotFound, message: "requested resource not found, table [" + tableName + "] does not exist"}
+	}
+
+	return t, nil
+}
+
+func (f *Fake) getItem(tableName string, key map[string]ddbexpr.Value, exprs expressions) (map[string]ddbexpr.Value, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, _, err := f.lookupItem(tableName, key, exprs)
+	return item, err
+}
+
+// lookupItem returns the projected item having `key` and the key identifying it in its table,
+// the item is nil if it doesn't exist
+func (f *Fake) lookupItem(tableName string, key map[string]ddbexpr.Value, exprs expressions) (map[string]ddbexpr.Value, string, error) {
+	t, err := f.lookupTable(tableName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	itemKey, err := t.validateKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	item, ok := t.items[itemKey]
+	if !ok {
+		return nil, itemKey, nil
+	}
+
+	item, err = exprs.project(item)
+	return item, itemKey, err
+}
+
+func (f *Fake) putItem(tableName string, item map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w, err := f.preparePut(tableName, item, exprs)
+	if err != nil {
+		return write{}, err
+	}
+
+	w.apply()
+	return w, nil
+}
+
+func (f *Fake) preparePut(tableName string, item map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	t, err := f.lookupTable(tableName)
+	if err != nil {
+		return write{}, err
+	}
+
+	itemKey, err := t.itemKey(item)
+	if err != nil {
+		return write{}, err
+	}
+
+	oldItem := t.items[itemKey]
+	if err := exprs.checkCondition(oldItem); err != nil {
+		return write{}, err
+	}
+
+	return write{table: t, itemKey: itemKey, oldItem: oldItem, newItem: item}, nil
+}
+
+func (f *Fake) updateItem(tableName string, key map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w, err := f.prepareUpdate(tableName, key, exprs)
+	if err != nil {
+		return write{}, err
+	}
+
+	w.apply()
+	return w, nil
+}
+
+func (f *Fake) prepareUpdate(tableName string, key map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	t, err := f.lookupTable(tableName)
+	if err != nil {
+		return write{}, err
+	}
+
+	itemKey, err := t.validateKey(key)
+	if err != nil {
+		return write{}, err
+	}
+
+	oldItem := t.items[itemKey]
+	if err := exprs.checkCondition(oldItem); err != nil {
+		return write{}, err
+	}
+
+	// updating an item which doesn't exist creates it
+	newItem := oldItem
+	if newItem == nil {
+		newItem = key
+	}
+
+	if exprs.update != nil {
+		actions, err := ddbexpr.ParseUpdate(*exprs.update, exprs.names)
+		if err != nil {
+			return write{}, validationError(err.Error())
+		}
+
+		for _, action := range actions {
+			for _, keyAttributeName := range t.keyAttributeNames() {
+				if !action.Path[0].IsIndex && action.Path[0].Name == keyAttributeName {
+					return write{}, validationError("cannot update attribute [" + keyAttributeName + "], this attribute is part of the key")
+				}
+			}
+		}
+
+		if newItem, err = ddbexpr.ApplyUpdate(actions, newItem, exprs.values); err != nil {
+			return write{}, validationError(err.Error())
+		}
+	}
+
+	return write{table: t, itemKey: itemKey, oldItem: oldItem, newItem: newItem}, nil
+}
+
+func (f *Fake) deleteItem(tableName string, key map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w, err := f.prepareDelete(tableName, key, exprs)
+	if err != nil {
+		return write{}, err
+	}
+
+	w.apply()
+	return w, nil
+}
+
+func (f *Fake) prepareDelete(tableName string, key map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	t, err := f.lookupTable(tableName)
+	if err != nil {
+		return write{}, err
+	}
+
+	itemKey, err := t.validateKey(key)
+	if err != nil {
+		return write{}, err
+	}
+
+	oldItem := t.items[itemKey]
+	if err := exprs.checkCondition(oldItem); err != nil {
+		return write{}, err
+	}
+
+	return write{table: t, itemKey: itemKey, oldItem: oldItem}, nil
+}
+
+// prepareConditionCheck returns a write which leaves the item unchanged
+func (f *Fake) prepareConditionCheck(tableName string, key map[string]ddbexpr.Value, exprs expressions) (write, error) {
+	w, err := f.prepareDelete(tableName, key, exprs)
+	w.newItem = w.oldItem
+	return w, err
+}
+
+// returnValues returns the attributes requested by ReturnValues of a write
+func returnValues(returnValue string, w write) map[string]ddbexpr.Value {
+	switch returnValue {
+	case "ALL_OLD":
+		return w.oldItem
+	case "ALL_NEW":
+		return w.newItem
+	case "UPDATED_OLD", "UPDATED_NEW":
+		attributes, otherAttributes := w.oldItem, w.newItem
+		if returnValue == "UPDATED_NEW" {
+			attributes, otherAttributes = w.newItem, w.oldItem
+		}
+
+		updated := map[string]ddbexpr.Value{}
+		for name, value := range attributes {
+			if otherValue, ok := otherAttributes[name]; !ok || !otherValue.Equal(value) {
+				updated[name] = value
+			}
+		}
+		return updated
+	default:
+		return nil
+	}
+}
+
+// transactWrite is a single write of a transaction
+type transactWrite struct {
+	tableName string
+
+	// Key of ConditionCheck, Update and Delete
+	key map[string]ddbexpr.Value
+
+	// Item of Put
+	item map[string]ddbexpr.Value
+
+	exprs expressions
+
+	// One of "ConditionCheck", "Put", "Update" and "Delete"
+	operation string
+}
+
+func (f *Fake) transactWriteItems(transactWrites []transactWrite) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(transactWrites) == 0 || len(transactWrites) > maxTransactionItems {
+		return validationError("a transaction must have between 1 and " + strconv.Itoa(maxTransactionItems) + " items")
+	}
+
+	writes := make([]write, 0, len(transactWrites))
+	reasons := make([]string, 0, len(transactWrites))
+	canceled, targetedItems := false, map[*table]map[string]bool{}
+	for _, transactWrite := range transactWrites {
+		var w write
+		var err error
+		switch transactWrite.operation {
+		case "ConditionCheck":
+			w, err = f.prepareConditionCheck(transactWrite.tableName, transactWrite.key, transactWrite.exprs)
+		case "Put":
+			w, err = f.preparePut(transactWrite.tableName, transactWrite.item, transactWrite.exprs)
+		case "Update":
+			w, err = f.prepareUpdate(transactWrite.tableName, transactWrite.key, transactWrite.exprs)
+		case "Delete":
+			w, err = f.prepareDelete(transactWrite.tableName, transactWrite.key, transactWrite.exprs)
+		default:
+			err = validationError("transaction item must have exactly one of ConditionCheck, Put, Update and Delete")
+		}
+
+		var fe *fakeError
+		switch {
+		case errors.As(err, &fe) && fe.code == errorCodeConditionalCheckFailed:
+			canceled = true
+			reasons = append(reasons, "ConditionalCheckFailed")
+			continue
+		case err != nil:
+			return err
+		}
+
+		if targetedItems[w.table] == nil {
+			targetedItems[w.table] = map[string]bool{}
+		}
+		if targetedItems[w.table][w.itemKey] {
+			return validationError("transaction request cannot include multiple operations on one item")
+		}
+		targetedItems[w.table][w.itemKey] = true
+
+		writes = append(writes, w)
+		reasons = append(reasons, "None")
+	}
+
+	if canceled {
+		return &fakeError{
+			code:                errorCodeTransactionCanceled,
+			message:             "transaction cancelled, please refer cancellation reasons for specific reasons [" + strings.Join(reasons, ", ") + "]",
+			cancellationReasons: reasons,
+		}
+	}
+
+	for _, w := range writes {
+		w.apply()
+	}
+
+	return nil
+}
+
+// batchGet holds the keys of BatchGetItem to read from a single table
+type batchGet struct {
+	tableName string
+	keys      []map[string]ddbexpr.Value
+
+	// Projection of the items read
+	exprs expressions
+}
+
+// batchGetItem returns the existing items of every batch get by their table name, items which
+// don't exist are left out
+func (f *Fake) batchGetItem(batchGets []batchGet) (map[string][]map[string]ddbexpr.Value, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keyCount := 0
+	for _, batchGet := range batchGets {
+		keyCount += len(batchGet.keys)
+	}
+
+	if keyCount == 0 || keyCount > maxBatchGetItems {
+		return nil, validationError("a batch get must have between 1 and " + strconv.Itoa(maxBatchGetItems) + " keys")
+	}
+
+	responses := map[string][]map[string]ddbexpr.Value{}
+	for _, batchGet := range batchGets {
+		items, itemKeys := []map[string]ddbexpr.Value{}, map[string]bool{}
+		for _, key := range batchGet.keys {
+			item, itemKey, err := f.lookupItem(batchGet.tableName, key, batchGet.exprs)
+			if err != nil {
+				return nil, err
+			}
+
+			if itemKeys[itemKey] {
+				return nil, validationError("provided list of item keys contains duplicates")
+			}
+			itemKeys[itemKey] = true
+
+			if item != nil {
+				items = append(items, item)
+			}
+		}
+
+		responses[batchGet.tableName] = items
+	}
+
+	return responses, nil
+}
+
+// batchWrite is a single put or delete of BatchWriteItem
+type batchWrite struct {
+	tableName string
+
+	// Key of Delete
+	key map[string]ddbexpr.Value
+
+	// Item of Put
+	item map[string]ddbexpr.Value
+
+	// One of "Put" and "Delete"
+	operation string
+}
+
+// batchWriteItem applies every batch write or none of them when one is invalid, unlike a
+// transaction the writes have no conditions and every write is processed
+func (f *Fake) batchWriteItem(batchWrites []batchWrite) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(batchWrites) == 0 || len(batchWrites) > maxBatchWriteItems {
+		return validationError("a batch write must have between 1 and " + strconv.Itoa(maxBatchWriteItems) + " writes")
+	}
+
+	writes := make([]write, 0, len(batchWrites))
+	targetedItems := map[*table]map[string]bool{}
+	for _, batchWrite := range batchWrites {
+		var w write
+		var err error
+		switch batchWrite.operation {
+		case "Put":
+			w, err = f.preparePut(batchWrite.tableName, batchWrite.item, expressions{})
+		case "Delete":
+			w, err = f.prepareDelete(batchWrite.tableName, batchWrite.key, expressions{})
+		default:
+			err = validationError("write request must have exactly one of PutRequest and DeleteRequest")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if targetedItems[w.table] == nil {
+			targetedItems[w.table] = map[string]bool{}
+		}
+		if targetedItems[w.table][w.itemKey] {
+			return validationError("provided list of item keys contains duplicates")
+		}
+		targetedItems[w.table][w.itemKey] = true
+
+		writes = append(writes, w)
+	}
+
+	for _, w := range writes {
+		w.apply()
+	}
+
+	return nil
+}
+
+// transactGet is a single get of a transaction
+type transactGet struct {
+	tableName string
+	key       map[string]ddbexpr.Value
+
+	// Projection of the item read
+	exprs expressions
+}
+
+// transactGetItems returns the item of every transact get in order, nil for an item which
+// doesn't exist
+func (f *Fake) transactGetItems(transactGets []transactGet) ([]map[string]ddbexpr.Value, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(transactGets) == 0 || len(transactGets) > maxTransactionItems {
+		return nil, validationError("a transaction must have between 1 and " + strconv.Itoa(maxTransactionItems) + " items")
+	}
+
+	items := make([]map[string]ddbexpr.Value, 0, len(transactGets))
+	targetedItems := map[string]map[string]bool{}
+	for _, transactGet := range transactGets {
+		item, itemKey, err := f.lookupItem(transactGet.tableName, transactGet.key, transactGet.exprs)
+		if err != nil {
+			return nil, err
+		}
+
+		if targetedItems[transactGet.tableName] == nil {
+			targetedItems[transactGet.tableName] = map[string]bool{}
+		}
+		if targetedItems[transactGet.tableName][itemKey] {
+			return nil, validationError("transaction request cannot include multiple operations on one item")
+		}
+		targetedItems[transactGet.tableName][itemKey] = true
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// sortedTableNames returns the table names of the request items of a batch request in order,
+// so that the fake validates them deterministically
+func sortedTableNames[R any](requestItems map[string]R) []string {
+	tableNames := make([]string, 0, len(requestItems))
+	for tableName := range requestItems {
+		tableNames = append(tableNames, tableName)
+	}
+
+	sort.Strings(tableNames)
+	return tableNames
+}
+
+// readInput holds the parameters of Query and Scan
+type readInput struct {
+	exprs             expressions
+	indexName         *string
+	scanIndexForward  bool
+	limit             int
+	exclusiveStartKey map[string]ddbexpr.Value
+}
+
+type readOutput struct {
+	items            []map[string]ddbexpr.Value
+	scannedCount     int
+	lastEvaluatedKey map[string]ddbexpr.Value
+}
+
+func (f *Fake) query(tableName string, input readInput) (readOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.lookupTable(tableName)
+	if err != nil {
+		return readOutput{}, err
+	}
+
+	if input.indexName != nil {
+		return readOutput{}, validationError("secondary indexes are not supported by the fake, index [" + *input.indexName + "]")
+	}
+
+	if input.exprs.keyCondition == nil {
+		return readOutput{}, validationError("query must have a key condition expression")
+	}
+
+	keyCondition, err := ddbexpr.ParseCondition(*input.exprs.keyCondition, input.exprs.names)
+	if err != nil {
+		return readOutput{}, validationError(err.Error())
+	}
+
+	if err := t.validateKeyCondition(keyCondition); err != nil {
+		return readOutput{}, err
+	}
+
+	items := []map[string]ddbexpr.Value{}
+	for _, item := range t.sortedItems() {
+		matched, err := input.exprs.matches(input.exprs.keyCondition, item)
+		if err != nil {
+			return readOutput{}, err
+		}
+
+		if matched {
+			items = append(items, item)
+		}
+	}
+
+	if !input.scanIndexForward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	return t.read(items, input)
+}
+
+func (f *Fake) scan(tableName string, input readInput) (readOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.lookupTable(tableName)
+	if err != nil {
+		return readOutput{}, err
+	}
+
+	if input.indexName != nil {
+		return readOutput{}, validationError("secondary indexes are not supported by the fake, index [" + *input.indexName + "]")
+	}
+
+	return t.read(t.sortedItems(), input)
+}
+
+// read pages through `items` from the exclusive start key, applying limit, filter and projection
+func (t *table) read(items []map[string]ddbexpr.Value, input readInput) (readOutput, error) {
+	if input.exclusiveStartKey != nil {
+		startKey, err := t.validateKey(input.exclusiveStartKey)
+		if err != nil {
+			return readOutput{}, err
+		}
+
+		for idx, item := range items {
+			if itemKey, _ := t.itemKey(item); itemKey == startKey {
+				items = items[idx+1:]
+				break
+			}
+		}
+	}
+
+	output := readOutput{items: []map[string]ddbexpr.Value{}}
+	if input.limit > 0 && len(items) > input.limit {
+		items = items[:input.limit]
+		output.lastEvaluatedKey = t.extractKey(items[len(items)-1])
+	}
+
+	for _, item := range items {
+		output.scannedCount++
+		matched, err := input.exprs.matches(input.exprs.filter, item)
+		if err != nil {
+			return readOutput{}, err
+		}
+
+		if !matched {
+			continue
+		}
+
+		projected, err := input.exprs.project(item)
+		if err != nil {
+			return readOutput{}, err
+		}
+		output.items = append(output.items, projected)
+	}
+
+	return output, nil
+}
+
+// validateKeyCondition validates that the key condition has an equality condition on
+// partition key and at most one condition on sort key
+func (t *table) validateKeyCondition(keyCondition *ddbexpr.Condition) error {
+	conditions := []*ddbexpr.Condition{keyCondition}
+	if keyCondition.Kind == ddbexpr.CONDITION_AND {
+		conditions = keyCondition.Conditions
+	}
+
+	conditionedKeys := map[string]bool{}
+	for _, condition := range conditions {
+		if len(condition.Operands) == 0 || condition.Operands[0].Kind != ddbexpr.OPERAND_PATH || len(condition.Operands[0].Path) != 1 {
+			return validationError("invalid key condition [" + condition.Text + "]")
+		}
+
+		name := condition.Operands[0].Path[0].Name
+		switch {
+		case conditionedKeys[name]:
+			return validationError("key condition has more than one condition on key attribute [" + name + "]")
+		case name == t.partitionKey && condition.Kind == ddbexpr.CONDITION_COMPARE && condition.Operator == "=":
+		case name == t.sortKey && name != "" && (condition.Kind == ddbexpr.CONDITION_COMPARE && condition.Operator != "<>" ||
+			condition.Kind == ddbexpr.CONDITION_BETWEEN || condition.Kind == ddbexpr.CONDITION_FUNCTION && condition.Operator == "begins_with"):
+		default:
+			return validationError("invalid key condition [" + condition.Text + "] on table [" + t.name + "]")
+		}
+		conditionedKeys[name] = true
+	}
+
+	if !conditionedKeys[t.partitionKey] {
+		return validationError("key condition must have an equality condition on partition key [" + t.partitionKey + "]")
+	}
+
+	return nil
+}
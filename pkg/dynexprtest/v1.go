@@ -0,0 +1,406 @@
+package dynexprtest
+
+import (
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv1"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// ClientV1 is an aws-sdk-go dynamo db client backed by Fake. ExecuteStatement,
+// BatchExecuteStatement and ExecuteTransaction return an UnsupportedOperationException, methods
+// of dynamodbiface.DynamoDBAPI other than these and GetItem, PutItem, UpdateItem, DeleteItem,
+// Query, Scan, BatchGetItem, BatchWriteItem, TransactGetItems and TransactWriteItems panic
+type ClientV1 struct {
+	dynamodbiface.DynamoDBAPI
+
+	fake *Fake
+}
+
+// ClientV1 returns an aws-sdk-go dynamo db client operating on `this` fake
+func (f *Fake) ClientV1() *ClientV1 {
+	return &ClientV1{fake: f}
+}
+
+func (c *ClientV1) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return c.GetItemWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	item, err := c.fake.getItem(aws.StringValue(input.TableName), sdkv1.FromItem(input.Key), expressions{
+		projection: input.ProjectionExpression,
+		names:      aws.StringValueMap(input.ExpressionAttributeNames),
+	})
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	return &dynamodb.GetItemOutput{Item: toItemV1(item)}, nil
+}
+
+func (c *ClientV1) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return c.PutItemWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	w, err := c.fake.putItem(aws.StringValue(input.TableName), sdkv1.FromItem(input.Item), expressions{
+		condition: input.ConditionExpression,
+		names:     aws.StringValueMap(input.ExpressionAttributeNames),
+		values:    sdkv1.FromItem(input.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	return &dynamodb.PutItemOutput{Attributes: toItemV1(returnValues(aws.StringValue(input.ReturnValues), w))}, nil
+}
+
+func (c *ClientV1) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	return c.UpdateItemWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	w, err := c.fake.updateItem(aws.StringValue(input.TableName), sdkv1.FromItem(input.Key), expressions{
+		condition: input.ConditionExpression,
+		update:    input.UpdateExpression,
+		names:     aws.StringValueMap(input.ExpressionAttributeNames),
+		values:    sdkv1.FromItem(input.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	return &dynamodb.UpdateItemOutput{Attributes: toItemV1(returnValues(aws.StringValue(input.ReturnValues), w))}, nil
+}
+
+func (c *ClientV1) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return c.DeleteItemWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	w, err := c.fake.deleteItem(aws.StringValue(input.TableName), sdkv1.FromItem(input.Key), expressions{
+		condition: input.ConditionExpression,
+		names:     aws.StringValueMap(input.ExpressionAttributeNames),
+		values:    sdkv1.FromItem(input.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	return &dynamodb.DeleteItemOutput{Attributes: toItemV1(returnValues(aws.StringValue(input.ReturnValues), w))}, nil
+}
+
+func (c *ClientV1) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return c.QueryWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	output, err := c.fake.query(aws.StringValue(input.TableName), readInput{
+		exprs: expressions{
+			keyCondition: input.KeyConditionExpression,
+			filter:       input.FilterExpression,
+			projection:   input.ProjectionExpression,
+			names:        aws.StringValueMap(input.ExpressionAttributeNames),
+			values:       sdkv1.FromItem(input.ExpressionAttributeValues),
+		},
+		indexName:         input.IndexName,
+		scanIndexForward:  input.ScanIndexForward == nil || *input.ScanIndexForward,
+		limit:             int(aws.Int64Value(input.Limit)),
+		exclusiveStartKey: fromKeyV1(input.ExclusiveStartKey),
+	})
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(output.items))
+	for _, item := range output.items {
+		items = append(items, toItemV1(item))
+	}
+
+	return &dynamodb.QueryOutput{
+		Items:            items,
+		Count:            aws.Int64(int64(len(items))),
+		ScannedCount:     aws.Int64(int64(output.scannedCount)),
+		LastEvaluatedKey: toItemV1(output.lastEvaluatedKey),
+	}, nil
+}
+
+func (c *ClientV1) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return c.ScanWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	output, err := c.fake.scan(aws.StringValue(input.TableName), readInput{
+		exprs: expressions{
+			filter:     input.FilterExpression,
+			projection: input.ProjectionExpression,
+			names:      aws.StringValueMap(input.ExpressionAttributeNames),
+			values:     sdkv1.FromItem(input.ExpressionAttributeValues),
+		},
+		indexName:         input.IndexName,
+		scanIndexForward:  true,
+		limit:             int(aws.Int64Value(input.Limit)),
+		exclusiveStartKey: fromKeyV1(input.ExclusiveStartKey),
+	})
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(output.items))
+	for _, item := range output.items {
+		items = append(items, toItemV1(item))
+	}
+
+	return &dynamodb.ScanOutput{
+		Items:            items,
+		Count:            aws.Int64(int64(len(items))),
+		ScannedCount:     aws.Int64(int64(output.scannedCount)),
+		LastEvaluatedKey: toItemV1(output.lastEvaluatedKey),
+	}, nil
+}
+
+func (c *ClientV1) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	return c.BatchGetItemWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	batchGets := make([]batchGet, 0, len(input.RequestItems))
+	for _, tableName := range sortedTableNames(input.RequestItems) {
+		keysAndAttributes := input.RequestItems[tableName]
+		keys := make([]map[string]ddbexpr.Value, 0, len(keysAndAttributes.Keys))
+		for _, key := range keysAndAttributes.Keys {
+			keys = append(keys, sdkv1.FromItem(key))
+		}
+
+		batchGets = append(batchGets, batchGet{
+			tableName: tableName,
+			keys:      keys,
+			exprs: expressions{
+				projection: keysAndAttributes.ProjectionExpression,
+				names:      aws.StringValueMap(keysAndAttributes.ExpressionAttributeNames),
+			},
+		})
+	}
+
+	responses, err := c.fake.batchGetItem(batchGets)
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	output := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]*dynamodb.AttributeValue{},
+		UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{},
+	}
+	for tableName, items := range responses {
+		output.Responses[tableName] = make([]map[string]*dynamodb.AttributeValue, 0, len(items))
+		for _, item := range items {
+			output.Responses[tableName] = append(output.Responses[tableName], toItemV1(item))
+		}
+	}
+
+	return output, nil
+}
+
+func (c *ClientV1) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	return c.BatchWriteItemWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	batchWrites := []batchWrite{}
+	for _, tableName := range sortedTableNames(input.RequestItems) {
+		for _, writeRequest := range input.RequestItems[tableName] {
+			switch {
+			case writeRequest.PutRequest != nil && writeRequest.DeleteRequest == nil:
+				batchWrites = append(batchWrites, batchWrite{
+					operation: "Put",
+					tableName: tableName,
+					item:      sdkv1.FromItem(writeRequest.PutRequest.Item),
+				})
+			case writeRequest.DeleteRequest != nil && writeRequest.PutRequest == nil:
+				batchWrites = append(batchWrites, batchWrite{
+					operation: "Delete",
+					tableName: tableName,
+					key:       sdkv1.FromItem(writeRequest.DeleteRequest.Key),
+				})
+			default:
+				batchWrites = append(batchWrites, batchWrite{tableName: tableName})
+			}
+		}
+	}
+
+	if err := c.fake.batchWriteItem(batchWrites); err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}, nil
+}
+
+func (c *ClientV1) TransactGetItems(input *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error) {
+	return c.TransactGetItemsWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, opts ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	transactGets := make([]transactGet, 0, len(input.TransactItems))
+	for _, transactItem := range input.TransactItems {
+		if transactItem.Get == nil {
+			return nil, toErrorV1(validationError("transaction item must have Get"))
+		}
+
+		transactGets = append(transactGets, transactGet{
+			tableName: aws.StringValue(transactItem.Get.TableName),
+			key:       sdkv1.FromItem(transactItem.Get.Key),
+			exprs: expressions{
+				projection: transactItem.Get.ProjectionExpression,
+				names:      aws.StringValueMap(transactItem.Get.ExpressionAttributeNames),
+			},
+		})
+	}
+
+	items, err := c.fake.transactGetItems(transactGets)
+	if err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	responses := make([]*dynamodb.ItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, &dynamodb.ItemResponse{Item: toItemV1(item)})
+	}
+
+	return &dynamodb.TransactGetItemsOutput{Responses: responses}, nil
+}
+
+func (c *ClientV1) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	return c.TransactWriteItemsWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	transactWrites := make([]transactWrite, 0, len(input.TransactItems))
+	for _, transactItem := range input.TransactItems {
+		switch {
+		case transactItem.ConditionCheck != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "ConditionCheck",
+				tableName: aws.StringValue(transactItem.ConditionCheck.TableName),
+				key:       sdkv1.FromItem(transactItem.ConditionCheck.Key),
+				exprs: expressions{
+					condition: transactItem.ConditionCheck.ConditionExpression,
+					names:     aws.StringValueMap(transactItem.ConditionCheck.ExpressionAttributeNames),
+					values:    sdkv1.FromItem(transactItem.ConditionCheck.ExpressionAttributeValues),
+				},
+			})
+		case transactItem.Put != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "Put",
+				tableName: aws.StringValue(transactItem.Put.TableName),
+				item:      sdkv1.FromItem(transactItem.Put.Item),
+				exprs: expressions{
+					condition: transactItem.Put.ConditionExpression,
+					names:     aws.StringValueMap(transactItem.Put.ExpressionAttributeNames),
+					values:    sdkv1.FromItem(transactItem.Put.ExpressionAttributeValues),
+				},
+			})
+		case transactItem.Update != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "Update",
+				tableName: aws.StringValue(transactItem.Update.TableName),
+				key:       sdkv1.FromItem(transactItem.Update.Key),
+				exprs: expressions{
+					condition: transactItem.Update.ConditionExpression,
+					update:    transactItem.Update.UpdateExpression,
+					names:     aws.StringValueMap(transactItem.Update.ExpressionAttributeNames),
+					values:    sdkv1.FromItem(transactItem.Update.ExpressionAttributeValues),
+				},
+			})
+		case transactItem.Delete != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "Delete",
+				tableName: aws.StringValue(transactItem.Delete.TableName),
+				key:       sdkv1.FromItem(transactItem.Delete.Key),
+				exprs: expressions{
+					condition: transactItem.Delete.ConditionExpression,
+					names:     aws.StringValueMap(transactItem.Delete.ExpressionAttributeNames),
+					values:    sdkv1.FromItem(transactItem.Delete.ExpressionAttributeValues),
+				},
+			})
+		default:
+			transactWrites = append(transactWrites, transactWrite{})
+		}
+	}
+
+	if err := c.fake.transactWriteItems(transactWrites); err != nil {
+		return nil, toErrorV1(err)
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (c *ClientV1) ExecuteStatement(input *dynamodb.ExecuteStatementInput) (*dynamodb.ExecuteStatementOutput, error) {
+	return c.ExecuteStatementWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) ExecuteStatementWithContext(ctx aws.Context, input *dynamodb.ExecuteStatementInput, opts ...request.Option) (*dynamodb.ExecuteStatementOutput, error) {
+	return nil, toErrorV1(unsupportedError("ExecuteStatement"))
+}
+
+func (c *ClientV1) BatchExecuteStatement(input *dynamodb.BatchExecuteStatementInput) (*dynamodb.BatchExecuteStatementOutput, error) {
+	return c.BatchExecuteStatementWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) BatchExecuteStatementWithContext(ctx aws.Context, input *dynamodb.BatchExecuteStatementInput, opts ...request.Option) (*dynamodb.BatchExecuteStatementOutput, error) {
+	return nil, toErrorV1(unsupportedError("BatchExecuteStatement"))
+}
+
+func (c *ClientV1) ExecuteTransaction(input *dynamodb.ExecuteTransactionInput) (*dynamodb.ExecuteTransactionOutput, error) {
+	return c.ExecuteTransactionWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *ClientV1) ExecuteTransactionWithContext(ctx aws.Context, input *dynamodb.ExecuteTransactionInput, opts ...request.Option) (*dynamodb.ExecuteTransactionOutput, error) {
+	return nil, toErrorV1(unsupportedError("ExecuteTransaction"))
+}
+
+// toItemV1 converts an item of the fake into an aws-sdk-go item, nil stays nil
+func toItemV1(item map[string]ddbexpr.Value) map[string]*dynamodb.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	return sdkv1.ToItem(item)
+}
+
+// fromKeyV1 converts an aws-sdk-go key into a key of the fake, nil stays nil
+func fromKeyV1(key map[string]*dynamodb.AttributeValue) map[string]ddbexpr.Value {
+	if key == nil {
+		return nil
+	}
+
+	return sdkv1.FromItem(key)
+}
+
+// toErrorV1 converts an error of the fake into the error returned by aws-sdk-go
+func toErrorV1(err error) error {
+	var fe *fakeError
+	if !errors.As(err, &fe) {
+		return err
+	}
+
+	switch fe.code {
+	case errorCodeConditionalCheckFailed:
+		return &dynamodb.ConditionalCheckFailedException{Message_: aws.String(fe.message)}
+	case errorCodeResourceNotFound:
+		return &dynamodb.ResourceNotFoundException{Message_: aws.String(fe.message)}
+	case errorCodeTransactionCanceled:
+		cancellationReasons := make([]*dynamodb.CancellationReason, 0, len(fe.cancellationReasons))
+		for _, reason := range fe.cancellationReasons {
+			cancellationReasons = append(cancellationReasons, &dynamodb.CancellationReason{Code: aws.String(reason)})
+		}
+		return &dynamodb.TransactionCanceledException{Message_: aws.String(fe.message), CancellationReasons: cancellationReasons}
+	default:
+		return awserr.New(fe.code, fe.message, nil)
+	}
+}
@@ -0,0 +1,281 @@
+package dynexprtest
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPersonV1(pk, sk, name string, age int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"pk":   {S: aws.String(pk)},
+		"sk":   {S: aws.String(sk)},
+		"name": {S: aws.String(name)},
+		"age":  {N: aws.String(strconv.Itoa(age))},
+		"bank_details": {M: map[string]*dynamodb.AttributeValue{
+			"accounts": {L: []*dynamodb.AttributeValue{{N: aws.String("1")}, {N: aws.String("2")}}},
+		}},
+	}
+}
+
+func keyV1(pk, sk string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"pk": {S: aws.String(pk)}, "sk": {S: aws.String(sk)}}
+}
+
+// Testing conditional writes and reads of single item
+func TestClientV1Item(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client := fake.ClientV1()
+
+	notExists, _ := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("pk"))).Build()
+	putItemInput := &dynamodb.PutItemInput{
+		TableName:                aws.String("persons"),
+		Item:                     newPersonV1("person#1", "profile", "John", 30),
+		ConditionExpression:      notExists.Condition(),
+		ExpressionAttributeNames: notExists.Names(),
+	}
+	_, err := client.PutItem(putItemInput)
+	assert.Nil(t, err)
+
+	// item already exists
+	_, err = client.PutItem(putItemInput)
+	var conditionalCheckFailed *dynamodb.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &conditionalCheckFailed))
+
+	projection, _ := expression.NewBuilder().WithProjection(expression.NamesList(expression.Name("name"), expression.Name("bank_details.accounts[1]"))).Build()
+	getItemOutput, err := client.GetItem(&dynamodb.GetItemInput{
+		TableName:                aws.String("persons"),
+		Key:                      keyV1("person#1", "profile"),
+		ProjectionExpression:     projection.Projection(),
+		ExpressionAttributeNames: projection.Names(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"name":         {S: aws.String("John")},
+		"bank_details": {M: map[string]*dynamodb.AttributeValue{"accounts": {L: []*dynamodb.AttributeValue{{N: aws.String("2")}}}}},
+	}, getItemOutput.Item)
+
+	update, _ := expression.NewBuilder().
+		WithCondition(expression.Name("age").LessThan(expression.Value(40))).
+		WithUpdate(expression.Set(expression.Name("age"), expression.Name("age").Plus(expression.Value(1))).Remove(expression.Name("bank_details"))).
+		Build()
+	updateItemInput := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("persons"),
+		Key:                       keyV1("person#1", "profile"),
+		ConditionExpression:       update.Condition(),
+		UpdateExpression:          update.Update(),
+		ExpressionAttributeNames:  update.Names(),
+		ExpressionAttributeValues: update.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueUpdatedNew),
+	}
+	updateItemOutput, err := client.UpdateItem(updateItemInput)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{"age": {N: aws.String("31")}}, updateItemOutput.Attributes)
+
+	// key attributes cannot be updated
+	updateKey, _ := expression.NewBuilder().WithUpdate(expression.Set(expression.Name("sk"), expression.Value("other"))).Build()
+	_, err = client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String("persons"),
+		Key:                       keyV1("person#1", "profile"),
+		UpdateExpression:          updateKey.Update(),
+		ExpressionAttributeNames:  updateKey.Names(),
+		ExpressionAttributeValues: updateKey.Values(),
+	})
+	assert.NotNil(t, err)
+
+	deleteItemOutput, err := client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:    aws.String("persons"),
+		Key:          keyV1("person#1", "profile"),
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"pk":   {S: aws.String("person#1")},
+		"sk":   {S: aws.String("profile")},
+		"name": {S: aws.String("John")},
+		"age":  {N: aws.String("31")},
+	}, deleteItemOutput.Attributes)
+
+	getItemOutput, err = client.GetItem(&dynamodb.GetItemInput{TableName: aws.String("persons"), Key: keyV1("person#1", "profile")})
+	assert.Nil(t, err)
+	assert.Nil(t, getItemOutput.Item)
+
+	_, err = client.GetItem(&dynamodb.GetItemInput{TableName: aws.String("unknown"), Key: keyV1("person#1", "profile")})
+	var resourceNotFound *dynamodb.ResourceNotFoundException
+	assert.True(t, errors.As(err, &resourceNotFound))
+}
+
+// Testing query and scan with key conditions, filters and pagination
+func TestClientV1QueryAndScan(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client := fake.ClientV1()
+	for _, item := range []map[string]*dynamodb.AttributeValue{
+		newPersonV1("person#1", "child#1", "Jane", 5),
+		newPersonV1("person#1", "child#2", "Jim", 8),
+		newPersonV1("person#1", "profile", "John", 30),
+		newPersonV1("person#2", "profile", "Doe", 40),
+	} {
+		_, err := client.PutItem(&dynamodb.PutItemInput{TableName: aws.String("persons"), Item: item})
+		assert.Nil(t, err)
+	}
+
+	query, _ := expression.NewBuilder().
+		WithKeyCondition(expression.Key("pk").Equal(expression.Value("person#1")).And(expression.Key("sk").BeginsWith("child#"))).
+		WithProjection(expression.NamesList(expression.Name("name"))).
+		Build()
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 aws.String("persons"),
+		KeyConditionExpression:    query.KeyCondition(),
+		ProjectionExpression:      query.Projection(),
+		ExpressionAttributeNames:  query.Names(),
+		ExpressionAttributeValues: query.Values(),
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int64(1),
+	}
+	queryOutput, err := client.Query(queryInput)
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]*dynamodb.AttributeValue{{"name": {S: aws.String("Jim")}}}, queryOutput.Items)
+	assert.Equal(t, keyV1("person#1", "child#2"), queryOutput.LastEvaluatedKey)
+
+	queryInput.ExclusiveStartKey = queryOutput.LastEvaluatedKey
+	queryOutput, err = client.Query(queryInput)
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]*dynamodb.AttributeValue{{"name": {S: aws.String("Jane")}}}, queryOutput.Items)
+	assert.Nil(t, queryOutput.LastEvaluatedKey)
+
+	// key condition must have an equality condition on partition key
+	invalidQuery, _ := expression.NewBuilder().WithKeyCondition(expression.Key("sk").Equal(expression.Value("profile"))).Build()
+	_, err = client.Query(&dynamodb.QueryInput{
+		TableName:                 aws.String("persons"),
+		KeyConditionExpression:    invalidQuery.KeyCondition(),
+		ExpressionAttributeNames:  invalidQuery.Names(),
+		ExpressionAttributeValues: invalidQuery.Values(),
+	})
+	assert.NotNil(t, err)
+
+	scan, _ := expression.NewBuilder().WithFilter(expression.Name("age").GreaterThanEqual(expression.Value(30))).Build()
+	scanOutput, err := client.Scan(&dynamodb.ScanInput{
+		TableName:                 aws.String("persons"),
+		FilterExpression:          scan.Filter(),
+		ExpressionAttributeNames:  scan.Names(),
+		ExpressionAttributeValues: scan.Values(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), *scanOutput.Count)
+	assert.Equal(t, int64(4), *scanOutput.ScannedCount)
+	assert.Equal(t, "John", *scanOutput.Items[0]["name"].S)
+	assert.Equal(t, "Doe", *scanOutput.Items[1]["name"].S)
+
+	// limit caps the items evaluated before the filter, not the items returned
+	scanOutput, err = client.Scan(&dynamodb.ScanInput{
+		TableName:                 aws.String("persons"),
+		FilterExpression:          scan.Filter(),
+		ExpressionAttributeNames:  scan.Names(),
+		ExpressionAttributeValues: scan.Values(),
+		Limit:                     aws.Int64(3),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), *scanOutput.Count)
+	assert.Equal(t, int64(3), *scanOutput.ScannedCount)
+	assert.Equal(t, "John", *scanOutput.Items[0]["name"].S)
+	assert.Equal(t, keyV1("person#1", "profile"), scanOutput.LastEvaluatedKey)
+}
+
+// Testing that a transaction is applied only when every condition holds
+func TestClientV1TransactWriteItems(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client := fake.ClientV1()
+	_, err := client.PutItem(&dynamodb.PutItemInput{TableName: aws.String("persons"), Item: newPersonV1("person#1", "profile", "John", 30)})
+	assert.Nil(t, err)
+
+	notExists, _ := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("pk"))).Build()
+	transactWriteItemsInput := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Put: &dynamodb.Put{TableName: aws.String("persons"), Item: newPersonV1("person#2", "profile", "Doe", 40)}},
+			{ConditionCheck: &dynamodb.ConditionCheck{
+				TableName:                aws.String("persons"),
+				Key:                      keyV1("person#1", "profile"),
+				ConditionExpression:      notExists.Condition(),
+				ExpressionAttributeNames: notExists.Names(),
+			}},
+		},
+	}
+	_, err = client.TransactWriteItems(transactWriteItemsInput)
+	var transactionCanceled *dynamodb.TransactionCanceledException
+	if assert.True(t, errors.As(err, &transactionCanceled)) {
+		assert.Equal(t, []*dynamodb.CancellationReason{{Code: aws.String("None")}, {Code: aws.String("ConditionalCheckFailed")}}, transactionCanceled.CancellationReasons)
+	}
+
+	// nothing is written when transaction is canceled
+	getItemOutput, err := client.GetItem(&dynamodb.GetItemInput{TableName: aws.String("persons"), Key: keyV1("person#2", "profile")})
+	assert.Nil(t, err)
+	assert.Nil(t, getItemOutput.Item)
+
+	transactWriteItemsInput.TransactItems[1] = &dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{TableName: aws.String("persons"), Key: keyV1("person#1", "profile")}}
+	_, err = client.TransactWriteItems(transactWriteItemsInput)
+	assert.Nil(t, err)
+
+	scanOutput, err := client.Scan(&dynamodb.ScanInput{TableName: aws.String("persons")})
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]*dynamodb.AttributeValue{newPersonV1("person#2", "profile", "Doe", 40)}, scanOutput.Items)
+}
+
+// Testing batch writes, batch gets and transactional gets
+func TestClientV1BatchAndTransactGetItems(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client := fake.ClientV1()
+	_, err := client.PutItem(&dynamodb.PutItemInput{TableName: aws.String("persons"), Item: newPersonV1("person#1", "profile", "John", 30)})
+	assert.Nil(t, err)
+
+	batchWriteItemInput := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]*dynamodb.WriteRequest{
+		"persons": {
+			{PutRequest: &dynamodb.PutRequest{Item: newPersonV1("person#2", "profile", "Doe", 40)}},
+			{DeleteRequest: &dynamodb.DeleteRequest{Key: keyV1("person#1", "profile")}},
+			{DeleteRequest: &dynamodb.DeleteRequest{Key: keyV1("person#2", "profile")}},
+		},
+	}}
+
+	// an item can be written only once per batch, nothing is written on error
+	_, err = client.BatchWriteItem(batchWriteItemInput)
+	assert.ErrorContains(t, err, "provided list of item keys contains duplicates")
+
+	batchWriteItemInput.RequestItems["persons"] = batchWriteItemInput.RequestItems["persons"][:2]
+	batchWriteItemOutput, err := client.BatchWriteItem(batchWriteItemInput)
+	assert.Nil(t, err)
+	assert.Empty(t, batchWriteItemOutput.UnprocessedItems)
+
+	projection, _ := expression.NewBuilder().WithProjection(expression.NamesList(expression.Name("name"))).Build()
+	batchGetItemOutput, err := client.BatchGetItem(&dynamodb.BatchGetItemInput{RequestItems: map[string]*dynamodb.KeysAndAttributes{
+		"persons": {
+			Keys:                     []map[string]*dynamodb.AttributeValue{keyV1("person#1", "profile"), keyV1("person#2", "profile")},
+			ProjectionExpression:     projection.Projection(),
+			ExpressionAttributeNames: projection.Names(),
+		},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]map[string]*dynamodb.AttributeValue{"persons": {{"name": {S: aws.String("Doe")}}}}, batchGetItemOutput.Responses)
+
+	transactGetItemsOutput, err := client.TransactGetItems(&dynamodb.TransactGetItemsInput{TransactItems: []*dynamodb.TransactGetItem{
+		{Get: &dynamodb.Get{TableName: aws.String("persons"), Key: keyV1("person#1", "profile")}},
+		{Get: &dynamodb.Get{TableName: aws.String("persons"), Key: keyV1("person#2", "profile"), ProjectionExpression: projection.Projection(), ExpressionAttributeNames: projection.Names()}},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []*dynamodb.ItemResponse{{}, {Item: map[string]*dynamodb.AttributeValue{"name": {S: aws.String("Doe")}}}}, transactGetItemsOutput.Responses)
+
+	// partiql isn't evaluated by the fake
+	_, err = client.ExecuteStatement(&dynamodb.ExecuteStatementInput{Statement: aws.String("SELECT * FROM persons")})
+	var awsErr awserr.Error
+	if assert.True(t, errors.As(err, &awsErr)) {
+		assert.Equal(t, "UnsupportedOperationException", awsErr.Code())
+	}
+}
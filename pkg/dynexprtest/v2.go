@@ -0,0 +1,353 @@
+package dynexprtest
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// ClientV2 is an aws-sdk-go-v2 dynamo db client backed by Fake, it has the methods GetItem,
+// PutItem, UpdateItem, DeleteItem, Query, Scan, BatchGetItem, BatchWriteItem, TransactGetItems,
+// TransactWriteItems, ExecuteStatement, BatchExecuteStatement and ExecuteTransaction of
+// dynamodb.Client so it satisfies any interface declaring a subset of them. The PartiQL
+// operations return an UnsupportedOperationException
+type ClientV2 struct {
+	fake *Fake
+}
+
+// ClientV2 returns an aws-sdk-go-v2 dynamo db client operating on `this` fake
+func (f *Fake) ClientV2() *ClientV2 {
+	return &ClientV2{fake: f}
+}
+
+func (c *ClientV2) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	item, err := c.fake.getItem(aws.ToString(params.TableName), sdkv2.FromItem(params.Key), expressions{
+		projection: params.ProjectionExpression,
+		names:      params.ExpressionAttributeNames,
+	})
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	return &dynamodb.GetItemOutput{Item: toItemV2(item)}, nil
+}
+
+func (c *ClientV2) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	w, err := c.fake.putItem(aws.ToString(params.TableName), sdkv2.FromItem(params.Item), expressions{
+		condition: params.ConditionExpression,
+		names:     params.ExpressionAttributeNames,
+		values:    sdkv2.FromItem(params.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	return &dynamodb.PutItemOutput{Attributes: toItemV2(returnValues(string(params.ReturnValues), w))}, nil
+}
+
+func (c *ClientV2) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	w, err := c.fake.updateItem(aws.ToString(params.TableName), sdkv2.FromItem(params.Key), expressions{
+		condition: params.ConditionExpression,
+		update:    params.UpdateExpression,
+		names:     params.ExpressionAttributeNames,
+		values:    sdkv2.FromItem(params.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	return &dynamodb.UpdateItemOutput{Attributes: toItemV2(returnValues(string(params.ReturnValues), w))}, nil
+}
+
+func (c *ClientV2) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	w, err := c.fake.deleteItem(aws.ToString(params.TableName), sdkv2.FromItem(params.Key), expressions{
+		condition: params.ConditionExpression,
+		names:     params.ExpressionAttributeNames,
+		values:    sdkv2.FromItem(params.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	return &dynamodb.DeleteItemOutput{Attributes: toItemV2(returnValues(string(params.ReturnValues), w))}, nil
+}
+
+func (c *ClientV2) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	output, err := c.fake.query(aws.ToString(params.TableName), readInput{
+		exprs: expressions{
+			keyCondition: params.KeyConditionExpression,
+			filter:       params.FilterExpression,
+			projection:   params.ProjectionExpression,
+			names:        params.ExpressionAttributeNames,
+			values:       sdkv2.FromItem(params.ExpressionAttributeValues),
+		},
+		indexName:         params.IndexName,
+		scanIndexForward:  params.ScanIndexForward == nil || *params.ScanIndexForward,
+		limit:             int(aws.ToInt32(params.Limit)),
+		exclusiveStartKey: fromKeyV2(params.ExclusiveStartKey),
+	})
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, len(output.items))
+	for _, item := range output.items {
+		items = append(items, toItemV2(item))
+	}
+
+	return &dynamodb.QueryOutput{
+		Items:            items,
+		Count:            int32(len(items)),
+		ScannedCount:     int32(output.scannedCount),
+		LastEvaluatedKey: toItemV2(output.lastEvaluatedKey),
+	}, nil
+}
+
+func (c *ClientV2) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	output, err := c.fake.scan(aws.ToString(params.TableName), readInput{
+		exprs: expressions{
+			filter:     params.FilterExpression,
+			projection: params.ProjectionExpression,
+			names:      params.ExpressionAttributeNames,
+			values:     sdkv2.FromItem(params.ExpressionAttributeValues),
+		},
+		indexName:         params.IndexName,
+		scanIndexForward:  true,
+		limit:             int(aws.ToInt32(params.Limit)),
+		exclusiveStartKey: fromKeyV2(params.ExclusiveStartKey),
+	})
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, len(output.items))
+	for _, item := range output.items {
+		items = append(items, toItemV2(item))
+	}
+
+	return &dynamodb.ScanOutput{
+		Items:            items,
+		Count:            int32(len(items)),
+		ScannedCount:     int32(output.scannedCount),
+		LastEvaluatedKey: toItemV2(output.lastEvaluatedKey),
+	}, nil
+}
+
+func (c *ClientV2) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	batchGets := make([]batchGet, 0, len(params.RequestItems))
+	for _, tableName := range sortedTableNames(params.RequestItems) {
+		keysAndAttributes := params.RequestItems[tableName]
+		keys := make([]map[string]ddbexpr.Value, 0, len(keysAndAttributes.Keys))
+		for _, key := range keysAndAttributes.Keys {
+			keys = append(keys, sdkv2.FromItem(key))
+		}
+
+		batchGets = append(batchGets, batchGet{
+			tableName: tableName,
+			keys:      keys,
+			exprs: expressions{
+				projection: keysAndAttributes.ProjectionExpression,
+				names:      keysAndAttributes.ExpressionAttributeNames,
+			},
+		})
+	}
+
+	responses, err := c.fake.batchGetItem(batchGets)
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	output := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]types.AttributeValue{},
+		UnprocessedKeys: map[string]types.KeysAndAttributes{},
+	}
+	for tableName, items := range responses {
+		output.Responses[tableName] = make([]map[string]types.AttributeValue, 0, len(items))
+		for _, item := range items {
+			output.Responses[tableName] = append(output.Responses[tableName], toItemV2(item))
+		}
+	}
+
+	return output, nil
+}
+
+func (c *ClientV2) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	batchWrites := []batchWrite{}
+	for _, tableName := range sortedTableNames(params.RequestItems) {
+		for _, writeRequest := range params.RequestItems[tableName] {
+			switch {
+			case writeRequest.PutRequest != nil && writeRequest.DeleteRequest == nil:
+				batchWrites = append(batchWrites, batchWrite{
+					operation: "Put",
+					tableName: tableName,
+					item:      sdkv2.FromItem(writeRequest.PutRequest.Item),
+				})
+			case writeRequest.DeleteRequest != nil && writeRequest.PutRequest == nil:
+				batchWrites = append(batchWrites, batchWrite{
+					operation: "Delete",
+					tableName: tableName,
+					key:       sdkv2.FromItem(writeRequest.DeleteRequest.Key),
+				})
+			default:
+				batchWrites = append(batchWrites, batchWrite{tableName: tableName})
+			}
+		}
+	}
+
+	if err := c.fake.batchWriteItem(batchWrites); err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{}}, nil
+}
+
+func (c *ClientV2) TransactGetItems(ctx context.Context, params *dynamodb.TransactGetItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactGetItemsOutput, error) {
+	transactGets := make([]transactGet, 0, len(params.TransactItems))
+	for _, transactItem := range params.TransactItems {
+		if transactItem.Get == nil {
+			return nil, toErrorV2(validationError("transaction item must have Get"))
+		}
+
+		transactGets = append(transactGets, transactGet{
+			tableName: aws.ToString(transactItem.Get.TableName),
+			key:       sdkv2.FromItem(transactItem.Get.Key),
+			exprs: expressions{
+				projection: transactItem.Get.ProjectionExpression,
+				names:      transactItem.Get.ExpressionAttributeNames,
+			},
+		})
+	}
+
+	items, err := c.fake.transactGetItems(transactGets)
+	if err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	responses := make([]types.ItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, types.ItemResponse{Item: toItemV2(item)})
+	}
+
+	return &dynamodb.TransactGetItemsOutput{Responses: responses}, nil
+}
+
+func (c *ClientV2) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	transactWrites := make([]transactWrite, 0, len(params.TransactItems))
+	for _, transactItem := range params.TransactItems {
+		switch {
+		case transactItem.ConditionCheck != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "ConditionCheck",
+				tableName: aws.ToString(transactItem.ConditionCheck.TableName),
+				key:       sdkv2.FromItem(transactItem.ConditionCheck.Key),
+				exprs: expressions{
+					condition: transactItem.ConditionCheck.ConditionExpression,
+					names:     transactItem.ConditionCheck.ExpressionAttributeNames,
+					values:    sdkv2.FromItem(transactItem.ConditionCheck.ExpressionAttributeValues),
+				},
+			})
+		case transactItem.Put != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "Put",
+				tableName: aws.ToString(transactItem.Put.TableName),
+				item:      sdkv2.FromItem(transactItem.Put.Item),
+				exprs: expressions{
+					condition: transactItem.Put.ConditionExpression,
+					names:     transactItem.Put.ExpressionAttributeNames,
+					values:    sdkv2.FromItem(transactItem.Put.ExpressionAttributeValues),
+				},
+			})
+		case transactItem.Update != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "Update",
+				tableName: aws.ToString(transactItem.Update.TableName),
+				key:       sdkv2.FromItem(transactItem.Update.Key),
+				exprs: expressions{
+					condition: transactItem.Update.ConditionExpression,
+					update:    transactItem.Update.UpdateExpression,
+					names:     transactItem.Update.ExpressionAttributeNames,
+					values:    sdkv2.FromItem(transactItem.Update.ExpressionAttributeValues),
+				},
+			})
+		case transactItem.Delete != nil:
+			transactWrites = append(transactWrites, transactWrite{
+				operation: "Delete",
+				tableName: aws.ToString(transactItem.Delete.TableName),
+				key:       sdkv2.FromItem(transactItem.Delete.Key),
+				exprs: expressions{
+					condition: transactItem.Delete.ConditionExpression,
+					names:     transactItem.Delete.ExpressionAttributeNames,
+					values:    sdkv2.FromItem(transactItem.Delete.ExpressionAttributeValues),
+				},
+			})
+		default:
+			transactWrites = append(transactWrites, transactWrite{})
+		}
+	}
+
+	if err := c.fake.transactWriteItems(transactWrites); err != nil {
+		return nil, toErrorV2(err)
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (c *ClientV2) ExecuteStatement(ctx context.Context, params *dynamodb.ExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
+	return nil, toErrorV2(unsupportedError("ExecuteStatement"))
+}
+
+func (c *ClientV2) BatchExecuteStatement(ctx context.Context, params *dynamodb.BatchExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
+	return nil, toErrorV2(unsupportedError("BatchExecuteStatement"))
+}
+
+func (c *ClientV2) ExecuteTransaction(ctx context.Context, params *dynamodb.ExecuteTransactionInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+	return nil, toErrorV2(unsupportedError("ExecuteTransaction"))
+}
+
+// toItemV2 converts an item of the fake into an aws-sdk-go-v2 item, nil stays nil
+func toItemV2(item map[string]ddbexpr.Value) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	return sdkv2.ToItem(item)
+}
+
+// fromKeyV2 converts an aws-sdk-go-v2 key into a key of the fake, nil stays nil
+func fromKeyV2(key map[string]types.AttributeValue) map[string]ddbexpr.Value {
+	if key == nil {
+		return nil
+	}
+
+	return sdkv2.FromItem(key)
+}
+
+// toErrorV2 converts an error of the fake into the error returned by aws-sdk-go-v2
+func toErrorV2(err error) error {
+	var fe *fakeError
+	if !errors.As(err, &fe) {
+		return err
+	}
+
+	switch fe.code {
+	case errorCodeConditionalCheckFailed:
+		return &types.ConditionalCheckFailedException{Message: aws.String(fe.message)}
+	case errorCodeResourceNotFound:
+		return &types.ResourceNotFoundException{Message: aws.String(fe.message)}
+	case errorCodeTransactionCanceled:
+		cancellationReasons := make([]types.CancellationReason, 0, len(fe.cancellationReasons))
+		for _, reason := range fe.cancellationReasons {
+			cancellationReasons = append(cancellationReasons, types.CancellationReason{Code: aws.String(reason)})
+		}
+		return &types.TransactionCanceledException{Message: aws.String(fe.message), CancellationReasons: cancellationReasons}
+	default:
+		return &smithy.GenericAPIError{Code: fe.code, Message: fe.message}
+	}
+}
@@ -0,0 +1,241 @@
+package dynexprtest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPersonV2(pk, sk, name string, age int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk":   &types.AttributeValueMemberS{Value: pk},
+		"sk":   &types.AttributeValueMemberS{Value: sk},
+		"name": &types.AttributeValueMemberS{Value: name},
+		"age":  &types.AttributeValueMemberN{Value: strconv.Itoa(age)},
+		"bank_details": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"accounts": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberN{Value: "1"}, &types.AttributeValueMemberN{Value: "2"}}},
+		}},
+	}
+}
+
+func keyV2(pk, sk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: pk}, "sk": &types.AttributeValueMemberS{Value: sk}}
+}
+
+// Testing conditional writes and reads of single item
+func TestClientV2Item(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client, ctx := fake.ClientV2(), context.Background()
+
+	notExists, _ := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("pk"))).Build()
+	putItemInput := &dynamodb.PutItemInput{
+		TableName:                aws.String("persons"),
+		Item:                     newPersonV2("person#1", "profile", "John", 30),
+		ConditionExpression:      notExists.Condition(),
+		ExpressionAttributeNames: notExists.Names(),
+	}
+	_, err := client.PutItem(ctx, putItemInput)
+	assert.Nil(t, err)
+
+	// item already exists
+	_, err = client.PutItem(ctx, putItemInput)
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &conditionalCheckFailed))
+
+	projection, _ := expression.NewBuilder().WithProjection(expression.NamesList(expression.Name("name"), expression.Name("bank_details.accounts[1]"))).Build()
+	getItemOutput, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:                aws.String("persons"),
+		Key:                      keyV2("person#1", "profile"),
+		ProjectionExpression:     projection.Projection(),
+		ExpressionAttributeNames: projection.Names(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"name": &types.AttributeValueMemberS{Value: "John"},
+		"bank_details": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"accounts": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberN{Value: "2"}}},
+		}},
+	}, getItemOutput.Item)
+
+	update, _ := expression.NewBuilder().
+		WithCondition(expression.Name("age").LessThan(expression.Value(40))).
+		WithUpdate(expression.Set(expression.Name("age"), expression.Name("age").Plus(expression.Value(1))).Remove(expression.Name("bank_details"))).
+		Build()
+	updateItemOutput, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("persons"),
+		Key:                       keyV2("person#1", "profile"),
+		ConditionExpression:       update.Condition(),
+		UpdateExpression:          update.Update(),
+		ExpressionAttributeNames:  update.Names(),
+		ExpressionAttributeValues: update.Values(),
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{"age": &types.AttributeValueMemberN{Value: "31"}}, updateItemOutput.Attributes)
+
+	// condition no longer holds
+	update, _ = expression.NewBuilder().
+		WithCondition(expression.Name("age").Equal(expression.Value(30))).
+		WithUpdate(expression.Set(expression.Name("name"), expression.Value("Doe"))).
+		Build()
+	_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("persons"),
+		Key:                       keyV2("person#1", "profile"),
+		ConditionExpression:       update.Condition(),
+		UpdateExpression:          update.Update(),
+		ExpressionAttributeNames:  update.Names(),
+		ExpressionAttributeValues: update.Values(),
+	})
+	assert.True(t, errors.As(err, &conditionalCheckFailed))
+
+	// query without key condition is invalid
+	_, err = client.Query(ctx, &dynamodb.QueryInput{TableName: aws.String("persons")})
+	var apiError smithy.APIError
+	if assert.True(t, errors.As(err, &apiError)) {
+		assert.Equal(t, "ValidationException", apiError.ErrorCode())
+	}
+
+	deleteItemOutput, err := client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String("persons"),
+		Key:          keyV2("person#1", "profile"),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"pk":   &types.AttributeValueMemberS{Value: "person#1"},
+		"sk":   &types.AttributeValueMemberS{Value: "profile"},
+		"name": &types.AttributeValueMemberS{Value: "John"},
+		"age":  &types.AttributeValueMemberN{Value: "31"},
+	}, deleteItemOutput.Attributes)
+}
+
+// Testing query, scan and transactions
+func TestClientV2QueryScanAndTransactWriteItems(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client, ctx := fake.ClientV2(), context.Background()
+
+	notExists, _ := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("pk"))).Build()
+	transactWriteItemsInput := &dynamodb.TransactWriteItemsInput{}
+	for _, item := range []map[string]types.AttributeValue{
+		newPersonV2("person#1", "child#1", "Jane", 5),
+		newPersonV2("person#1", "child#2", "Jim", 8),
+		newPersonV2("person#1", "profile", "John", 30),
+	} {
+		transactWriteItemsInput.TransactItems = append(transactWriteItemsInput.TransactItems, types.TransactWriteItem{Put: &types.Put{
+			TableName:                aws.String("persons"),
+			Item:                     item,
+			ConditionExpression:      notExists.Condition(),
+			ExpressionAttributeNames: notExists.Names(),
+		}})
+	}
+	_, err := client.TransactWriteItems(ctx, transactWriteItemsInput)
+	assert.Nil(t, err)
+
+	// items already exist
+	_, err = client.TransactWriteItems(ctx, transactWriteItemsInput)
+	var transactionCanceled *types.TransactionCanceledException
+	if assert.True(t, errors.As(err, &transactionCanceled)) {
+		assert.Equal(t, 3, len(transactionCanceled.CancellationReasons))
+		assert.Equal(t, "ConditionalCheckFailed", aws.ToString(transactionCanceled.CancellationReasons[0].Code))
+	}
+
+	query, _ := expression.NewBuilder().
+		WithKeyCondition(expression.Key("pk").Equal(expression.Value("person#1")).And(expression.Key("sk").LessThan(expression.Value("profile")))).
+		WithFilter(expression.Name("age").GreaterThan(expression.Value(6))).
+		WithProjection(expression.NamesList(expression.Name("sk"))).
+		Build()
+	queryOutput, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String("persons"),
+		KeyConditionExpression:    query.KeyCondition(),
+		FilterExpression:          query.Filter(),
+		ProjectionExpression:      query.Projection(),
+		ExpressionAttributeNames:  query.Names(),
+		ExpressionAttributeValues: query.Values(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]types.AttributeValue{{"sk": &types.AttributeValueMemberS{Value: "child#2"}}}, queryOutput.Items)
+	assert.Equal(t, int32(1), queryOutput.Count)
+	assert.Equal(t, int32(2), queryOutput.ScannedCount)
+
+	// limit caps the items evaluated before the filter, not the items returned
+	queryOutput, err = client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String("persons"),
+		KeyConditionExpression:    query.KeyCondition(),
+		FilterExpression:          query.Filter(),
+		ProjectionExpression:      query.Projection(),
+		ExpressionAttributeNames:  query.Names(),
+		ExpressionAttributeValues: query.Values(),
+		Limit:                     aws.Int32(1),
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, queryOutput.Items)
+	assert.Equal(t, int32(0), queryOutput.Count)
+	assert.Equal(t, int32(1), queryOutput.ScannedCount)
+	assert.Equal(t, keyV2("person#1", "child#1"), queryOutput.LastEvaluatedKey)
+
+	scanOutput, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("persons"), Limit: aws.Int32(2)})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scanOutput.Items))
+	assert.Equal(t, keyV2("person#1", "child#2"), scanOutput.LastEvaluatedKey)
+}
+
+// Testing batch writes, batch gets and transactional gets
+func TestClientV2BatchAndTransactGetItems(t *testing.T) {
+	fake := New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client, ctx := fake.ClientV2(), context.Background()
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("persons"), Item: newPersonV2("person#1", "profile", "John", 30)})
+	assert.Nil(t, err)
+
+	batchWriteItemInput := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"persons": {
+			{PutRequest: &types.PutRequest{Item: newPersonV2("person#2", "profile", "Doe", 40)}},
+			{DeleteRequest: &types.DeleteRequest{Key: keyV2("person#1", "profile")}},
+			{DeleteRequest: &types.DeleteRequest{Key: keyV2("person#2", "profile")}},
+		},
+	}}
+
+	// an item can be written only once per batch, nothing is written on error
+	_, err = client.BatchWriteItem(ctx, batchWriteItemInput)
+	assert.ErrorContains(t, err, "provided list of item keys contains duplicates")
+
+	batchWriteItemInput.RequestItems["persons"] = batchWriteItemInput.RequestItems["persons"][:2]
+	batchWriteItemOutput, err := client.BatchWriteItem(ctx, batchWriteItemInput)
+	assert.Nil(t, err)
+	assert.Empty(t, batchWriteItemOutput.UnprocessedItems)
+
+	projection, _ := expression.NewBuilder().WithProjection(expression.NamesList(expression.Name("name"))).Build()
+	batchGetItemOutput, err := client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		"persons": {
+			Keys:                     []map[string]types.AttributeValue{keyV2("person#1", "profile"), keyV2("person#2", "profile")},
+			ProjectionExpression:     projection.Projection(),
+			ExpressionAttributeNames: projection.Names(),
+		},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]map[string]types.AttributeValue{"persons": {{"name": &types.AttributeValueMemberS{Value: "Doe"}}}}, batchGetItemOutput.Responses)
+
+	transactGetItemsOutput, err := client.TransactGetItems(ctx, &dynamodb.TransactGetItemsInput{TransactItems: []types.TransactGetItem{
+		{Get: &types.Get{TableName: aws.String("persons"), Key: keyV2("person#1", "profile")}},
+		{Get: &types.Get{TableName: aws.String("persons"), Key: keyV2("person#2", "profile"), ProjectionExpression: projection.Projection(), ExpressionAttributeNames: projection.Names()}},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.ItemResponse{{}, {Item: map[string]types.AttributeValue{"name": &types.AttributeValueMemberS{Value: "Doe"}}}}, transactGetItemsOutput.Responses)
+
+	// partiql isn't evaluated by the fake
+	_, err = client.ExecuteStatement(ctx, &dynamodb.ExecuteStatementInput{Statement: aws.String("SELECT * FROM persons")})
+	var apiErr smithy.APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, "UnsupportedOperationException", apiErr.ErrorCode())
+	}
+}
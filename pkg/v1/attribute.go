@@ -1,9 +1,8 @@
 package v1
 
 import (
-	"errors"
-	"strings"
-
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv1"
 	"github.com/gauxs/dynexpr/internal/utils"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -11,51 +10,23 @@ import (
 
 // Represents a dyanmo db attribute
 type DynamoAttribute[T any] struct {
-	// True when build has been executed on 'this' attribute
-	// projection, Condition and Update can only be done after
-	// build is executed
-	buildExecuted bool
-
-	// Mark 'this' attribute for projection
-	projection bool
-
-	// Name of the dynamo attribute as defined in DB
-	name string
-
-	// Document path of this attribute
-	documentPath string
-
-	// Use this to build any condition on 'this' attribute
-	nameBuilder expression.NameBuilder
-
-	// Represents all the conditions applied on 'this' dynamo attribute
-	conditionBuilder *expression.ConditionBuilder
+	// Node of 'this' attribute in the expression builder tree
+	// holding its name, document path and marks
+	node *core.Node
 
 	// Helps in direct member selection of child attributes
 	accessReference T
-
-	// Determines the operation which needs to performed on
-	// 'this' attribute
-	operation DynamoOperation
-
-	// Represent the value which needs to be assigned to 'this'
-	// attribute for update
-	value any
-
-	// Child attributes of 'this' attribute, can be DynamoAttribute
-	// or DynamoListAttribute
-	childAttributes []interface{}
 }
 
 func NewDynamoAttribute[T any]() *DynamoAttribute[T] {
-	return &DynamoAttribute[T]{
-		childAttributes: []interface{}{},
-	}
+	da := &DynamoAttribute[T]{node: core.NewNode(core.NODE_ATTRIBUTE)}
+	da.node.SetOwner(da)
+	return da
 }
 
 // WithName builds `this` DynamoAttribute with a dynamo db attribute name
 func (da *DynamoAttribute[T]) WithName(name string) *DynamoAttribute[T] {
-	da.name = name
+	da.node.SetName(name)
 	return da
 }
 
@@ -69,26 +40,29 @@ func (da *DynamoAttribute[T]) WithAccessReference(accessReference T) *DynamoAttr
 // WithChildAttribute builds `this` DynamoAttribute child attribute which are
 // child nodes holding member attribute of type `T`
 func (da *DynamoAttribute[T]) WithChildAttribute(childAttribute interface{}) *DynamoAttribute[T] {
-	da.childAttributes = append(da.childAttributes, childAttribute)
+	switch childAttributeType := childAttribute.(type) {
+	case attributeNode:
+		// the child attribute may be a copy of the attribute created with the node
+		childAttributeType.coreNode().SetOwner(childAttribute)
+		da.node.AddChild(childAttributeType.coreNode())
+	}
+
 	return da
 }
 
 // Project marks `this` attribute for projection
 func (da *DynamoAttribute[T]) Project() error {
-	if !da.buildExecuted {
-		return errors.New("build is not yet executed on attribute [" + da.name + "], cannot mark this attribute for projection")
-	}
-
-	da.projection = true
-	return nil
+	defer da.node.LockTree()()
+	return da.node.Project()
 }
 
 func (da *DynamoAttribute[T]) GetName() string {
-	return da.name
+	return da.node.Name()
 }
 
 func (da *DynamoAttribute[T]) GetNameBuilder() expression.NameBuilder {
-	return da.nameBuilder
+	defer da.node.RLockTree()()
+	return nameBuilder(da.node)
 }
 
 // AR returns the type `T` held by this node
@@ -102,142 +76,214 @@ func (da *DynamoAttribute[T]) AR() T {
 // might give build error
 func (da *DynamoAttribute[T]) AndWithCondition() func(conditionBuilder expression.ConditionBuilder) {
 	return func(conditionBuilder expression.ConditionBuilder) {
-		if da.conditionBuilder != nil {
-			da.conditionBuilder = utils.PointerTo((*da.conditionBuilder).And(conditionBuilder))
-		} else {
-			da.conditionBuilder = &conditionBuilder
-		}
+		defer da.node.LockTree()()
+		da.node.AndWithCondition(conditionBuilder)
+	}
+}
+
+// AndWithFilter adds a new filter to `this` attributes existing filters using `AND`, filters
+// are built separately from conditions by BuildFilterBuilder and used by query and scan
+func (da *DynamoAttribute[T]) AndWithFilter() func(filterBuilder expression.ConditionBuilder) {
+	return func(filterBuilder expression.ConditionBuilder) {
+		defer da.node.LockTree()()
+		da.node.AndWithFilter(filterBuilder)
 	}
 }
 
 // AddValue adds a value which will be used to update `this` attributes
 func (da *DynamoAttribute[T]) AddValue(operation DynamoOperation, value any) {
-	da.operation = operation
+	defer da.node.LockTree()()
+	da.node.AddValue(core.Operation(operation), value)
+}
 
-	// TODO: time.Time will automatically convert to string
-	// add a switch case hee and allow clients to specify
-	// which data type they want time to be in
-	da.value = value
+// Increment marks `this` attribute for UPDATE_SET with its current value plus `n`, combined
+// with SetIfNotExists in either order the default value is used when `this` attribute doesn't
+// exist, i.e. `SET a = if_not_exists(a, :d) + :n`
+//
+// `T` must be a number or a pointer to one, ErrNotNumber is returned otherwise and `this`
+// attribute is left as is
+func (da *DynamoAttribute[T]) Increment(n T) error {
+	defer da.node.LockTree()()
+	return da.node.AddArithmetic(core.SetOperation{Kind: core.SET_PLUS, Value: n})
 }
 
-func (da *DynamoAttribute[T]) constructDocumentPath(documentPathOfParent string) string {
-	if strings.HasSuffix(da.GetName(), "]") { // this is a top level element of a list, don't use DDBAtributeNameCancatenator
-		return documentPathOfParent + da.GetName()
-	}
+// Decrement marks `this` attribute for UPDATE_SET with its current value minus `n`, see Increment
+func (da *DynamoAttribute[T]) Decrement(n T) error {
+	defer da.node.LockTree()()
+	return da.node.AddArithmetic(core.SetOperation{Kind: core.SET_MINUS, Value: n})
+}
 
-	if documentPathOfParent == "" { // this is top level attribute
-		return da.GetName()
-	}
+// SetIfNotExists marks `this` attribute for UPDATE_SET with `value` only if `this` attribute
+// doesn't exist, see Increment
+func (da *DynamoAttribute[T]) SetIfNotExists(value T) {
+	defer da.node.LockTree()()
+	da.node.AddSetOperation(core.SetOperation{Kind: core.SET_IF_NOT_EXISTS, Value: value})
+}
 
-	return documentPathOfParent + DDBAtributeNameCancatenator + da.GetName()
+// SetFrom marks `this` attribute for UPDATE_SET with the value of the attribute `from`
+func (da *DynamoAttribute[T]) SetFrom(from Node) {
+	documentPath := from.DocumentPath()
+	defer da.node.LockTree()()
+	da.node.AddSetOperation(core.SetOperation{Kind: core.SET_FROM, From: documentPath})
 }
 
-func (da *DynamoAttribute[T]) build(parentDocumentPath string) error {
-	if da.buildExecuted {
-		return errors.New("build is already executed on attribute " + da.documentPath)
-	}
+// SetExpr marks `this` attribute for UPDATE_SET with `operandBuilder`, e.g. a SetValueBuilder
+// which isn't covered by Increment, Decrement, SetIfNotExists and SetFrom
+func (da *DynamoAttribute[T]) SetExpr(operandBuilder expression.OperandBuilder) {
+	defer da.node.LockTree()()
+	da.node.AddValue(core.UPDATE_SET, operandBuilder)
+}
+
+func (da *DynamoAttribute[T]) coreNode() *core.Node {
+	return da.node
+}
 
-	// build document path
-	da.documentPath = da.constructDocumentPath(parentDocumentPath)
+// addName recursively collects all the attributes which were marked for projection
+func (da *DynamoAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	return addNames(da.node, projectionBuilder)
+}
 
-	// build name builder
-	da.nameBuilder = expression.Name(da.documentPath)
+func (da *DynamoAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return addConditions(da.node, conditionBuilder)
+}
 
-	for _, childAttribute := range da.childAttributes {
-		switch childAttributeType := childAttribute.(type) {
-		case Builder:
-			childAttributeType.build(da.documentPath)
-		}
-	}
+func (da *DynamoAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (*expression.UpdateBuilder, error) {
+	return addUpdates(da.node, updateBuilder)
+}
 
-	da.buildExecuted = true
-	return nil
+// nameBuilder returns the name builder of the document path of `node`
+func nameBuilder(node *core.Node) expression.NameBuilder {
+	return expression.Name(node.DocumentPath())
 }
 
-// addName recursively collects all the attributes which were marked for projection
-func (da *DynamoAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (newProjectionBuilder *expression.ProjectionBuilder, err error) {
-	if !da.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + da.name + "], cannot mark this attribute for projection")
+// addNames adds the document paths of the nodes below `node` marked for projection into the
+// projection builder and returns a new projection builder
+func addNames(node *core.Node, projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	newProjectionBuilder := projectionBuilder
+	for _, path := range node.ProjectionPaths() {
+		newProjectionBuilder = utils.PointerTo(newProjectionBuilder.AddNames(expression.Name(path)))
 	}
 
-	if projectionBuilder == nil {
-		return nil, errors.New("nil projection builder passed for attribute " + da.documentPath)
-	}
+	return newProjectionBuilder, nil
+}
 
-	newProjectionBuilder = projectionBuilder
-	if da.projection { // skipping projection of child attributes
-		return utils.PointerTo(newProjectionBuilder.AddNames(expression.Name(da.documentPath))), nil
-	} else {
-		for _, childAttribute := range da.childAttributes {
-			switch childAttributeType := childAttribute.(type) {
-			case Projector:
-				newProjectionBuilder, err = childAttributeType.addName(newProjectionBuilder)
-			}
+// addConditions adds the conditions of the nodes below `node` into the condition builder using
+// `AND` and returns a new condition builder, conditions of a single node are joined first
+func addConditions(node *core.Node, conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return joinConditions(node.Conditions(), conditionBuilder)
+}
 
-			if err != nil {
-				return nil, err
+// addFilters adds the filters of the nodes below `node` into the condition builder using `AND`
+// and returns a new condition builder, filters of a single node are joined first
+func addFilters(node *core.Node, filterBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return joinConditions(node.Filters(), filterBuilder)
+}
+
+// joinConditions joins the conditions of every node into the condition builder using `AND`
+func joinConditions(nodeConditions [][]any, conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	newConditionBuilder := conditionBuilder
+	for _, conditions := range nodeConditions {
+		var nodeConditionBuilder *expression.ConditionBuilder
+		for _, condition := range conditions {
+			if nodeConditionBuilder != nil {
+				nodeConditionBuilder = utils.PointerTo(nodeConditionBuilder.And(renderCondition(condition)))
+			} else {
+				nodeConditionBuilder = utils.PointerTo(renderCondition(condition))
 			}
 		}
+
+		if newConditionBuilder != nil {
+			newConditionBuilder = utils.PointerTo(newConditionBuilder.And(*nodeConditionBuilder))
+		} else {
+			newConditionBuilder = nodeConditionBuilder
+		}
 	}
 
-	return newProjectionBuilder, nil
+	return newConditionBuilder
 }
 
-func (da *DynamoAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) (newConditionBuilder *expression.ConditionBuilder) {
-	if da.conditionBuilder != nil {
-		if conditionBuilder != nil {
-			newConditionBuilder = utils.PointerTo(conditionBuilder.And(*da.conditionBuilder))
-		} else {
-			newConditionBuilder = da.conditionBuilder
+// renderCondition converts a condition of the tree into a condition builder
+func renderCondition(condition any) expression.ConditionBuilder {
+	switch conditionType := condition.(type) {
+	case core.OldValueCondition:
+		if conditionType.Old == nil {
+			return expression.Name(conditionType.Path).AttributeNotExists()
 		}
-	} else {
-		newConditionBuilder = conditionBuilder
+
+		return expression.Name(conditionType.Path).Equal(expression.Value(renderValue(core.RawValue{Value: *conditionType.Old})))
+	case core.ParsedCondition:
+		return renderParsedCondition(conditionType)
+	default:
+		return condition.(expression.ConditionBuilder)
+	}
+}
+
+// renderValue converts a value of the tree into a value for the marshaller
+func renderValue(value any) any {
+	switch valueType := value.(type) {
+	case core.RawValue:
+		return rawAttributeValue{sdkv1.ToAttributeValue(valueType.Value)}
+	default:
+		return value
 	}
+}
 
-	for _, childAttribute := range da.childAttributes {
-		switch childAttributeType := childAttribute.(type) {
-		case Conditioner:
-			// when both passed and returned condition builder are nil
-			if retConditionBuilder := childAttributeType.addCondition(newConditionBuilder); retConditionBuilder != nil {
-				newConditionBuilder = retConditionBuilder
-			}
-		}
+// renderUpdateValue converts a value marked for update on the attribute at `path` into a value
+// for the marshaller or an operand builder
+func renderUpdateValue(path string, value any) any {
+	if operand, ok := core.UpdateOperand(path, value); ok {
+		return renderOperand(operand)
 	}
 
-	return newConditionBuilder
+	return renderValue(value)
 }
 
-func (da *DynamoAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (newUpdateBuilder *expression.UpdateBuilder, err error) {
-	if !da.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + da.name + "], cannot update this attribute")
+// renderOperand converts an Operand into an operand builder
+func renderOperand(operand core.Operand) expression.OperandBuilder {
+	arguments := make([]expression.OperandBuilder, 0, len(operand.Arguments))
+	for _, argument := range operand.Arguments {
+		arguments = append(arguments, renderOperand(argument))
 	}
 
-	if updateBuilder == nil {
-		return nil, errors.New("nil update builder passed for attribute " + da.documentPath)
+	switch operand.Kind {
+	case core.OPERAND_NAME:
+		return expression.Name(operand.Path)
+	case core.OPERAND_VALUE:
+		return expression.Value(renderValue(operand.Value))
+	case core.OPERAND_SIZE:
+		return expression.Name(operand.Path).Size()
+	case core.OPERAND_IF_NOT_EXISTS:
+		return expression.IfNotExists(expression.Name(operand.Arguments[0].Path), arguments[1])
+	case core.OPERAND_PLUS:
+		return expression.Plus(arguments[0], arguments[1])
+	case core.OPERAND_MINUS:
+		return expression.Minus(arguments[0], arguments[1])
+	default:
+		return expression.ListAppend(arguments[0], arguments[1])
 	}
+}
 
-	newUpdateBuilder = updateBuilder
-	valueBuilder := expression.Value(da.value)
-
-	switch da.operation {
-	case UPDATE_SET:
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Set(da.nameBuilder, valueBuilder))
-	case UPDATE_REMOVE:
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Remove(da.nameBuilder))
-	case UPDATE_ADD: // for numbers and set data structure
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Add(da.nameBuilder, valueBuilder))
-	case UPDATE_DELETE: // for set data structure only
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Delete(da.nameBuilder, valueBuilder))
-	case NO_OP:
-		for _, childAttribute := range da.childAttributes {
-			switch childAttributeType := childAttribute.(type) {
-			case Updater:
-				if retUpdateBuilder, err := childAttributeType.addUpdate(newUpdateBuilder); err != nil {
-					return nil, err
-				} else if retUpdateBuilder != nil {
-					newUpdateBuilder = retUpdateBuilder
-				}
+// addUpdates adds the update operations of the nodes below `node` into the update builder and
+// returns a new update builder
+func addUpdates(node *core.Node, updateBuilder *expression.UpdateBuilder) (*expression.UpdateBuilder, error) {
+	newUpdateBuilder := updateBuilder
+	for _, update := range node.Updates() {
+		nameBuilder, value := expression.Name(update.Path), renderUpdateValue(update.Path, update.Value)
+		valueBuilder := expression.Value(value)
+		switch update.Operation {
+		case core.UPDATE_SET:
+			switch valueType := value.(type) {
+			case expression.OperandBuilder: // this is when we want to perform if_not_exists, list_append or arithmetic
+				newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Set(nameBuilder, valueType))
+			default:
+				newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Set(nameBuilder, valueBuilder))
 			}
+		case core.UPDATE_REMOVE:
+			newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Remove(nameBuilder))
+		case core.UPDATE_ADD: // for numbers and set data structure
+			newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Add(nameBuilder, valueBuilder))
+		case core.UPDATE_DELETE: // for set data structure only
+			newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Delete(nameBuilder, valueBuilder))
 		}
 	}
 
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv1"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+const (
+	// Maximum number of keys allowed by dynamo db in a single BatchGetItem request
+	MaxBatchGetItemKeys = core.MaxBatchGetItemKeys
+
+	// Maximum number of put and delete requests allowed by dynamo db in a single
+	// BatchWriteItem request
+	MaxBatchWriteItems = core.MaxBatchWriteItems
+)
+
+// BuildBatchGetItemInputs builds BatchGetItemInput(s) which fetch the items of `keys` from the table,
+// keys are split into chunks of MaxBatchGetItemKeys and every chunk gets its own BatchGetItemInput.
+// Duplicate keys are fetched only once since dynamo db rejects a request having duplicate keys.
+//
+// Key can either be a struct or a map[string]*dynamodb.AttributeValue, attributes other than the key
+// attributes are ignored. Projection of the expression builder is used in every chunk, key attributes
+// are always projected so only the key attributes are fetched when nothing is marked for projection
+func BuildBatchGetItemInputs[K any](tableName string, keys []K, itemExpressionBuilder ItemExpressionBuilder) ([]*dynamodb.BatchGetItemInput, error) {
+	if itemExpressionBuilder == nil {
+		return nil, errors.New("nil expression builder passed for batch get of table " + tableName)
+	}
+
+	projectionBuilder, err := itemExpressionBuilder.BuildProjectionBuilder()
+	if err != nil {
+		return nil, err
+	}
+
+	projectionExpr, err := expression.NewBuilder().WithProjection(*projectionBuilder).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := marshalItems(keys, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueKeys, err := core.UniqueKeys(tableName, items, itemExpressionBuilder.keyAttributeNames())
+	if err != nil {
+		return nil, err
+	}
+
+	batchGetItemInputs := []*dynamodb.BatchGetItemInput{}
+	for _, chunk := range core.Chunks(uniqueKeys, MaxBatchGetItemKeys) {
+		chunkKeys := make([]map[string]*dynamodb.AttributeValue, 0, len(chunk))
+		for _, key := range chunk {
+			chunkKeys = append(chunkKeys, sdkv1.ToItem(key))
+		}
+
+		batchGetItemInputs = append(batchGetItemInputs, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]*dynamodb.KeysAndAttributes{
+				tableName: {
+					Keys:                     chunkKeys,
+					ProjectionExpression:     projectionExpr.Projection(),
+					ExpressionAttributeNames: projectionExpr.Names(),
+				},
+			},
+		})
+	}
+
+	return batchGetItemInputs, nil
+}
+
+// UnprocessedBatchGetItemInput builds a BatchGetItemInput for re-fetching the keys which were not
+// processed by dynamo db, returns nil if every key was processed
+func UnprocessedBatchGetItemInput(batchGetItemOutput *dynamodb.BatchGetItemOutput) *dynamodb.BatchGetItemInput {
+	if batchGetItemOutput == nil || len(batchGetItemOutput.UnprocessedKeys) == 0 {
+		return nil
+	}
+
+	return &dynamodb.BatchGetItemInput{
+		RequestItems: batchGetItemOutput.UnprocessedKeys,
+	}
+}
+
+// DecodeBatchGetItemOutput decodes the items fetched from the table into type `I`, which
+// generally is the struct representing a single item of dynamo db
+func DecodeBatchGetItemOutput[I any](tableName string, batchGetItemOutput *dynamodb.BatchGetItemOutput) ([]I, error) {
+	items := []I{}
+	if batchGetItemOutput == nil {
+		return items, nil
+	}
+
+	if err := dynamodbattribute.UnmarshalListOfMaps(batchGetItemOutput.Responses[tableName], &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// BuildBatchWriteItemInputs builds BatchWriteItemInput(s) which put `putItems` into and delete
+// `deleteKeys` from the table, requests are split into chunks of MaxBatchWriteItems and every
+// chunk gets its own BatchWriteItemInput. Put requests come before delete requests.
+//
+// Items and keys can be of different types, each either a struct or a
+// map[string]*dynamodb.AttributeValue, for keys attributes other than the key attributes are
+// ignored. Returns an error if more than one request targets the same item since dynamo db
+// rejects such request
+func BuildBatchWriteItemInputs[P, K any](tableName string, putItems []P, deleteKeys []K, itemExpressionBuilder ItemExpressionBuilder) ([]*dynamodb.BatchWriteItemInput, error) {
+	if itemExpressionBuilder == nil {
+		return nil, errors.New("nil expression builder passed for batch write of table " + tableName)
+	}
+
+	putValues, err := marshalItems(putItems, "put item")
+	if err != nil {
+		return nil, err
+	}
+
+	deleteValues, err := marshalItems(deleteKeys, "delete key")
+	if err != nil {
+		return nil, err
+	}
+
+	writeRequests, err := core.WriteRequests(tableName, putValues, deleteValues, itemExpressionBuilder.keyAttributeNames())
+	if err != nil {
+		return nil, err
+	}
+
+	batchWriteItemInputs := []*dynamodb.BatchWriteItemInput{}
+	for _, chunk := range core.Chunks(writeRequests, MaxBatchWriteItems) {
+		chunkRequests := make([]*dynamodb.WriteRequest, 0, len(chunk))
+		for _, writeRequest := range chunk {
+			if writeRequest.Item != nil {
+				chunkRequests = append(chunkRequests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: sdkv1.ToItem(writeRequest.Item)}})
+			} else {
+				chunkRequests = append(chunkRequests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: sdkv1.ToItem(writeRequest.Key)}})
+			}
+		}
+
+		batchWriteItemInputs = append(batchWriteItemInputs, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{
+				tableName: chunkRequests,
+			},
+		})
+	}
+
+	return batchWriteItemInputs, nil
+}
+
+// UnprocessedBatchWriteItemInput builds a BatchWriteItemInput for re-sending the requests which
+// were not processed by dynamo db, returns nil if every request was processed
+func UnprocessedBatchWriteItemInput(batchWriteItemOutput *dynamodb.BatchWriteItemOutput) *dynamodb.BatchWriteItemInput {
+	if batchWriteItemOutput == nil || len(batchWriteItemOutput.UnprocessedItems) == 0 {
+		return nil
+	}
+
+	return &dynamodb.BatchWriteItemInput{
+		RequestItems: batchWriteItemOutput.UnprocessedItems,
+	}
+}
+
+// marshalItems marshals `values` into dynamo db items, `what` names the values in errors
+func marshalItems[I any](values []I, what string) ([]map[string]ddbexpr.Value, error) {
+	items := make([]map[string]ddbexpr.Value, 0, len(values))
+	for idx, value := range values {
+		item, err := marshalItem(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %d: %w", what, idx, err)
+		}
+
+		items = append(items, sdkv1.FromItem(item))
+	}
+
+	return items, nil
+}
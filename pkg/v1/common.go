@@ -10,3 +10,22 @@ const (
 	UPDATE_ADD
 	UPDATE_DELETE
 )
+
+func (do DynamoOperation) String() string {
+	switch do {
+	case NO_OP:
+		return "NO_OP"
+	case GET:
+		return "GET"
+	case UPDATE_SET:
+		return "UPDATE_SET"
+	case UPDATE_REMOVE:
+		return "UPDATE_REMOVE"
+	case UPDATE_ADD:
+		return "UPDATE_ADD"
+	case UPDATE_DELETE:
+		return "UPDATE_DELETE"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv1"
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type diffOptions struct {
+	// Add a condition asserting the old value on every changed attribute
+	oldValueConditions bool
+}
+
+type DiffOption func(*diffOptions)
+
+// WithOldValueConditions adds a condition on every changed attribute asserting that its
+// old value still holds, attributes absent in old value are asserted to not exist
+func WithOldValueConditions() DiffOption {
+	return func(options *diffOptions) {
+		options.oldValueConditions = true
+	}
+}
+
+// UpdateFromDiff walks this expression builder tree and marks UPDATE_SET/UPDATE_REMOVE on the
+// minimal set of document paths which differ between `oldItem` and `newItem`
+//
+// Both items must be of the same type, generally the struct representing a single item of
+// dynamo db. Nested maps are compared attribute by attribute and lists index by index, list
+// items which were not added via AddListItem are added to the tree. Attributes below a node
+// having no children, e.g. sets, are set as a whole. Returns an error if the items are of different
+// types, if a key attribute differs or, wrapping ErrUnknownAttribute, if a changed attribute of a
+// map has no node in the tree
+func (d DDBItemExpressionBuilder[T]) UpdateFromDiff(oldItem, newItem any, opts ...DiffOption) error {
+	defer d.root.node.LockTree()()
+	if reflect.TypeOf(oldItem) != reflect.TypeOf(newItem) {
+		return errors.New("old and new item of diff must be of the same type")
+	}
+
+	options := diffOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	oldValue, err := dynamodbattribute.Marshal(oldItem)
+	if err != nil {
+		return err
+	}
+
+	newValue, err := dynamodbattribute.Marshal(newItem)
+	if err != nil {
+		return err
+	}
+
+	if oldValue.M == nil || newValue.M == nil {
+		return errors.New("old and new item of diff must marshal into a map")
+	}
+
+	return d.root.node.Diff(utils.PointerTo(sdkv1.FromAttributeValue(oldValue)), utils.PointerTo(sdkv1.FromAttributeValue(newValue)), options.oldValueConditions)
+}
+
+// rawAttributeValue passes an already marshalled attribute value through the marshaller
+type rawAttributeValue struct {
+	attributeValue *dynamodb.AttributeValue
+}
+
+func (rav rawAttributeValue) MarshalDynamoDBAttributeValue(attributeValue *dynamodb.AttributeValue) error {
+	*attributeValue = *rav.attributeValue
+	return nil
+}
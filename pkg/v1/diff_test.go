@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing update expression generated from diff of two persons
+func TestUpdateFromDiff(t *testing.T) {
+	oldPerson := Person{
+		PK:   utils.PointerTo("person#1"),
+		SK:   utils.PointerTo("details"),
+		Name: utils.PointerTo("Old Name"),
+		FamilyDetails: &FamilyDetail{
+			Children:  &[]*Child{{Name: utils.PointerTo("Child1")}, {Name: utils.PointerTo("Child2")}},
+			IsMarried: utils.PointerTo(true),
+		},
+		PhoneNos: &[]*string{utils.PointerTo("111"), utils.PointerTo("222"), utils.PointerTo("333")},
+	}
+	newPerson := Person{
+		PK: utils.PointerTo("person#1"),
+		SK: utils.PointerTo("details"),
+		FamilyDetails: &FamilyDetail{
+			Children:  &[]*Child{{Name: utils.PointerTo("Child1")}, {Name: utils.PointerTo("Child2 Renamed")}},
+			IsMarried: utils.PointerTo(true),
+		},
+		PhoneNos: &[]*string{utils.PointerTo("111"), utils.PointerTo("999")},
+	}
+
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	if err := expBuilder.UpdateFromDiff(oldPerson, newPerson, WithOldValueConditions()); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	updateBuilder, err := expBuilder.BuildUpdateBuilder()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(*updateBuilder).WithCondition(*expBuilder.BuildConditionBuilder()).Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, "REMOVE #0, #3[2]\nSET #1.#2[1].#0 = :4, #3[1] = :5\n", *expr.Update())
+	assert.Equal(t, "(((#0 = :0) AND (#1.#2[1].#0 = :1)) AND (#3[1] = :2)) AND (#3[2] = :3)", *expr.Condition())
+	assert.Equal(t, map[string]*string{
+		"#0": aws.String("name"),
+		"#1": aws.String("family_details"),
+		"#2": aws.String("children"),
+		"#3": aws.String("phone_nos"),
+	}, expr.Names())
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		":0": {S: aws.String("Old Name")},
+		":1": {S: aws.String("Child2")},
+		":2": {S: aws.String("222")},
+		":3": {S: aws.String("333")},
+		":4": {S: aws.String("Child2 Renamed")},
+		":5": {S: aws.String("999")},
+	}, expr.Values())
+
+	// nothing changed
+	expBuilder = NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	assert.Nil(t, expBuilder.UpdateFromDiff(oldPerson, oldPerson))
+	assert.Nil(t, expBuilder.BuildConditionBuilder())
+
+	// key attributes cannot be updated
+	newPerson.SK = utils.PointerTo("other")
+	assert.ErrorContains(t, expBuilder.UpdateFromDiff(oldPerson, newPerson, WithOldValueConditions()), "cannot diff attribute [sk]: key attribute cannot be updated")
+	// the other changes are not marked and their list items are not added
+	assert.Nil(t, expBuilder.BuildConditionBuilder())
+	assert.Empty(t, expBuilder.DDBItemRoot().AR().PhoneNos.Children())
+	assert.Empty(t, expBuilder.DDBItemRoot().AR().FamilyDetails.AR().Children.Children())
+	assert.ErrorContains(t, expBuilder.UpdateFromDiff(oldPerson, &newPerson), "old and new item of diff must be of the same type")
+
+	// attributes without a node in the tree can't be diffed once changed
+	type nicknamedPerson struct {
+		Person
+		Nickname *string `json:"nickname,omitempty" dynamodbav:"nickname,omitempty"`
+	}
+	expBuilder = NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	oldNicknamed := nicknamedPerson{Person: oldPerson, Nickname: utils.PointerTo("Old")}
+	assert.Nil(t, expBuilder.UpdateFromDiff(oldNicknamed, oldNicknamed))
+	err = expBuilder.UpdateFromDiff(oldNicknamed, nicknamedPerson{Person: oldPerson})
+	assert.ErrorIs(t, err, ErrUnknownAttribute)
+	assert.EqualError(t, err, "cannot diff attribute [nickname]: unknown attribute")
+}
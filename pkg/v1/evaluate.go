@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv1"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// EvaluationResult is the outcome of evaluating a condition against an item
+type EvaluationResult = ddbexpr.EvaluationResult
+
+// Evaluate evaluates the conditions marked on this expression builder tree against `item`
+// in memory, following the semantics of dynamo db. Item is either a
+// map[string]*dynamodb.AttributeValue or a value which marshals into one, generally the
+// struct representing a single item of dynamo db. An item always matches when no
+// condition is marked. Filters marked using AndWithFilter are not conditions of a write and
+// are evaluated by EvaluateFilters instead
+func (d DDBItemExpressionBuilder[T]) Evaluate(item any) (EvaluationResult, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().Evaluate(item)
+	}
+
+	if err := d.root.node.IndexErrors(); err != nil {
+		return EvaluationResult{}, err
+	}
+
+	conditionBuilder := d.conditionBuilder()
+	if conditionBuilder == nil {
+		return EvaluationResult{Matched: true}, nil
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(*conditionBuilder).Build()
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	return EvaluateCondition(expr, item)
+}
+
+// EvaluateFilters evaluates the filters marked on this expression builder tree against `item`
+// in memory like Evaluate does for conditions, i.e. whether a query or scan returns the item.
+// An item always matches when no filter is marked
+func (d DDBItemExpressionBuilder[T]) EvaluateFilters(item any) (EvaluationResult, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().EvaluateFilters(item)
+	}
+
+	if err := d.root.node.IndexErrors(); err != nil {
+		return EvaluationResult{}, err
+	}
+
+	filterBuilder := addFilters(d.root.node, nil)
+	if filterBuilder == nil {
+		return EvaluationResult{Matched: true}, nil
+	}
+
+	expr, err := expression.NewBuilder().WithFilter(*filterBuilder).Build()
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	return EvaluateFilter(expr, item)
+}
+
+// EvaluateCondition evaluates the condition of a built expression against `item`, see Evaluate
+func EvaluateCondition(expr expression.Expression, item any) (EvaluationResult, error) {
+	return evaluate(expr.Condition(), expr, item)
+}
+
+// EvaluateFilter evaluates the filter of a built expression against `item`, see Evaluate
+func EvaluateFilter(expr expression.Expression, item any) (EvaluationResult, error) {
+	return evaluate(expr.Filter(), expr, item)
+}
+
+func evaluate(conditionExpr *string, expr expression.Expression, item any) (EvaluationResult, error) {
+	if conditionExpr == nil {
+		return EvaluationResult{Matched: true}, nil
+	}
+
+	attributeValues, err := marshalItem(item)
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	return fromExpression(expr).Evaluate(conditionExpr, sdkv1.FromItem(attributeValues))
+}
+
+// ApplyUpdate applies the updates marked on the expression builder tree on `item` in memory,
+// following the semantics of dynamo db, and returns the updated item. `item` itself is left
+// unchanged and is returned as a copy when no update is marked
+func ApplyUpdate(item map[string]*dynamodb.AttributeValue, itemExpressionBuilder ItemExpressionBuilder) (map[string]*dynamodb.AttributeValue, error) {
+	updateBuilder, err := itemExpressionBuilder.BuildUpdateBuilder()
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(*updateBuilder).Build()
+	if errors.As(err, &expression.UnsetParameterError{}) { // no attribute is marked for update
+		return ApplyUpdateExpression(item, expression.Expression{})
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ApplyUpdateExpression(item, expr)
+}
+
+// ApplyUpdateExpression applies the update of a built expression on `item`, see ApplyUpdate
+func ApplyUpdateExpression(item map[string]*dynamodb.AttributeValue, expr expression.Expression) (map[string]*dynamodb.AttributeValue, error) {
+	updatedItem, err := fromExpression(expr).ApplyUpdate(sdkv1.FromItem(item))
+	if err != nil {
+		return nil, err
+	}
+
+	return sdkv1.ToItem(updatedItem), nil
+}
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing evaluation of conditions marked on the expression builder tree against a struct
+func TestEvaluate(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.FamilyDetails.AR().Children.AddListItem(0)
+	expBuilder.Build()
+
+	person := Person{
+		PK:   utils.PointerTo("person#1"),
+		Name: utils.PointerTo("John"),
+		FamilyDetails: &FamilyDetail{
+			Children:  &[]*Child{{Name: utils.PointerTo("Child")}},
+			IsMarried: utils.PointerTo(true),
+		},
+	}
+
+	// nothing is marked
+	result, err := expBuilder.Evaluate(person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, EvaluationResult{Matched: true}, result)
+
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().BeginsWith("Jo"))
+	rootExpBldr.FamilyDetails.AR().IsMarried.AndWithCondition()(rootExpBldr.FamilyDetails.AR().IsMarried.GetNameBuilder().Equal(expression.Value(true)))
+	childName := rootExpBldr.FamilyDetails.AR().Children.Index(0).AR().Name
+	childName.AndWithCondition()(childName.GetNameBuilder().AttributeExists())
+
+	result, err = expBuilder.Evaluate(&person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, EvaluationResult{Matched: true}, result)
+
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().Size().GreaterThan(expression.Value(10)))
+	result, err = expBuilder.Evaluate(person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, EvaluationResult{FailedCondition: "size (name) > 10"}, result)
+
+	// filters are evaluated apart from the conditions
+	result, err = expBuilder.EvaluateFilters(person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, EvaluationResult{Matched: true}, result)
+
+	rootExpBldr.PK.AndWithFilter()(expression.Name("pk").Equal(expression.Value("person#1")))
+	rootExpBldr.Name.AndWithFilter()(rootExpBldr.Name.GetNameBuilder().Equal(expression.Value("Jane")))
+	result, err = expBuilder.EvaluateFilters(person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, EvaluationResult{FailedCondition: "name = \"Jane\""}, result)
+	result, _ = expBuilder.Evaluate(person)
+	assert.Equal(t, EvaluationResult{FailedCondition: "size (name) > 10"}, result)
+}
+
+// Testing evaluation of condition and filter of a built expression against a map of attribute values
+func TestEvaluateConditionAndFilter(t *testing.T) {
+	item := map[string]*dynamodb.AttributeValue{
+		"pk":  {S: aws.String("person#1")},
+		"age": {N: aws.String("30")},
+	}
+
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name("age").Between(expression.Value(18), expression.Value(65))).
+		WithFilter(expression.Name("pk").Equal(expression.Value("person#1")).And(expression.Name("age").GreaterThan(expression.Value(60)))).
+		Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	result, err := EvaluateCondition(expr, item)
+	assert.Nil(t, err)
+	assert.Equal(t, EvaluationResult{Matched: true}, result)
+
+	result, err = EvaluateFilter(expr, item)
+	assert.Nil(t, err)
+	assert.Equal(t, EvaluationResult{FailedCondition: "age > 60"}, result)
+
+	// between with lower bound greater than upper bound is rejected by dynamo db
+	expr, _ = expression.NewBuilder().WithCondition(expression.Name("age").Between(expression.Value(65), expression.Value(18))).Build()
+	_, err = EvaluateCondition(expr, item)
+	assert.NotNil(t, err)
+}
+
+// Testing updates marked on the expression builder tree applied on an item in memory
+func TestApplyUpdate(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	expBuilder.Build()
+
+	person := Person{
+		PK:            utils.PointerTo("person#1"),
+		Name:          utils.PointerTo("John"),
+		FamilyDetails: &FamilyDetail{IsMarried: utils.PointerTo(true)},
+		PhoneNos:      &[]*string{utils.PointerTo("123")},
+	}
+	item, err := dynamodbattribute.MarshalMap(person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	// nothing is marked
+	updatedItem, err := ApplyUpdate(item, expBuilder)
+	assert.Nil(t, err)
+	assert.Equal(t, item, updatedItem)
+
+	rootExpBldr.Name.AddValue(UPDATE_SET, utils.PointerTo("Doe"))
+	rootExpBldr.FamilyDetails.AR().IsMarried.AddValue(UPDATE_REMOVE, nil)
+	rootExpBldr.PhoneNos.AddValue(UPDATE_SET, expression.ListAppend(rootExpBldr.PhoneNos.GetNameBuilder(), expression.Value([]string{"456"})))
+
+	updatedItem, err = ApplyUpdate(item, expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	updatedPerson := Person{}
+	if err := dynamodbattribute.UnmarshalMap(updatedItem, &updatedPerson); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, Person{
+		PK:            utils.PointerTo("person#1"),
+		Name:          utils.PointerTo("Doe"),
+		FamilyDetails: &FamilyDetail{},
+		PhoneNos:      &[]*string{utils.PointerTo("123"), utils.PointerTo("456")},
+	}, updatedPerson)
+	assert.Equal(t, "John", *item["name"].S) // item is left unchanged
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing explanation of every expression of the expression builder tree
+func TestExplain(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.BankDetails.AR().Accounts.AddListItem(3)
+	expBuilder.Build()
+
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value("person#1")))
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().In(expression.Value("Name1"), expression.Value(strings.Repeat("a", 100))))
+	rootExpBldr.Name.AddValue(UPDATE_SET, utils.PointerTo("New Name"))
+	rootExpBldr.BankDetails.AR().Accounts.Index(3).AR().BankAccountNumber.AddValue(UPDATE_ADD, 2)
+	rootExpBldr.FamilyDetails.AR().Children.AddValue(UPDATE_SET, []Child{{Name: utils.PointerTo("Child")}})
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, strings.Join([]string{
+		`KeyCondition: pk = "person#1"`,
+		`Condition: name IN ("Name1", "` + strings.Repeat("a", 64) + `...")`,
+		`Projection: pk, sk`,
+		`Update: ADD bank_details.accounts[3].bank_account_number 2 SET name = "New Name", family_details.children = [{"name": "Child"}]`,
+	}, "\n"), explanation)
+
+	// nothing is marked
+	transactionExpBuilder := NewTransaction_ExpressionBuilder()
+	transactionExpBuilder.Build()
+	explanation, err = transactionExpBuilder.Explain()
+	assert.Nil(t, err)
+	assert.Equal(t, "Projection: user_id, transaction_id", explanation)
+}
@@ -1,11 +1,13 @@
 package v1
 
 import (
+	"github.com/gauxs/dynexpr/internal/core"
+
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 )
 
 const (
-	DDBAtributeNameCancatenator = "."
+	DDBAtributeNameCancatenator = core.DDBAtributeNameCancatenator
 )
 
 // This is implemented by structs using this package
@@ -15,19 +17,12 @@ type TreeBuilder[T any] interface {
 	BuildTree(string) *DynamoAttribute[T]
 }
 
-// TODO: instead of Builder and Projector we should have union of
+// TODO: instead of Projector and Updater we should have union of
 // DynamoAttribute and DynamoListAttribute but current limitation
 // doesn't allow us.
 // Ref: https://stackoverflow.com/a/71378366
 
-type Builder interface {
-	// build builds `this` attribute. If `this` attribute
-	// is a list then new nodes(elements of the list) are attached
-	// if specified via AddListItem
-	build(string) error
-}
-
-// TODO: merge this with Builder
+// TODO: merge this with Updater
 type Projector interface {
 	// addName adds 'this' attribute's document name into the projection builder
 	// passed in argument and returns a new projection builder
@@ -54,6 +49,9 @@ type KeyConditioner interface {
 	//
 	// condition passed in argument always comes first in `AND`
 	addKeyCondition(*expression.KeyConditionBuilder) *expression.KeyConditionBuilder
+
+	// GetName returns the name of the key attribute as defined in DB
+	GetName() string
 }
 
 type Updater interface {
@@ -62,68 +60,189 @@ type Updater interface {
 	addUpdate(*expression.UpdateBuilder) (*expression.UpdateBuilder, error)
 }
 
+// attributeNode is implemented by the typed attributes wrapping a node of the expression
+// builder tree
+type attributeNode interface {
+	// coreNode returns the node of 'this' attribute
+	coreNode() *core.Node
+}
+
 // Enforcing constraints at compile time
-var _ Builder = (&DynamoAttribute[int]{})
 var _ Updater = (&DynamoAttribute[int]{})
 var _ Projector = (&DynamoAttribute[int]{})
 var _ Conditioner = (&DynamoAttribute[int]{})
+var _ attributeNode = (&DynamoAttribute[int]{})
 
-var _ Builder = (&DynamoListAttribute[int]{})
 var _ Updater = (&DynamoListAttribute[int]{})
 var _ Projector = (&DynamoListAttribute[int]{})
 var _ Conditioner = (&DynamoListAttribute[int]{})
+var _ attributeNode = (&DynamoListAttribute[int]{})
 
 var _ Projector = (&DynamoKeyAttribute[int]{})
 var _ KeyConditioner = (&DynamoKeyAttribute[int]{})
+var _ attributeNode = (&DynamoKeyAttribute[int]{})
+
+// ItemExpressionBuilder is implemented by DDBItemExpressionBuilder of every DDB item
+// type, this allows expression builders of different DDB items to be used together
+type ItemExpressionBuilder interface {
+	BuildProjectionBuilder() (*expression.ProjectionBuilder, error)
+	BuildKeyConditionBuilder() *expression.KeyConditionBuilder
+	BuildConditionBuilder() *expression.ConditionBuilder
+	BuildFilterBuilder() (*expression.ConditionBuilder, error)
+	BuildUpdateBuilder() (*expression.UpdateBuilder, error)
+
+	// keyAttributeNames returns the name of all the key attributes of the DDB item
+	keyAttributeNames() []string
+
+	// checkBuild returns the errors BuildKeyConditionBuilder and BuildConditionBuilder can't return
+	checkBuild() error
+}
+
+var _ ItemExpressionBuilder = DDBItemExpressionBuilder[int]{}
 
 type DDBItemExpressionBuilder[T any] struct {
 	// root of the ddb item
 	root *DynamoAttribute[T]
+
+	// Validate the tree in BuildProjectionBuilder and BuildUpdateBuilder
+	validation bool
+
+	// Mode in which conflicting marks are handled
+	markMode MarkMode
+
+	// Scope of the slots created by Slot, shared with the clones of this expression builder
+	slotScope *core.SlotScope
 }
 
 func (d DDBItemExpressionBuilder[T]) DDBItemRoot() *DynamoAttribute[T] {
 	return d.root
 }
 
-// Build creates additional tree structure for attributes of List/Set data type
-// this has to be invoked before any builder can be built
+// Build is optional, document paths are computed on demand and list items can be added at any
+// time. It is kept for compatibility, does nothing and always returns nil
 func (d DDBItemExpressionBuilder[T]) Build() error {
-	return d.root.build("")
+	return nil
 }
 
 // BuildProjectionBuilder builds a ProjectionBuilder by aggregating all the projection of this
 // expression builder tree
 func (d DDBItemExpressionBuilder[T]) BuildProjectionBuilder() (*expression.ProjectionBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildProjectionBuilder()
+	}
+
+	if err := d.markConflicts(MARK_CONFLICT_SHADOWED_PROJECTION); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return nil, err
+	}
+
 	return d.root.addName(&expression.ProjectionBuilder{})
 }
 
 // BuildKeyConditionBuilder builds a KeyConditionBuilder by aggregating all the KeyCondition of this
-// expression builder tree
+// expression builder tree. It doesn't return an error, key conditions are validated by the
+// builders which do or by calling Validate
 func (d DDBItemExpressionBuilder[T]) BuildKeyConditionBuilder() *expression.KeyConditionBuilder {
-	var keyConditionBuilder *expression.KeyConditionBuilder
-	// key condition will always be top level attribute, so we can directly traverse root's child attribute only
-	// instead of a recusrsive solution
-	for _, childAttribute := range d.root.childAttributes {
-		switch childAttributeType := childAttribute.(type) {
-		case KeyConditioner:
-			if newKeyConditionBuilder := childAttributeType.addKeyCondition(keyConditionBuilder); newKeyConditionBuilder != nil {
-				keyConditionBuilder = newKeyConditionBuilder
-			}
-		}
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildKeyConditionBuilder()
 	}
 
-	return keyConditionBuilder
+	return d.keyConditionBuilder()
 }
 
 // BuildConditionBuilder builds a ConditionBuilder by aggregating all the condition of this
-// expression builder tree
+// expression builder tree. It doesn't return an error, conditions are validated by the builders
+// which do or by calling Validate
 func (d DDBItemExpressionBuilder[T]) BuildConditionBuilder() *expression.ConditionBuilder {
-	// return d.root.addCondition(&expression.ConditionBuilder{})
-	return d.root.addCondition(nil)
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildConditionBuilder()
+	}
+
+	return d.conditionBuilder()
+}
+
+// BuildFilterBuilder builds a ConditionBuilder by aggregating all the filters of this
+// expression builder tree, to be used as the filter of a scan. Use BuildQueryFilterBuilder for
+// the filter of a query
+func (d DDBItemExpressionBuilder[T]) BuildFilterBuilder() (*expression.ConditionBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildFilterBuilder()
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return nil, err
+	}
+
+	return addFilters(d.root.node, nil), nil
+}
+
+// BuildQueryFilterBuilder builds the ConditionBuilder of BuildFilterBuilder to be used as the
+// filter of a query, filters marked on key attributes are rejected with ErrKeyAttributeFilter.
+// Use key conditions instead
+func (d DDBItemExpressionBuilder[T]) BuildQueryFilterBuilder() (*expression.ConditionBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildQueryFilterBuilder()
+	}
+
+	if err := d.root.node.CheckQueryFilters(); err != nil {
+		return nil, err
+	}
+
+	return d.BuildFilterBuilder()
 }
 
 // BuildUpdateBuilder builds a UpdateBuilder by aggregating all the update operation of this
 // expression builder tree
 func (d DDBItemExpressionBuilder[T]) BuildUpdateBuilder() (*expression.UpdateBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildUpdateBuilder()
+	}
+
+	if err := d.markConflicts(MARK_CONFLICT_SHADOWED_UPDATE, MARK_CONFLICT_REPLACED_UPDATE); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return nil, err
+	}
+
 	return d.root.addUpdate(&expression.UpdateBuilder{})
 }
+
+// checkBuild returns the errors of list items Index couldn't add to this expression builder tree
+// and runs Validate if it is built with validation, every builder returning an error runs it
+func (d DDBItemExpressionBuilder[T]) checkBuild() error {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().checkBuild()
+	}
+
+	if err := d.root.node.IndexErrors(); err != nil {
+		return err
+	}
+
+	if !d.validation {
+		return nil
+	}
+
+	return d.Validate()
+}
+
+// keyConditionBuilder aggregates the key conditions of this expression builder tree, nil when
+// nothing is marked
+func (d DDBItemExpressionBuilder[T]) keyConditionBuilder() *expression.KeyConditionBuilder {
+	return addKeyConditions(d.root.node.KeyConditions(), nil)
+}
+
+// conditionBuilder aggregates the conditions of this expression builder tree, nil when nothing is
+// marked
+func (d DDBItemExpressionBuilder[T]) conditionBuilder() *expression.ConditionBuilder {
+	return d.root.addCondition(nil)
+}
+
+// keyAttributeNames returns the name of all the key attributes of this expression builder tree
+func (d DDBItemExpressionBuilder[T]) keyAttributeNames() []string {
+	return d.root.node.KeyAttributeNames()
+}
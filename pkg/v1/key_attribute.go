@@ -1,8 +1,7 @@
 package v1
 
 import (
-	"errors"
-
+	"github.com/gauxs/dynexpr/internal/core"
 	"github.com/gauxs/dynexpr/internal/utils"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -10,64 +9,31 @@ import (
 
 // Represents a dyanmo db primary key attribute
 type DynamoKeyAttribute[T any] struct {
-	// True when build has been executed on 'this' attribute
-	// projection, Condition and Update can only be done after
-	// build is executed
-	buildExecuted bool
-
-	// Mark 'this' attribute for projection
-	projection bool
-
 	// Name of the dynamo attribute as defined in DB
 	Name string
 
-	// Document path of this attribute
-	documentPath string
-
-	// Use this to build any condition on 'this' attribute
-	keyBuilder expression.KeyBuilder
-
-	// Represents all the conditions applied on 'this' dynamo attribute
-	keyConditionBuilder *expression.KeyConditionBuilder
+	// Node of 'this' attribute in the expression builder tree
+	// holding its document path and marks
+	node *core.Node
 }
 
 func NewDynamoKeyAttribute[T any]() *DynamoKeyAttribute[T] {
-	return &DynamoKeyAttribute[T]{}
-}
-
-func (dka *DynamoKeyAttribute[T]) constructDocumentPath(documentPathOfParent string) string {
-	return dka.GetName()
+	dka := &DynamoKeyAttribute[T]{node: core.NewNode(core.NODE_KEY)}
+	dka.node.SetOwner(dka)
+	return dka
 }
 
 // WithName builds `this` DynamoKeyAttribute with a dynamo db attribute name
 func (dka *DynamoKeyAttribute[T]) WithName(name string) *DynamoKeyAttribute[T] {
 	dka.Name = name
+	dka.node.SetName(name)
 	return dka
 }
 
-func (dka *DynamoKeyAttribute[T]) build(parentDocumentPath string) error {
-	if dka.buildExecuted {
-		return errors.New("build is already executed on attribute " + dka.documentPath)
-	}
-
-	// build document path
-	dka.documentPath = dka.constructDocumentPath(parentDocumentPath)
-
-	// build key builder
-	dka.keyBuilder = expression.Key(dka.documentPath)
-
-	dka.buildExecuted = true
-	return nil
-}
-
 // Project marks `this` attribute for projection
 func (dka *DynamoKeyAttribute[T]) Project() error {
-	if !dka.buildExecuted {
-		return errors.New("build is not yet executed on attribute [" + dka.Name + "], cannot mark this attribute for projection")
-	}
-
-	dka.projection = true
-	return nil
+	defer dka.node.LockTree()()
+	return dka.node.Project()
 }
 
 func (dka *DynamoKeyAttribute[T]) GetName() string {
@@ -75,47 +41,72 @@ func (dka *DynamoKeyAttribute[T]) GetName() string {
 }
 
 func (dka *DynamoKeyAttribute[T]) GetKeyBuilder() expression.KeyBuilder {
-	return dka.keyBuilder
+	defer dka.node.RLockTree()()
+	return expression.Key(dka.node.DocumentPath())
 }
 
-func (dka *DynamoKeyAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
-	if !dka.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + dka.Name + "], cannot mark this attribute for projection")
+// AndWithCondition adds a new condition to `this` attributes existing conditions using `AND`
+// NOTE: keyConditionBuilder represent any valid condition, zero value of struct `KeyConditionBuilder`
+// might give build error
+func (dka *DynamoKeyAttribute[T]) AndWithCondition() func(keyConditionBuilder expression.KeyConditionBuilder) {
+	return func(keyConditionBuilder expression.KeyConditionBuilder) {
+		defer dka.node.LockTree()()
+		dka.node.AndWithCondition(keyConditionBuilder)
 	}
+}
 
-	if projectionBuilder == nil {
-		return nil, errors.New("nil projection builder passed for attribute " + dka.documentPath)
+// AndWithFilter adds a new filter to `this` attributes existing filters using `AND`, DynamoDB
+// allows key attributes in the filter of a scan only, see BuildQueryFilterBuilder
+func (dka *DynamoKeyAttribute[T]) AndWithFilter() func(filterBuilder expression.ConditionBuilder) {
+	return func(filterBuilder expression.ConditionBuilder) {
+		defer dka.node.LockTree()()
+		dka.node.AndWithFilter(filterBuilder)
 	}
+}
 
-	return utils.PointerTo(projectionBuilder.AddNames(expression.Name(dka.documentPath))), nil
+func (dka *DynamoKeyAttribute[T]) coreNode() *core.Node {
+	return dka.node
 }
 
-// AndWithCondition adds a new condition to `this` attributes existing conditions using `AND`
-// NOTE: keyConditionBuilder represent any valid condition, zero value of struct `KeyConditionBuilder`
-// might give build error
-func (dka *DynamoKeyAttribute[T]) AndWithCondition() func(keyConditionBuilder expression.KeyConditionBuilder) {
-	return func(keyConditionBuilder expression.KeyConditionBuilder) {
-		if dka.keyConditionBuilder != nil {
-			// if keyConditionBuilder.IsSet() {
-			dka.keyConditionBuilder = utils.PointerTo((*dka.keyConditionBuilder).And(keyConditionBuilder))
-			// }
-		} else {
-			// dka.isKeyConditionBuilderSet = true
-			dka.keyConditionBuilder = &keyConditionBuilder
-		}
+func (dka *DynamoKeyAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	return addNames(dka.node, projectionBuilder)
+}
+
+func (dka *DynamoKeyAttribute[T]) addKeyCondition(keyConditionBuilder *expression.KeyConditionBuilder) *expression.KeyConditionBuilder {
+	return addKeyConditions([][]any{dka.node.MarkedConditions()}, keyConditionBuilder)
+}
+
+// renderKeyCondition converts a marked key condition into a key condition builder
+func renderKeyCondition(condition any) expression.KeyConditionBuilder {
+	if parsed, ok := condition.(core.ParsedCondition); ok {
+		return renderParsedKeyCondition(parsed)
 	}
+
+	return condition.(expression.KeyConditionBuilder)
 }
 
-func (dka *DynamoKeyAttribute[T]) addKeyCondition(keyConditionBuilder *expression.KeyConditionBuilder) (newKeyConditionBuilder *expression.KeyConditionBuilder) {
-	if dka.keyConditionBuilder != nil {
-		if keyConditionBuilder != nil {
+// addKeyConditions adds the key conditions grouped by key attribute into the key condition
+// builder using `AND` and returns a new key condition builder
+func addKeyConditions(keyConditions [][]any, keyConditionBuilder *expression.KeyConditionBuilder) *expression.KeyConditionBuilder {
+	newKeyConditionBuilder := keyConditionBuilder
+	for _, conditions := range keyConditions {
+		var nodeKeyConditionBuilder *expression.KeyConditionBuilder
+		for _, condition := range conditions {
+			if nodeKeyConditionBuilder != nil {
+				nodeKeyConditionBuilder = utils.PointerTo(nodeKeyConditionBuilder.And(renderKeyCondition(condition)))
+			} else {
+				nodeKeyConditionBuilder = utils.PointerTo(renderKeyCondition(condition))
+			}
+		}
+
+		if nodeKeyConditionBuilder == nil {
+			continue
+		} else if newKeyConditionBuilder != nil {
 			// parent condition will be L.H.S
-			newKeyConditionBuilder = utils.PointerTo(keyConditionBuilder.And(*dka.keyConditionBuilder))
+			newKeyConditionBuilder = utils.PointerTo(newKeyConditionBuilder.And(*nodeKeyConditionBuilder))
 		} else {
-			newKeyConditionBuilder = dka.keyConditionBuilder
+			newKeyConditionBuilder = nodeKeyConditionBuilder
 		}
-	} else {
-		newKeyConditionBuilder = keyConditionBuilder
 	}
 
 	return newKeyConditionBuilder
@@ -1,11 +1,7 @@
 package v1
 
 import (
-	"errors"
-	"strconv"
-	"strings"
-
-	"github.com/gauxs/dynexpr/internal/utils"
+	"github.com/gauxs/dynexpr/internal/core"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 )
@@ -18,55 +14,31 @@ import (
 // 2 - List of list is NOT supported currently
 // 3 - List of object is supported but list of object containing another list is NOT
 type DynamoListAttribute[T any] struct {
-	// True when build has been executed on 'this' attribute
-	// projection, Condition and Update can only be done after
-	// build is executed
-	buildExecuted bool
-
-	// Mark 'this' attribute for projection
-	projection bool
-
-	// Name of the dynamo attribute as defined in DB
-	name string
-
-	// Document path of this attribute
-	documentPath string
-
-	// Use this to build any condition on 'this' attribute
-	nameBuilder expression.NameBuilder
-
-	// Represents all the conditions applied on 'this' dynamo attribute
-	conditionBuilder *expression.ConditionBuilder
+	// Node of 'this' attribute in the expression builder tree
+	// holding its name, document path, marks and list items
+	node *core.Node
 
 	// Helps in direct member selection of child attributes of list item
 	// This will also be used in creating new list item
 	listItemAccessReference T
-
-	// Determines the operation which needs to performed on
-	// 'this' attribute
-	operation DynamoOperation
-
-	// Represent the value which needs to be assigned to 'this'
-	// attribute for update
-	value any
-
-	// Defines the order of index added, this helps in maintaining
-	// order when generating projection
-	orderOfListItems []int
-
-	// List of item of 'this' list attribute
-	listAttributes map[int]interface{}
 }
 
 func NewDynamoListAttribute[T any]() *DynamoListAttribute[T] {
-	return &DynamoListAttribute[T]{
-		listAttributes: map[int]interface{}{},
+	dla := &DynamoListAttribute[T]{node: core.NewNode(core.NODE_LIST)}
+	dla.node.SetOwner(dla)
+	dla.node.SetListItemFactory(func(index int) *core.Node {
+		// owner is the copy of `dla` held by the expression builder of the parent
+		return dla.node.Owner().(*DynamoListAttribute[T]).newListItem(index)
+	})
+	if _, ok := interface{}(dla.listItemAccessReference).(TreeBuilder[T]); ok {
+		dla.node.SetMapListItems()
 	}
+	return dla
 }
 
 // WithName builds `this` DynamoListAttribute with a dynamo db attribute name
 func (dla *DynamoListAttribute[T]) WithName(name string) *DynamoListAttribute[T] {
-	dla.name = name
+	dla.node.SetName(name)
 	return dla
 }
 
@@ -81,20 +53,17 @@ func (dla *DynamoListAttribute[T]) WithListItemAccessReference(listItemAccessRef
 
 // Project marks `this` attribute for projection
 func (dla *DynamoListAttribute[T]) Project() error {
-	if !dla.buildExecuted {
-		return errors.New("build is not yet executed on attribute [" + dla.name + "], cannot mark this attribute for projection")
-	}
-
-	dla.projection = true
-	return nil
+	defer dla.node.LockTree()()
+	return dla.node.Project()
 }
 
 func (dla *DynamoListAttribute[T]) GetName() string {
-	return dla.name
+	return dla.node.Name()
 }
 
 func (dla *DynamoListAttribute[T]) GetNameBuilder() expression.NameBuilder {
-	return dla.nameBuilder
+	defer dla.node.RLockTree()()
+	return nameBuilder(dla.node)
 }
 
 // AR returns the type `T` held by this node
@@ -105,182 +74,126 @@ func (dla *DynamoListAttribute[T]) AR() T {
 
 // AddListItem add a node in the list
 func (dla *DynamoListAttribute[T]) AddListItem(listItemsIndex ...int) error {
-	if dla.buildExecuted {
-		return errors.New("build is already executed on attribute " + dla.documentPath)
-	}
+	defer dla.node.LockTree()()
+	return dla.node.AddListItem(listItemsIndex...)
+}
 
-	// currently, we are not allowing nested lists
-	for _, index := range listItemsIndex {
-		dla.orderOfListItems = append(dla.orderOfListItems, index)
-		switch listItemType := interface{}(dla.listItemAccessReference).(type) {
-		case TreeBuilder[T]:
-			dla.listAttributes[index] = listItemType.BuildTree("[" + strconv.Itoa(int(index)) + "]")
-		default: // it's a primitive
-			dla.listAttributes[index] = NewDynamoAttribute[T]().WithName("[" + strconv.Itoa(int(index)) + "]")
-		}
+// newListItem creates the node of list item at `index`
+func (dla *DynamoListAttribute[T]) newListItem(index int) *core.Node {
+	switch listItemType := interface{}(dla.listItemAccessReference).(type) {
+	case TreeBuilder[T]:
+		return listItemType.BuildTree(core.ListItemName(index)).node
+	default: // it's a primitive
+		return NewDynamoAttribute[T]().WithName(core.ListItemName(index)).node
 	}
+}
 
-	return nil
+// AddListItemRange adds the list items from index `from` up to but excluding `to`, list items
+// which are already part of the list are kept as they are
+func (dla *DynamoListAttribute[T]) AddListItemRange(from, to int) error {
+	defer dla.node.LockTree()()
+	return dla.node.AddListItemRange(from, to)
 }
 
+// Index returns the list item at `listAttributeIndex`, it is added to the list if not present.
+// For a negative index the list item returned is not part of the list, its marks are ignored and
+// building the expression builder tree fails with an error wrapping ErrUnknownListIndex. Use
+// AddListItem or AddListItemRange to get the error right away
 func (dla *DynamoListAttribute[T]) Index(listAttributeIndex int) *DynamoAttribute[T] {
+	defer dla.node.LockTree()()
 	// currently, we are limiting the type to DynamoAttribute
-	if listAttribute, ok := dla.listAttributes[listAttributeIndex].(*DynamoAttribute[T]); !ok {
-		return nil
-	} else {
-		return listAttribute
-	}
+	listItem := dla.node.IndexListItem(listAttributeIndex)
+	listAttribute, _ := listItem.Owner().(*DynamoAttribute[T])
+	return listAttribute
+}
+
+// ForEachIndex calls `fn` for every list item in the order they were added, e.g. to apply the
+// same projection, condition or update to the list items added by AddListItemRange. Errors
+// returned by `fn` are joined
+//
+// In concurrency mode the tree is not locked while `fn` is called, so `fn` can mark the list items
+func (dla *DynamoListAttribute[T]) ForEachIndex(fn func(index int, listItem *DynamoAttribute[T]) error) error {
+	return dla.node.ForEachListItem(func(index int, listItem *core.Node) error {
+		listAttribute, _ := listItem.Owner().(*DynamoAttribute[T])
+		return fn(index, listAttribute)
+	})
 }
 
 // NOTE: conditionBuilder represent any valid condition, zero value of struct `ConditionBuilder`
 // might give build error
 func (dla *DynamoListAttribute[T]) AndWithCondition() func(conditionBuilder expression.ConditionBuilder) {
 	return func(conditionBuilder expression.ConditionBuilder) {
-		if dla.conditionBuilder != nil {
-			dla.conditionBuilder = utils.PointerTo((*dla.conditionBuilder).And(conditionBuilder))
-		} else {
-			dla.conditionBuilder = &conditionBuilder
-		}
+		defer dla.node.LockTree()()
+		dla.node.AndWithCondition(conditionBuilder)
 	}
 }
 
-func (dla *DynamoListAttribute[T]) AddValue(operation DynamoOperation, value any) {
-	dla.operation = operation
-	dla.value = value
-}
-
-func (dla *DynamoListAttribute[T]) constructDocumentPath(documentPathOfParent string) string {
-	if strings.HasSuffix(dla.GetName(), "]") { // this is a top level element of a list, we can skip DDBAtributeNameCancatenator
-		return documentPathOfParent + dla.GetName()
+// AndWithFilter adds a new filter to `this` attributes existing filters using `AND`, see
+// DynamoAttribute.AndWithFilter
+func (dla *DynamoListAttribute[T]) AndWithFilter() func(filterBuilder expression.ConditionBuilder) {
+	return func(filterBuilder expression.ConditionBuilder) {
+		defer dla.node.LockTree()()
+		dla.node.AndWithFilter(filterBuilder)
 	}
-
-	if documentPathOfParent == "" { // this is root's child
-		return dla.GetName()
-	}
-
-	return documentPathOfParent + DDBAtributeNameCancatenator + dla.GetName()
 }
 
-func (dla *DynamoListAttribute[T]) build(parentDocumentPath string) error {
-	if dla.buildExecuted {
-		return errors.New("build is already executed on attribute " + dla.documentPath)
-	}
-
-	// build document path
-	dla.documentPath = dla.constructDocumentPath(parentDocumentPath)
-
-	// build name builder
-	dla.nameBuilder = expression.Name(dla.documentPath)
-
-	for _, listItem := range dla.listAttributes {
-		switch listItemType := listItem.(type) {
-		case Builder:
-			listItemType.build(dla.documentPath)
-		}
-	}
-
-	dla.buildExecuted = true
-	return nil
+func (dla *DynamoListAttribute[T]) AddValue(operation DynamoOperation, value any) {
+	defer dla.node.LockTree()()
+	dla.node.AddValue(core.Operation(operation), value)
 }
 
-func (dla *DynamoListAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (newProjectionBuilder *expression.ProjectionBuilder, err error) {
-	if !dla.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + dla.name + "], cannot mark this attribute for projection")
-	}
-
-	if projectionBuilder == nil {
-		return nil, errors.New("nil projection builder passed for attribute " + dla.documentPath)
-	}
-
-	newProjectionBuilder = projectionBuilder
-	if dla.projection { // skipping projection of child attributes
-		return utils.PointerTo(newProjectionBuilder.AddNames(expression.Name(dla.documentPath))), nil
-	} else {
-		for _, listAttributeIndex := range dla.orderOfListItems {
-			listItem := dla.listAttributes[listAttributeIndex]
-			switch listItemType := listItem.(type) {
-			case Projector:
-				newProjectionBuilder, err = listItemType.addName(newProjectionBuilder)
-			}
-
-			if err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	return newProjectionBuilder, nil
+// Append marks `this` list for update by adding `items` to its end using list_append. Items of
+// later calls are added after the items of earlier ones and compose with the items of Prepend as
+// `list_append(:prepended, list_append(list, :appended))`, any other value marked for update on
+// `this` list is replaced
+//
+// NOTE: T of a list of maps is the expression builder of the map which can't be used as value,
+// ErrMapListItemValue is returned for such a list. Mark it using AddValue instead
+func (dla *DynamoListAttribute[T]) Append(items ...T) error {
+	defer dla.node.LockTree()()
+	return dla.node.AppendListItems(core.ListItemValues(items), false)
 }
 
-func (dla *DynamoListAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) (newConditionBuilder *expression.ConditionBuilder) {
-	if dla.conditionBuilder != nil {
-		if conditionBuilder != nil {
-			newConditionBuilder = utils.PointerTo(conditionBuilder.And(*dla.conditionBuilder))
-		} else {
-			newConditionBuilder = dla.conditionBuilder
-		}
-	} else {
-		newConditionBuilder = conditionBuilder
-	}
-
-	for _, listAttributeIndex := range dla.orderOfListItems {
-		listItem := dla.listAttributes[listAttributeIndex]
-		switch listItemType := listItem.(type) {
-		case Conditioner:
-			if retConditionBuilder := listItemType.addCondition(newConditionBuilder); retConditionBuilder != nil {
-				newConditionBuilder = retConditionBuilder
-			}
-		}
-	}
+// Prepend marks `this` list for update by adding `items` to its start using list_append, see
+// Append
+func (dla *DynamoListAttribute[T]) Prepend(items ...T) error {
+	defer dla.node.LockTree()()
+	return dla.node.AppendListItems(core.ListItemValues(items), true)
+}
 
-	return newConditionBuilder
+// RemoveAt marks the list items at `indices` for UPDATE_REMOVE, the list items are added to the
+// list if not present. Every index is removed once and the indices are removed in order
+func (dla *DynamoListAttribute[T]) RemoveAt(indices ...int) error {
+	defer dla.node.LockTree()()
+	return dla.node.RemoveListItems(indices...)
 }
 
-func (dla *DynamoListAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (newUpdateBuilder *expression.UpdateBuilder, err error) {
-	if !dla.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + dla.name + "], cannot update this list attribute")
-	}
+// SetAt marks the list item at `index` for UPDATE_SET with `value`, the list item is added to the
+// list if not present. ErrMapListItemValue is returned for a list of maps, see Append
+func (dla *DynamoListAttribute[T]) SetAt(index int, value T) error {
+	defer dla.node.LockTree()()
+	return dla.node.SetListItem(index, value)
+}
 
-	if updateBuilder == nil {
-		return nil, errors.New("nil update builder passed for attribute " + dla.documentPath)
-	}
+func (dla *DynamoListAttribute[T]) coreNode() *core.Node {
+	return dla.node
+}
 
-	newUpdateBuilder = updateBuilder
-	valueBuilder := expression.Value(dla.value)
+func (dla *DynamoListAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	return addNames(dla.node, projectionBuilder)
+}
 
-	switch dla.operation {
-	case UPDATE_SET:
-		switch valueType := interface{}(dla.value).(type) {
-		case expression.SetValueBuilder: // this is when we want to perform list_append
-			newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Set(dla.nameBuilder, valueType))
-		default:
-			newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Set(dla.nameBuilder, valueBuilder))
-		}
-	case UPDATE_REMOVE:
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Remove(dla.nameBuilder))
-	case UPDATE_ADD: // for numbers and set data structure
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Add(dla.nameBuilder, valueBuilder))
-	case UPDATE_DELETE: // for set data structure only
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Delete(dla.nameBuilder, valueBuilder))
-	case NO_OP:
-		for _, listAttributeIndex := range dla.orderOfListItems {
-			listItem := dla.listAttributes[listAttributeIndex]
-			switch listItemType := listItem.(type) {
-			case Updater:
-				if retUpdateBuilder, err := listItemType.addUpdate(newUpdateBuilder); err != nil {
-					return nil, err
-				} else if retUpdateBuilder != nil {
-					newUpdateBuilder = retUpdateBuilder
-				}
-			}
-		}
-	}
+func (dla *DynamoListAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return addConditions(dla.node, conditionBuilder)
+}
 
-	return newUpdateBuilder, nil
+func (dla *DynamoListAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (*expression.UpdateBuilder, error) {
+	return addUpdates(dla.node, updateBuilder)
 }
 
 func NewDDBItemExpressionBuilder[T TreeBuilder[T]](treeBuilder T) DDBItemExpressionBuilder[T] {
 	return DDBItemExpressionBuilder[T]{
-		root: treeBuilder.BuildTree(""),
+		root:      treeBuilder.BuildTree(""),
+		slotScope: core.NewSlotScope(),
 	}
 }
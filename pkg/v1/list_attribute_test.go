@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that list items are added on demand and marked index by index
+func TestListItemRange(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	children := expBuilder.DDBItemRoot().AR().FamilyDetails.AR().Children
+	expBuilder.Build()
+
+	// a negative index isn't added to the list and fails every build until the tree is reset
+	children.Index(-1).AddValue(UPDATE_REMOVE, nil)
+	_, err := expBuilder.BuildUpdateBuilder()
+	assert.ErrorIs(t, err, ErrUnknownListIndex)
+	assert.EqualError(t, err, "cannot add list item [-1] to attribute [family_details.children]: unknown list index")
+	_, err = expBuilder.BuildProjectionBuilder()
+	assert.ErrorIs(t, err, ErrUnknownListIndex)
+	_, err = expBuilder.Explain()
+	assert.ErrorIs(t, err, ErrUnknownListIndex)
+	expBuilder.Reset()
+	_, err = expBuilder.BuildUpdateBuilder()
+	assert.Nil(t, err)
+
+	// list items are added after build
+	child := children.Index(4)
+	if assert.NotNil(t, child) {
+		assert.Equal(t, "family_details.children[4]", child.DocumentPath())
+	}
+	assert.Same(t, child, children.Index(4))
+
+	assert.ErrorIs(t, children.AddListItemRange(-1, 1), ErrUnknownListIndex)
+	if err := children.AddListItemRange(0, 3); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	indices := []int{}
+	err = children.ForEachIndex(func(index int, listItem *DynamoAttribute[*Child_ExpressionBuilder]) error {
+		indices = append(indices, index)
+		if index == 4 {
+			return nil
+		}
+
+		listItem.AddValue(UPDATE_REMOVE, nil)
+		return errors.New("removed " + listItem.DocumentPath())
+	})
+	assert.Equal(t, []int{4, 0, 1, 2}, indices)
+	assert.EqualError(t, err, "removed family_details.children[0]\nremoved family_details.children[1]\nremoved family_details.children[2]")
+
+	updates := []string{}
+	Walk(&children, func(node Node) error {
+		if node.Marks().Operation == UPDATE_REMOVE {
+			updates = append(updates, node.DocumentPath())
+		}
+		return nil
+	})
+	assert.Equal(t, []string{"family_details.children[0]", "family_details.children[1]", "family_details.children[2]"}, updates)
+}
+
+// Testing that list operations generate list_append and REMOVE of sorted, unique indices while
+// the other list item updates keep the order the list items were added in
+func TestListOperations(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	phoneNos := expBuilder.DDBItemRoot().AR().PhoneNos
+	first, second, third := "1", "2", "3"
+
+	assert.Nil(t, phoneNos.Append(&first))
+	assert.Nil(t, phoneNos.Append(&second))
+	expr, err := buildUpdate(expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "SET #0 = list_append(#0, :0)\n", *expr.Update())
+	assert.Len(t, expr.Values()[":0"].L, 2)
+
+	// prepend composes with the appended items
+	assert.Nil(t, phoneNos.Prepend(&third))
+	expr, _ = buildUpdate(expBuilder)
+	assert.Equal(t, "SET #0 = list_append(:0, list_append(#0, :1))\n", *expr.Update())
+	assert.Len(t, expr.Values()[":0"].L, 1)
+	assert.Len(t, expr.Values()[":1"].L, 2)
+
+	expBuilder.Reset()
+	phoneNos.Index(5)
+	assert.ErrorIs(t, phoneNos.RemoveAt(-1), ErrUnknownListIndex)
+	if err := phoneNos.RemoveAt(3, 1, 5, 3); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if err := phoneNos.SetAt(2, &first); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	phoneNos.Index(0).AddValue(UPDATE_SET, &second)
+	expr, _ = buildUpdate(expBuilder)
+	assert.Equal(t, "REMOVE #0[1], #0[3], #0[5]\nSET #0[2] = :0, #0[0] = :1\n", *expr.Update())
+
+	// a list item removed by RemoveAt and then set is updated in the order it was added
+	if err := phoneNos.SetAt(5, &third); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	expr, _ = buildUpdate(expBuilder)
+	assert.Equal(t, "REMOVE #0[1], #0[3]\nSET #0[5] = :0, #0[2] = :1, #0[0] = :2\n", *expr.Update())
+}
+
+// Testing that the expression builder of a list of maps can't be used as value of its list items
+func TestMapListOperations(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	children := expBuilder.DDBItemRoot().AR().FamilyDetails.AR().Children
+
+	err := children.Append(children.AR())
+	assert.ErrorIs(t, err, ErrMapListItemValue)
+	assert.EqualError(t, err, "cannot append to attribute [family_details.children]: expression builder cannot be used as value of a list item")
+	assert.ErrorIs(t, children.Prepend(children.AR()), ErrMapListItemValue)
+	assert.ErrorIs(t, children.SetAt(0, children.AR()), ErrMapListItemValue)
+	assert.Empty(t, children.Children())
+
+	// list items of a list of maps are removed or marked on their attributes
+	if err := children.RemoveAt(2, 0); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	children.Index(1).AR().Name.AddValue(UPDATE_SET, "name")
+	expr, err := buildUpdate(expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "REMOVE #0.#1[0], #0.#1[2]\nSET #0.#1[1].#2 = :0\n", *expr.Update())
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that parsed expressions are marked on the expression builder tree
+func TestParseExpression(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+
+	names := map[string]*string{
+		"#pk":       aws.String("pk"),
+		"#sk":       aws.String("sk"),
+		"#name":     aws.String("name"),
+		"#family":   aws.String("family_details"),
+		"#children": aws.String("children"),
+		"#married":  aws.String("is_married"),
+		"#phones":   aws.String("phone_nos"),
+		"#bank":     aws.String("bank_details"),
+		"#accounts": aws.String("accounts"),
+		"#number":   aws.String("bank_account_number"),
+	}
+	values := map[string]*dynamodb.AttributeValue{
+		":pk":     {S: aws.String("person#1")},
+		":prefix": {S: aws.String("profile#")},
+		":name":   {S: aws.String("John")},
+		":true":   {BOOL: aws.Bool(true)},
+		":one":    {N: aws.String("1")},
+		":phones": {L: []*dynamodb.AttributeValue{{S: aws.String("123")}}},
+	}
+
+	for _, parsed := range []struct {
+		kind ExpressionKind
+		expr string
+	}{
+		{EXPRESSION_KEY_CONDITION, "#pk = :pk AND begins_with(#sk, :prefix)"},
+		{EXPRESSION_CONDITION, "attribute_exists(#pk) AND (#name = :name OR #family.#married = :true) AND size(#phones) > :one"},
+		{EXPRESSION_PROJECTION, "#name, #family.#children[1].#name"},
+		{EXPRESSION_UPDATE, "SET #phones = list_append(#phones, :phones), #bank.#accounts[2].#number = if_not_exists(#bank.#accounts[2].#number, :one) + :one REMOVE #family.#married"},
+	} {
+		if err := expBuilder.ParseExpression(parsed.kind, parsed.expr, names, values); err != nil {
+			t.Errorf(err.Error())
+			return
+		}
+	}
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, strings.Join([]string{
+		`KeyCondition: (pk = "person#1") AND (begins_with (sk, "profile#"))`,
+		`Condition: ((attribute_exists (pk)) AND ((name = "John") OR (family_details.is_married = true))) AND (size (phone_nos) > 1)`,
+		`Projection: pk, sk, name, family_details.children[1].name`,
+		`Update: REMOVE family_details.is_married SET bank_details.accounts[2].bank_account_number = if_not_exists(bank_details.accounts[2].bank_account_number, 1) + 1, phone_nos = list_append(phone_nos, ["123"])`,
+	}, "\n"), explanation)
+}
+
+// Testing that unknown document paths are reported without changing the tree
+func TestParseExpressionUnknownPaths(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+
+	names := map[string]*string{"#name": aws.String("name"), "#age": aws.String("age"), "#family": aws.String("family_details")}
+	values := map[string]*dynamodb.AttributeValue{":age": {N: aws.String("30")}}
+
+	err := expBuilder.ParseExpression(EXPRESSION_UPDATE, "SET #age = :age, #name = #family.#age", names, values)
+	var unknownPathsError *UnknownPathsError
+	if assert.True(t, errors.As(err, &unknownPathsError)) {
+		assert.Equal(t, []string{"age", "family_details.age"}, unknownPathsError.Paths)
+	}
+
+	explanation, err := expBuilder.Explain()
+	assert.Nil(t, err)
+	assert.Equal(t, "Projection: pk, sk", explanation)
+
+	// key attributes can't be updated and other attributes can't be used in key condition
+	assert.NotNil(t, expBuilder.ParseExpression(EXPRESSION_UPDATE, "SET #pk = :age", map[string]*string{"#pk": aws.String("pk")}, values))
+	assert.NotNil(t, expBuilder.ParseExpression(EXPRESSION_KEY_CONDITION, "#name = :age", names, values))
+}
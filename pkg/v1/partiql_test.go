@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing PartiQL statements rendered from the expression builder tree
+func TestBuildPartiQL(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.PhoneNos.AddListItem(1)
+	rootExpBldr.BankDetails.AR().Accounts.AddListItem(3)
+	expBuilder.Build()
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value("person#1")))
+	rootExpBldr.SK.AndWithCondition()(rootExpBldr.SK.GetKeyBuilder().Equal(expression.Value("details")))
+	rootExpBldr.Name.Project()
+	rootExpBldr.FamilyDetails.AR().IsMarried.Project()
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().In(expression.Value("Name1"), expression.Value("Name2")))
+	rootExpBldr.FamilyDetails.AR().IsMarried.AndWithCondition()(rootExpBldr.FamilyDetails.AR().IsMarried.GetNameBuilder().AttributeExists())
+	// filters are part of the WHERE clause of SELECT only
+	rootExpBldr.Name.AndWithFilter()(rootExpBldr.Name.GetNameBuilder().NotEqual(expression.Value("Name3")))
+
+	statement, params, err := expBuilder.BuildPartiQL(PARTIQL_SELECT, "persons")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, `SELECT "pk", "sk", "name", "family_details"."is_married" FROM "persons" WHERE `+
+		`(("pk" = ?) AND ("sk" = ?)) AND (("name" IN [?, ?]) AND (("family_details"."is_married" IS NOT MISSING))) AND ("name" <> ?)`, statement)
+	assert.Equal(t, []*dynamodb.AttributeValue{
+		{S: aws.String("person#1")},
+		{S: aws.String("details")},
+		{S: aws.String("Name1")},
+		{S: aws.String("Name2")},
+		{S: aws.String("Name3")},
+	}, params)
+
+	rootExpBldr.Name.AddValue(UPDATE_SET, utils.PointerTo("New Name"))
+	rootExpBldr.BankDetails.AR().Accounts.Index(3).AR().BankAccountNumber.AddValue(UPDATE_ADD, 2)
+	rootExpBldr.PhoneNos.Index(1).AddValue(UPDATE_REMOVE, nil)
+	_, _, err = expBuilder.BuildPartiQL(PARTIQL_UPDATE, "persons")
+	assert.ErrorIs(t, err, ErrUnsupportedPartiQL)
+
+	rootExpBldr.BankDetails.AR().Accounts.Index(3).AR().BankAccountNumber.AddValue(UPDATE_SET, 2)
+	statement, params, err = expBuilder.BuildPartiQL(PARTIQL_UPDATE, "persons")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, `UPDATE "persons" REMOVE "phone_nos"[1] SET "name" = ? `+
+		`SET "bank_details"."accounts"[3]."bank_account_number" = ? WHERE `+
+		`(("pk" = ?) AND ("sk" = ?)) AND (("name" IN [?, ?]) AND (("family_details"."is_married" IS NOT MISSING)))`, statement)
+	assert.Equal(t, 6, len(params))
+	assert.Equal(t, &dynamodb.AttributeValue{S: aws.String("New Name")}, params[0])
+	assert.Equal(t, &dynamodb.AttributeValue{N: aws.String("2")}, params[1])
+
+	// only key condition
+	transactionExpBuilder := NewTransaction_ExpressionBuilder()
+	transactionExpBuilder.Build()
+	transactionRoot := transactionExpBuilder.DDBItemRoot().AR()
+	transactionRoot.UserID.AndWithCondition()(transactionRoot.UserID.GetKeyBuilder().Equal(expression.Value("u1")))
+	transactionRoot.TransactionID.AndWithCondition()(transactionRoot.TransactionID.GetKeyBuilder().Equal(expression.Value("t1")))
+	statement, params, err = transactionExpBuilder.BuildPartiQL(PARTIQL_DELETE, "transactions")
+	assert.Nil(t, err)
+	assert.Equal(t, `DELETE FROM "transactions" WHERE ("user_id" = ?) AND ("transaction_id" = ?)`, statement)
+	assert.Equal(t, []*dynamodb.AttributeValue{{S: aws.String("u1")}, {S: aws.String("t1")}}, params)
+
+	// delete without WHERE clause
+	transactionExpBuilder = NewTransaction_ExpressionBuilder()
+	transactionExpBuilder.Build()
+	_, _, err = transactionExpBuilder.BuildPartiQL(PARTIQL_DELETE, "transactions")
+	assert.ErrorContains(t, err, "DELETE statement requires a key condition or condition")
+}
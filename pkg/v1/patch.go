@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"github.com/gauxs/dynexpr/internal/core"
+)
+
+type PatchOption = core.PatchOption
+
+var (
+	// WithNull sets the attributes at document paths `paths` to null, e.g. "name" or
+	// "family_details.is_married", whatever their field in the patch holds. Fields left
+	// unchanged can't express an explicit null, so nulls are given by their document paths
+	// instead
+	WithNull = core.WithNull
+
+	// WithNullAsRemove removes the attributes of WithNull instead of setting them to null
+	WithNullAsRemove = core.WithNullAsRemove
+
+	// WithReplaceNested sets the nested structs of the patch as a whole, replacing the map
+	// stored in dynamo db. By default nested structs are patched field by field
+	WithReplaceNested = core.WithReplaceNested
+)
+
+// ApplyPatch walks this expression builder tree alongside `patch` and marks UPDATE_SET on the
+// attributes whose field in the patch is set, generally patch is a pointer to the struct
+// representing a single item of dynamo db
+//
+// Nil pointers, slices and maps, and zero values of other types, are left unchanged, i.e. a
+// field which isn't a pointer can't set its attribute to the zero value of its type, e.g. 0 or
+// false, use a pointer field for such attributes. Attributes of WithNull are set to null or
+// removed with WithNullAsRemove. Lists are always set as a whole. Key attributes are ignored
+// since they identify the item, except in WithNull where they return an error. On error the
+// tree is left unchanged
+func (d DDBItemExpressionBuilder[T]) ApplyPatch(patch any, opts ...PatchOption) error {
+	defer d.root.node.LockTree()()
+	return d.root.node.Patch(patch, opts...)
+}
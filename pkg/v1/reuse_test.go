@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that a reset tree can be built and marked again, and clones are independent of the tree
+func TestResetAndClone(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder().WithValidation()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.BankDetails.AR().Accounts.AddListItem(1)
+	expBuilder.Build()
+
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value("person#1")))
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().AttributeExists())
+	rootExpBldr.BankDetails.AR().Accounts.Index(1).AR().BankAccountNumber.AddValue(UPDATE_ADD, 1)
+	rootExpBldr.FamilyDetails.Project()
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	clone := expBuilder.Clone()
+	assert.True(t, clone.validation)
+	cloneExplanation, err := clone.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, explanation, cloneExplanation)
+
+	// values are deep copied
+	name := "John"
+	rootExpBldr.FamilyDetails.AR().IsMarried.AddValue(UPDATE_SET, &name)
+	clone = expBuilder.Clone()
+	name = "Jane"
+	cloneName := clone.DDBItemRoot().AR().FamilyDetails.AR().IsMarried.Marks().Value.(*string)
+	assert.Equal(t, "John", *cloneName)
+	rootExpBldr.FamilyDetails.AR().IsMarried.AddValue(NO_OP, nil)
+
+	// marks of the clone don't change the tree
+	clone.DDBItemRoot().AR().Name.AddValue(UPDATE_SET, "John")
+	assert.Len(t, clone.DDBItemRoot().AR().BankDetails.AR().Accounts.Children(), 1)
+	newExplanation, _ := expBuilder.Explain()
+	assert.Equal(t, explanation, newExplanation)
+
+	expBuilder.Reset()
+	assert.Empty(t, rootExpBldr.BankDetails.AR().Accounts.Children())
+	assert.Nil(t, rootExpBldr.BankDetails.AR().Accounts.AddListItem(2))
+	assert.Nil(t, expBuilder.Build())
+	rootExpBldr.BankDetails.AR().Accounts.Index(2).AR().AccountType.Project()
+
+	explanation, err = expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "Projection: pk, sk, bank_details.accounts[2].account_type", explanation)
+}
+
+// Testing that trees put back into the pool are reset and keep their options
+func TestDDBItemExpressionBuilderPool(t *testing.T) {
+	pool := NewDDBItemExpressionBuilderPool(&Person_ExpressionBuilder{})
+	expBuilder := pool.Get().WithValidation().WithMarkMode(MARK_MODE_STRICT).WithConcurrency()
+	root := expBuilder.root
+	expBuilder.DDBItemRoot().AR().PhoneNos.AddListItem(1)
+	expBuilder.Build()
+	expBuilder.DDBItemRoot().AR().Name.AddValue(UPDATE_SET, "John")
+	pool.Put(expBuilder)
+
+	// the pool may drop what was put back, options are kept only by the same tree
+	expBuilder = pool.Get()
+	if expBuilder.root == root {
+		assert.True(t, expBuilder.validation)
+		assert.Equal(t, MARK_MODE_STRICT, expBuilder.markMode)
+		assert.NotNil(t, expBuilder.root.node.Lock())
+	}
+	assert.Nil(t, expBuilder.Build())
+	assert.Empty(t, expBuilder.DDBItemRoot().AR().PhoneNos.Children())
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "Projection: pk, sk", explanation)
+}
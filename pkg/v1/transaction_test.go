@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// ------------------------------------------ TRANSACTION MODEL ------------------------------------------
+type Transaction struct {
+	UserID        *string `json:"user_id,omitempty" dynamodbav:"user_id,omitempty"`
+	TransactionID *string `json:"transaction_id,omitempty" dynamodbav:"transaction_id,omitempty"`
+	Amount        *int    `json:"amount,omitempty" dynamodbav:"amount,omitempty"`
+}
+
+// ------------------------------------- EXPRESSION BUILDER FOR TRANSACTION MODEL -------------------------------------
+type Transaction_ExpressionBuilder struct {
+	UserID        DynamoKeyAttribute[*string]
+	TransactionID DynamoKeyAttribute[*string]
+	Amount        DynamoAttribute[*int]
+}
+
+func (transactionExpBldr *Transaction_ExpressionBuilder) BuildTree(name string) *DynamoAttribute[*Transaction_ExpressionBuilder] {
+	transactionExpBldr = &Transaction_ExpressionBuilder{}
+	transactionExpBldr.UserID = *NewDynamoKeyAttribute[*string]().WithName("user_id")
+	transactionExpBldr.TransactionID = *NewDynamoKeyAttribute[*string]().WithName("transaction_id")
+	transactionExpBldr.Amount = *NewDynamoAttribute[*int]().WithName("amount")
+	return NewDynamoAttribute[*Transaction_ExpressionBuilder]().
+		WithAccessReference(transactionExpBldr).
+		WithName(name).
+		WithChildAttribute(&transactionExpBldr.UserID).
+		WithChildAttribute(&transactionExpBldr.TransactionID).
+		WithChildAttribute(&transactionExpBldr.Amount)
+}
+
+func NewTransaction_ExpressionBuilder() DDBItemExpressionBuilder[*Transaction_ExpressionBuilder] {
+	return NewDDBItemExpressionBuilder(&Transaction_ExpressionBuilder{})
+}
+
+// -------------------------------------------------------------------------------------------------------------
+
+// Testing transaction of DDB items of different types
+func TestTransactionBuilder(t *testing.T) {
+	// update a person only if it exists
+	personExpBuilder := NewPerson_ExpressionBuilder()
+	personExpBuilder.Build()
+	personRoot := personExpBuilder.DDBItemRoot().AR()
+	personRoot.Name.AddValue(UPDATE_SET, "New Name")
+	personRoot.Name.AndWithCondition()(personRoot.Name.GetNameBuilder().AttributeExists())
+
+	// insert a transaction only if it doesn't exist
+	transactionExpBuilder := NewTransaction_ExpressionBuilder()
+	transactionExpBuilder.Build()
+	transactionRoot := transactionExpBuilder.DDBItemRoot().AR()
+	transactionRoot.Amount.AndWithCondition()(transactionRoot.Amount.GetNameBuilder().AttributeNotExists())
+
+	// check balance of the user
+	balanceExpBuilder := NewTransaction_ExpressionBuilder()
+	balanceExpBuilder.Build()
+	balanceRoot := balanceExpBuilder.DDBItemRoot().AR()
+	balanceRoot.Amount.AndWithCondition()(balanceRoot.Amount.GetNameBuilder().GreaterThanEqual(expression.Value(100)))
+
+	input, err := NewTransactionBuilder().
+		WithItem(TRANSACT_UPDATE, "persons", Person{PK: utils.PointerTo("person#1"), SK: utils.PointerTo("details")}, personExpBuilder).
+		WithItem(TRANSACT_PUT, "transactions", Transaction{
+			UserID:        utils.PointerTo("person#1"),
+			TransactionID: utils.PointerTo("transaction#1"),
+			Amount:        utils.PointerTo(100),
+		}, transactionExpBuilder).
+		WithItem(TRANSACT_CONDITION_CHECK, "transactions", map[string]*dynamodb.AttributeValue{
+			"user_id":        {S: aws.String("person#1")},
+			"transaction_id": {S: aws.String("balance")},
+		}, balanceExpBuilder).
+		Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expectedInput := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					TableName: aws.String("persons"),
+					Key: map[string]*dynamodb.AttributeValue{
+						"pk": {S: aws.String("person#1")},
+						"sk": {S: aws.String("details")},
+					},
+					UpdateExpression:          aws.String("SET #0 = :0\n"),
+					ConditionExpression:       aws.String("attribute_exists (#0)"),
+					ExpressionAttributeNames:  map[string]*string{"#0": aws.String("name")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":0": {S: aws.String("New Name")}},
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String("transactions"),
+					Item: map[string]*dynamodb.AttributeValue{
+						"user_id":        {S: aws.String("person#1")},
+						"transaction_id": {S: aws.String("transaction#1")},
+						"amount":         {N: aws.String("100")},
+					},
+					ConditionExpression:      aws.String("attribute_not_exists (#0)"),
+					ExpressionAttributeNames: map[string]*string{"#0": aws.String("amount")},
+				},
+			},
+			{
+				ConditionCheck: &dynamodb.ConditionCheck{
+					TableName: aws.String("transactions"),
+					Key: map[string]*dynamodb.AttributeValue{
+						"user_id":        {S: aws.String("person#1")},
+						"transaction_id": {S: aws.String("balance")},
+					},
+					ConditionExpression:       aws.String("#0 >= :0"),
+					ExpressionAttributeNames:  map[string]*string{"#0": aws.String("amount")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":0": {N: aws.String("100")}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expectedInput, input)
+}
+
+// Testing validations of transaction builder
+func TestTransactionBuilderValidation(t *testing.T) {
+	expBuilder := NewTransaction_ExpressionBuilder()
+	expBuilder.Build()
+
+	// two operations on the same item
+	_, err := NewTransactionBuilder().
+		WithItem(TRANSACT_DELETE, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		WithItem(TRANSACT_PUT, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1"), Amount: utils.PointerTo(1)}, expBuilder).
+		Build()
+	assert.ErrorContains(t, err, "item 0 and 1 of transaction target the same item")
+
+	// same key but different tables
+	_, err = NewTransactionBuilder().
+		WithItem(TRANSACT_DELETE, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		WithItem(TRANSACT_DELETE, "archived_transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		Build()
+	assert.Nil(t, err)
+
+	// key attribute missing
+	_, err = NewTransactionBuilder().
+		WithItem(TRANSACT_DELETE, "transactions", Transaction{UserID: utils.PointerTo("u1")}, expBuilder).
+		Build()
+	assert.ErrorContains(t, err, "value of key attribute [transaction_id] is missing")
+
+	// condition check without condition
+	_, err = NewTransactionBuilder().
+		WithItem(TRANSACT_CONDITION_CHECK, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		Build()
+	assert.ErrorContains(t, err, "condition check requires a condition")
+
+	// more than allowed number of items
+	transactionBuilder := NewTransactionBuilder()
+	for idx := 0; idx <= MaxTransactWriteItems; idx++ {
+		transactionBuilder.WithItem(TRANSACT_DELETE, "transactions", Transaction{
+			UserID:        utils.PointerTo("u1"),
+			TransactionID: utils.PointerTo("t" + strconv.Itoa(idx)),
+		}, expBuilder)
+	}
+	_, err = transactionBuilder.Build()
+	assert.ErrorContains(t, err, "a transaction can have at most 100 items")
+}
@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"github.com/gauxs/dynexpr/internal/core"
+)
+
+// ValidationErrorKind is the limit exceeded by a ValidationError, kinds mirror ddbexpr.ViolationKind
+type ValidationErrorKind = core.ValidationErrorKind
+
+const (
+	VALIDATION_EXPRESSION_TOO_LONG   = core.VALIDATION_EXPRESSION_TOO_LONG
+	VALIDATION_TOO_MANY_IN_OPERANDS  = core.VALIDATION_TOO_MANY_IN_OPERANDS
+	VALIDATION_ITEM_TOO_LARGE        = core.VALIDATION_ITEM_TOO_LARGE
+	VALIDATION_OVERLAPPING_PATHS     = core.VALIDATION_OVERLAPPING_PATHS
+	VALIDATION_DUPLICATE_UPDATE_PATH = core.VALIDATION_DUPLICATE_UPDATE_PATH
+)
+
+// ValidationError is a limit of dynamo db exceeded by an expression of the expression builder tree
+type ValidationError = core.ValidationError
+
+// ValidationErrors are all the limits of dynamo db exceeded by the expression builder tree,
+// errors.As can be used to get the first *ValidationError
+type ValidationErrors = core.ValidationErrors
+
+// WithValidation returns `this` expression builder which runs Validate in every builder returning
+// an error, e.g. BuildProjectionBuilder, BuildFilterBuilder, BuildUpdateBuilder, BuildPartiQL or
+// a TransactionBuilder. BuildKeyConditionBuilder and BuildConditionBuilder don't return an error,
+// their conditions are validated by the other builders or by calling Validate
+func (d DDBItemExpressionBuilder[T]) WithValidation() DDBItemExpressionBuilder[T] {
+	d.validation = true
+	return d
+}
+
+// Validate checks the expressions marked on this expression builder tree against the limits of
+// dynamo db: expressions longer than 4KB, more than 100 operands of IN, values of an update larger
+// than an item can be, overlapping document paths of a projection or an update and document
+// paths appearing twice in an update. Every exceeded limit is returned as ValidationErrors
+//
+// Size of the item is estimated from the values assigned by the update since the stored item is
+// not known, items which are put should be checked separately
+func (d DDBItemExpressionBuilder[T]) Validate() error {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().Validate()
+	}
+
+	expr, err := d.buildExpression()
+	if err != nil {
+		return err
+	}
+
+	return core.ValidateExpression(fromExpression(expr))
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that every node of the tree is visited in order with its kind, type and marks
+func TestWalk(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.BankDetails.AR().Accounts.AddListItem(1)
+	expBuilder.Build()
+	rootExpBldr.Name.AddValue(UPDATE_SET, "John")
+	rootExpBldr.BankDetails.AR().Accounts.Index(1).AR().AccountType.Project()
+
+	visited := []string{}
+	err := Walk(expBuilder.DDBItemRoot(), func(node Node) error {
+		visited = append(visited, node.Kind().String()+" "+node.DocumentPath())
+		if node.Kind() == NODE_STRUCT && node.GetName() == "family_details" {
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, []string{
+		"STRUCT ",
+		"KEY pk",
+		"KEY sk",
+		"LEAF name",
+		"STRUCT bank_details",
+		"LIST bank_details.accounts",
+		"STRUCT bank_details.accounts[1]",
+		"LEAF bank_details.accounts[1].bank_account_number",
+		"LEAF bank_details.accounts[1].account_type",
+		"STRUCT family_details",
+		"LIST phone_nos",
+	}, visited)
+
+	assert.Equal(t, Marks{Operation: UPDATE_SET, Value: "John"}, rootExpBldr.Name.Marks())
+	assert.Equal(t, Marks{Projection: true}, rootExpBldr.BankDetails.AR().Accounts.Index(1).AR().AccountType.Marks())
+	assert.Equal(t, reflect.TypeOf((*string)(nil)), rootExpBldr.PhoneNos.Type())
+
+	// nodes returned by Children are the attributes of the expression builder
+	children := expBuilder.DDBItemRoot().Children()
+	assert.Same(t, &rootExpBldr.Name, children[2])
+}
@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that arithmetic, if_not_exists and copies of attributes are rendered as set values
+func TestSetOperations(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	accounts := expBuilder.DDBItemRoot().AR().BankDetails.AR().Accounts
+	counter := accounts.Index(0).AR().BankAccountNumber
+	zero, one, two := 0, 1, 2
+
+	if err := counter.Increment(&one); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	expr, err := buildUpdate(expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "SET #0.#1[0].#2 = #0.#1[0].#2 + :0\n", *expr.Update())
+
+	// if_not_exists is combined with the arithmetic in either order
+	counter.SetIfNotExists(&zero)
+	expr, _ = buildUpdate(expBuilder)
+	assert.Equal(t, "SET #0.#1[0].#2 = if_not_exists(#0.#1[0].#2, :0) + :1\n", *expr.Update())
+	if err := counter.Decrement(&two); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	expr, _ = buildUpdate(expBuilder)
+	assert.Equal(t, "SET #0.#1[0].#2 = if_not_exists(#0.#1[0].#2, :0) - :1\n", *expr.Update())
+
+	expBuilder.Reset()
+	counter = accounts.Index(0).AR().BankAccountNumber
+	counter.SetIfNotExists(&zero)
+	accounts.Index(1).AR().BankAccountNumber.SetFrom(&counter)
+	expr, _ = buildUpdate(expBuilder)
+	assert.Equal(t, "SET #0.#1[0].#2 = if_not_exists(#0.#1[0].#2, :0), #0.#1[1].#2 = #0.#1[0].#2\n", *expr.Update())
+	assert.Equal(t, UPDATE_SET, counter.Marks().Operation)
+	assert.NotNil(t, counter.Marks().Value)
+}
+
+// Testing that only numbers can be incremented or decremented
+func TestArithmeticRequiresNumber(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	name := expBuilder.DDBItemRoot().AR().Name
+	text := "one"
+
+	err := name.Increment(&text)
+	assert.ErrorIs(t, err, ErrNotNumber)
+	assert.EqualError(t, err, "cannot increment attribute [name]: value is not a number")
+	assert.ErrorIs(t, name.Decrement(nil), ErrNotNumber)
+	assert.Equal(t, NO_OP, name.Marks().Operation)
+}
@@ -0,0 +1,150 @@
+package v2
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing chunking, de-duplication and projection of batch get
+func TestBuildBatchGetItemInputs(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	expBuilder.DDBItemRoot().AR().Name.Project()
+	expBuilder.DDBItemRoot().AR().FamilyDetails.AR().IsMarried.Project()
+
+	keys := []Person{}
+	for idx := 0; idx < 150; idx++ {
+		keys = append(keys, Person{PK: utils.PointerTo("person#" + strconv.Itoa(idx)), SK: utils.PointerTo("details")})
+	}
+	// duplicate keys should be fetched only once
+	keys = append(keys, keys[0], keys[149])
+
+	batchGetItemInputs, err := BuildBatchGetItemInputs("persons", keys, expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, 2, len(batchGetItemInputs))
+	assert.Equal(t, MaxBatchGetItemKeys, len(batchGetItemInputs[0].RequestItems["persons"].Keys))
+	assert.Equal(t, 50, len(batchGetItemInputs[1].RequestItems["persons"].Keys))
+	assert.Equal(t, map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "person#100"},
+		"sk": &types.AttributeValueMemberS{Value: "details"},
+	}, batchGetItemInputs[1].RequestItems["persons"].Keys[0])
+
+	for _, batchGetItemInput := range batchGetItemInputs {
+		// key attributes are always projected
+		assert.Equal(t, "#0, #1, #2, #3.#4", *batchGetItemInput.RequestItems["persons"].ProjectionExpression)
+		assert.Equal(t, map[string]string{
+			"#0": "pk",
+			"#1": "sk",
+			"#2": "name",
+			"#3": "family_details",
+			"#4": "is_married",
+		}, batchGetItemInput.RequestItems["persons"].ExpressionAttributeNames)
+	}
+
+	// when nothing is marked for projection only key attributes are projected
+	transactionExpBuilder := NewTransaction_ExpressionBuilder()
+	transactionExpBuilder.Build()
+	batchGetItemInputs, err = BuildBatchGetItemInputs("transactions",
+		[]Transaction{{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}}, transactionExpBuilder)
+	assert.Nil(t, err)
+	assert.Equal(t, "#0, #1", *batchGetItemInputs[0].RequestItems["transactions"].ProjectionExpression)
+
+	// key attribute is missing
+	_, err = BuildBatchGetItemInputs("persons", []Person{{PK: utils.PointerTo("person#1")}}, expBuilder)
+	assert.ErrorContains(t, err, "value of key attribute [sk] is missing")
+}
+
+// Testing re-queue of unprocessed keys and decoding of batch get output
+func TestBatchGetItemOutput(t *testing.T) {
+	batchGetItemOutput := &dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]types.AttributeValue{
+			"persons": {
+				{"pk": &types.AttributeValueMemberS{Value: "person#1"}, "sk": &types.AttributeValueMemberS{Value: "details"}, "name": &types.AttributeValueMemberS{Value: "Name1"}},
+				{"pk": &types.AttributeValueMemberS{Value: "person#2"}, "sk": &types.AttributeValueMemberS{Value: "details"}, "name": &types.AttributeValueMemberS{Value: "Name2"}},
+			},
+		},
+		UnprocessedKeys: map[string]types.KeysAndAttributes{
+			"persons": {
+				Keys: []map[string]types.AttributeValue{
+					{"pk": &types.AttributeValueMemberS{Value: "person#3"}, "sk": &types.AttributeValueMemberS{Value: "details"}},
+				},
+			},
+		},
+	}
+
+	persons, err := DecodeBatchGetItemOutput[Person]("persons", batchGetItemOutput)
+	assert.Nil(t, err)
+	assert.Equal(t, []Person{
+		{PK: utils.PointerTo("person#1"), SK: utils.PointerTo("details"), Name: utils.PointerTo("Name1")},
+		{PK: utils.PointerTo("person#2"), SK: utils.PointerTo("details"), Name: utils.PointerTo("Name2")},
+	}, persons)
+
+	assert.Equal(t, &dynamodb.BatchGetItemInput{RequestItems: batchGetItemOutput.UnprocessedKeys},
+		UnprocessedBatchGetItemInput(batchGetItemOutput))
+	assert.Nil(t, UnprocessedBatchGetItemInput(&dynamodb.BatchGetItemOutput{}))
+}
+
+// Testing chunking and validation of batch write
+func TestBuildBatchWriteItemInputs(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+
+	putItems := []Person{}
+	for idx := 0; idx < 30; idx++ {
+		putItems = append(putItems, Person{
+			PK:   utils.PointerTo("person#" + strconv.Itoa(idx)),
+			SK:   utils.PointerTo("details"),
+			Name: utils.PointerTo("Name" + strconv.Itoa(idx)),
+		})
+	}
+	deleteKeys := []Person{{PK: utils.PointerTo("person#100"), SK: utils.PointerTo("details"), Name: utils.PointerTo("ignored")}}
+
+	batchWriteItemInputs, err := BuildBatchWriteItemInputs("persons", putItems, deleteKeys, expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, 2, len(batchWriteItemInputs))
+	assert.Equal(t, MaxBatchWriteItems, len(batchWriteItemInputs[0].RequestItems["persons"]))
+	assert.Equal(t, 6, len(batchWriteItemInputs[1].RequestItems["persons"]))
+	assert.Equal(t, types.WriteRequest{
+		PutRequest: &types.PutRequest{
+			Item: map[string]types.AttributeValue{
+				"pk":   &types.AttributeValueMemberS{Value: "person#29"},
+				"sk":   &types.AttributeValueMemberS{Value: "details"},
+				"name": &types.AttributeValueMemberS{Value: "Name29"},
+			},
+		},
+	}, batchWriteItemInputs[1].RequestItems["persons"][4])
+	assert.Equal(t, types.WriteRequest{
+		DeleteRequest: &types.DeleteRequest{
+			Key: map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: "person#100"},
+				"sk": &types.AttributeValueMemberS{Value: "details"},
+			},
+		},
+	}, batchWriteItemInputs[1].RequestItems["persons"][5])
+
+	// put and delete of the same item
+	_, err = BuildBatchWriteItemInputs("persons", putItems, putItems[3:4], expBuilder)
+	assert.ErrorContains(t, err, "request 3 and 30 of batch write target the same item")
+
+	// delete keys can be of another type than the put items
+	_, err = BuildBatchWriteItemInputs("persons", putItems, []map[string]types.AttributeValue{map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "person#3"}, "sk": &types.AttributeValueMemberS{Value: "details"}}}, expBuilder)
+	assert.ErrorContains(t, err, "request 3 and 30 of batch write target the same item")
+
+	unprocessedItems := map[string][]types.WriteRequest{"persons": batchWriteItemInputs[1].RequestItems["persons"]}
+	assert.Equal(t, &dynamodb.BatchWriteItemInput{RequestItems: unprocessedItems},
+		UnprocessedBatchWriteItemInput(&dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessedItems}))
+	assert.Nil(t, UnprocessedBatchWriteItemInput(&dynamodb.BatchWriteItemOutput{}))
+}
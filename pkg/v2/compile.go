@@ -0,0 +1,148 @@
+package v2
+
+import (
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SlotValue is a named value which is bound per request on a compiled Template, see Slot
+type SlotValue struct {
+	name string
+
+	// Scope of the expression builder `this` slot was created by
+	scope *core.SlotScope
+}
+
+// Slot returns a named value which can be used in place of any value of this expression builder
+// tree, e.g. expression.Value(d.Slot("name")) or AddValue(UPDATE_SET, d.Slot("name")). The value
+// is bound by Template.Bind once the tree is compiled. Slots can't be nested in other values and
+// can only be marshalled by Compile of this expression builder or its clones, marshalling them
+// anywhere else returns ErrSlotOutsideCompile so that every other builder fails
+func (d DDBItemExpressionBuilder[T]) Slot(name string) SlotValue {
+	return SlotValue{name: name, scope: d.slotScope}
+}
+
+// MarshalDynamoDBAttributeValue marshals `this` slot into a token which is taken for the slot by
+// the running Compile, returns ErrSlotOutsideCompile when its expression builder isn't compiled
+func (sv SlotValue) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	token, err := sv.scope.SlotToken(sv.name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AttributeValueMemberS{Value: token}, nil
+}
+
+// Template is an immutable expression compiled from an expression builder tree, expression
+// strings and names are fixed while the values of slots are bound per request
+type Template struct {
+	template *core.Template
+	names    map[string]string
+
+	// Values which are not slots, copied on every bind
+	values map[string]types.AttributeValue
+}
+
+// Compile builds every marked projection, key condition, condition, filter and update of this
+// expression builder tree into a Template, values marked using Slot are left to be bound by
+// Template.Bind. The tree can be discarded once compiled. Compiles of an expression builder and
+// its clones are serialised, compiles of different expression builders run concurrently
+func (d DDBItemExpressionBuilder[T]) Compile() (*Template, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().Compile()
+	}
+
+	var expr expression.Expression
+	template, err := d.slotScope.Compile(func() (ddbexpr.Expression, error) {
+		var err error
+		if expr, err = d.buildExpression(); err != nil {
+			return ddbexpr.Expression{}, err
+		}
+
+		return fromExpression(expr), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{
+		template: template,
+		names:    expr.Names(),
+		values:   sdkv2.ToItem(template.Values),
+	}, nil
+}
+
+func (t *Template) KeyCondition() *string {
+	return t.template.KeyCondition
+}
+
+func (t *Template) Condition() *string {
+	return t.template.Condition
+}
+
+func (t *Template) Filter() *string {
+	return t.template.Filter
+}
+
+func (t *Template) Projection() *string {
+	return t.template.Projection
+}
+
+func (t *Template) Update() *string {
+	return t.template.Update
+}
+
+// Names returns the expression attribute names, the map is shared by every request and must
+// not be modified
+func (t *Template) Names() map[string]string {
+	return t.names
+}
+
+// Slots returns the names of the slots of `this` template
+func (t *Template) Slots() []string {
+	return t.template.Slots()
+}
+
+// Bind returns the expression attribute values with every slot bound to its value in
+// `values`, values which are already a types.AttributeValue are used as is and the rest are marshalled.
+// Values which are not slots are copied, every slot is bound to the same value wherever it is
+// used. Returns an error if a slot is not bound or an unknown slot is bound. Nil is returned
+// when the template has no value at all
+//
+// Positions of the slots are computed by Compile and the values which are not slots are copied
+// using a constant number of allocations, so that binding attribute values to a template of
+// scalars allocates a constant number of times
+func (t *Template) Bind(values map[string]any) (map[string]types.AttributeValue, error) {
+	if err := t.template.CheckSlots(values); err != nil {
+		return nil, err
+	}
+
+	if t.template.ValueCount() == 0 {
+		return nil, nil
+	}
+
+	boundValues := make(map[string]types.AttributeValue, t.template.ValueCount())
+	sdkv2.CopyItem(boundValues, t.values)
+	for name, value := range values {
+		attributeValue, ok := value.(types.AttributeValue)
+		if !ok {
+			var err error
+			if attributeValue, err = attributevalue.Marshal(value); err != nil {
+				return nil, errors.New("cannot marshal value of slot " + name + ", " + err.Error())
+			}
+		}
+
+		for _, placeholder := range t.template.SlotPlaceholders(name) {
+			boundValues[placeholder] = attributeValue
+		}
+	}
+
+	return boundValues, nil
+}
@@ -0,0 +1,171 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// markCompilePerson marks `expBuilder` for the compile tests and benchmarks, `pk` and `name` are
+// the values which change per request
+func markCompilePerson(expBuilder DDBItemExpressionBuilder[*Person_ExpressionBuilder], pk, name any) DDBItemExpressionBuilder[*Person_ExpressionBuilder] {
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.BankDetails.AR().Accounts.AddListItem(1)
+	expBuilder.Build()
+
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value(pk)))
+	rootExpBldr.SK.AndWithCondition()(rootExpBldr.SK.GetKeyBuilder().Equal(expression.Value("details")))
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().NotEqual(expression.Value(name)))
+	rootExpBldr.Name.AddValue(UPDATE_SET, name)
+	rootExpBldr.BankDetails.AR().Accounts.Index(1).AR().BankAccountNumber.AddValue(UPDATE_ADD, 1)
+	rootExpBldr.FamilyDetails.Project()
+
+	return expBuilder
+}
+
+// tokenRecordingSlot records the token its slot is marshalled into
+type tokenRecordingSlot struct {
+	SlotValue
+	token string
+}
+
+func (ts *tokenRecordingSlot) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	attributeValue, err := ts.SlotValue.MarshalDynamoDBAttributeValue()
+	if err != nil {
+		return nil, err
+	}
+
+	ts.token = attributeValue.(*types.AttributeValueMemberS).Value
+	return attributeValue, nil
+}
+
+// Testing that bound templates are equivalent to expressions built with the same values
+func TestCompile(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	template, err := markCompilePerson(expBuilder, expBuilder.Slot("pk"), expBuilder.Slot("name")).Compile()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, []string{"name", "pk"}, template.Slots())
+
+	expr, err := markCompilePerson(NewPerson_ExpressionBuilder(), "person#1", "John").buildExpression()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	values, err := template.Bind(map[string]any{"pk": "person#1", "name": &types.AttributeValueMemberS{Value: "John"}})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, expr.KeyCondition(), template.KeyCondition())
+	assert.Equal(t, expr.Condition(), template.Condition())
+	assert.Equal(t, expr.Projection(), template.Projection())
+	assert.Equal(t, expr.Update(), template.Update())
+	assert.Nil(t, template.Filter())
+	assert.Equal(t, expr.Names(), template.Names())
+	assert.Equal(t, expr.Values(), values)
+
+	// binding attribute values copies the values which are not slots using a constant number of
+	// allocations
+	attributeValues := map[string]any{
+		"pk":   &types.AttributeValueMemberS{Value: "person#1"},
+		"name": &types.AttributeValueMemberS{Value: "John"},
+	}
+	assert.LessOrEqual(t, testing.AllocsPerRun(100, func() { _, _ = template.Bind(attributeValues) }), 4.0)
+
+	// slots are bound on every request
+	_, err = template.Bind(map[string]any{"pk": "person#1"})
+	assert.EqualError(t, err, "slots [name] are not bound and slots [] are unknown")
+	_, err = template.Bind(map[string]any{"pk": "person#1", "age": 1})
+	assert.EqualError(t, err, "slots [name] are not bound and slots [age] are unknown")
+
+	// slots can only be marshalled by compiles of their expression builder and its clones
+	_, err = attributevalue.Marshal(expBuilder.Slot("pk"))
+	assert.ErrorIs(t, err, ErrSlotOutsideCompile)
+	assert.NotNil(t, expBuilder.Validate())
+	_, err = markCompilePerson(NewPerson_ExpressionBuilder(), expBuilder.Slot("pk"), "John").Compile()
+	assert.NotNil(t, err)
+	template, err = expBuilder.Clone().Compile()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, []string{"name", "pk"}, template.Slots())
+
+	// tokens of slots are scoped to a compile, the token of another compile is a value
+	otherExpBuilder := NewPerson_ExpressionBuilder()
+	slot := &tokenRecordingSlot{SlotValue: otherExpBuilder.Slot("name")}
+	if _, err := markCompilePerson(otherExpBuilder, "person#1", slot).Compile(); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.NotEmpty(t, slot.token)
+	template, err = markCompilePerson(NewPerson_ExpressionBuilder(), "person#1", slot.token).Compile()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Empty(t, template.Slots())
+
+	// values of the template are copied on every bind
+	expBuilder = NewPerson_ExpressionBuilder()
+	template, err = markCompilePerson(expBuilder, []byte("person#1"), expBuilder.Slot("name")).Compile()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	first, _ := template.Bind(map[string]any{"name": "John"})
+	second, _ := template.Bind(map[string]any{"name": "John"})
+	for _, value := range first {
+		if binary, ok := value.(*types.AttributeValueMemberB); ok {
+			binary.Value[0] = 'P'
+		}
+	}
+	binaries := 0
+	for _, value := range second {
+		if binary, ok := value.(*types.AttributeValueMemberB); ok {
+			assert.Equal(t, []byte("person#1"), binary.Value)
+			binaries++
+		}
+	}
+	assert.Equal(t, 1, binaries)
+}
+
+func BenchmarkRebuildExpression(b *testing.B) {
+	b.ReportAllocs()
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := markCompilePerson(NewPerson_ExpressionBuilder(), "person#1", "John").buildExpression(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTemplateBind(b *testing.B) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	template, err := markCompilePerson(expBuilder, expBuilder.Slot("pk"), expBuilder.Slot("name")).Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	values := map[string]any{
+		"pk":   &types.AttributeValueMemberS{Value: "person#1"},
+		"name": &types.AttributeValueMemberS{Value: "John"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := template.Bind(values); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
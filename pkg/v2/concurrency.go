@@ -0,0 +1,22 @@
+package v2
+
+// WithConcurrency returns `this` expression builder in concurrency mode, in which the tree can
+// be marked from multiple goroutines. Every node of the tree shares a single lock:
+//   - Project, AndWithCondition, AndWithFilter, AddValue and AddListItem of attributes, and Path, Reset,
+//     UpdateFromDiff, ApplyPatch, ProjectFor and ParseExpression lock the tree for writing
+//   - Build*Builder, Explain, Validate, MarkConflicts, Compile, Evaluate, EvaluateFilters,
+//     BuildPartiQL and Clone copy the tree while holding the read lock and work on the copy, so
+//     every call sees a consistent snapshot of the marks, at the cost of copying the tree
+//
+// The mode applies to the tree, so it is enabled for every expression builder sharing it
+func (d DDBItemExpressionBuilder[T]) WithConcurrency() DDBItemExpressionBuilder[T] {
+	d.root.node.EnsureLock()
+	return d
+}
+
+// snapshot returns a copy of this expression builder tree taken while holding the read lock,
+// the copy is not in concurrency mode since it is not shared
+func (d DDBItemExpressionBuilder[T]) snapshot() DDBItemExpressionBuilder[T] {
+	defer d.root.node.RLockTree()()
+	return d.clone()
+}
@@ -0,0 +1,115 @@
+package v2
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that the tree can be marked and built from multiple goroutines in concurrency mode,
+// run with -race
+func TestWithConcurrency(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder().WithConcurrency()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < 10; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			rootExpBldr.PhoneNos.AddListItem(idx)
+		}(idx)
+	}
+	wg.Wait()
+
+	if err := expBuilder.Build(); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value("person#1")))
+
+	for idx := 0; idx < 10; idx++ {
+		wg.Add(2)
+		go func(idx int) {
+			defer wg.Done()
+			rootExpBldr.PhoneNos.Index(idx).Project()
+			rootExpBldr.PhoneNos.Index(idx).AddValue(UPDATE_SET, strconv.Itoa(idx))
+			rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().NotEqual(expression.Value(strconv.Itoa(idx))))
+		}(idx)
+
+		// every build sees a consistent snapshot of the marks
+		go func() {
+			defer wg.Done()
+			if _, err := expBuilder.BuildUpdateBuilder(); err != nil {
+				t.Errorf(err.Error())
+			}
+			if _, err := expBuilder.Explain(); err != nil {
+				t.Errorf(err.Error())
+			}
+			expBuilder.MarkConflicts()
+		}()
+	}
+	wg.Wait()
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	lines := strings.Split(explanation, "\n")
+	if assert.Equal(t, 4, len(lines)) {
+		assert.Equal(t, "KeyCondition: pk = \"person#1\"", lines[0])
+		assert.Equal(t, 10, strings.Count(lines[1], "name <>"))
+		assert.Equal(t, 10, strings.Count(lines[2], "phone_nos["))
+		assert.Equal(t, 10, strings.Count(lines[3], "phone_nos["))
+	}
+
+	// clones are in concurrency mode as well
+	clone := expBuilder.Clone()
+	assert.NotNil(t, clone.root.node.Lock())
+	assert.NotSame(t, expBuilder.root.node.Lock(), clone.root.node.Lock())
+}
+
+// Testing that marks and list items added by bulk operations are visible to every goroutine
+func TestWithConcurrencyBulkMarks(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder().WithConcurrency()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	expBuilder.Build()
+
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		oldPerson := Person{PK: utils.PointerTo("person#1"), PhoneNos: &[]*string{utils.PointerTo("111")}}
+		newPerson := Person{PK: utils.PointerTo("person#1"), PhoneNos: &[]*string{utils.PointerTo("222"), utils.PointerTo("333")}}
+		err := expBuilder.UpdateFromDiff(oldPerson, newPerson)
+		assert.Nil(t, err)
+	}()
+	go func() {
+		defer wg.Done()
+		rootExpBldr.FamilyDetails.Project()
+		rootExpBldr.Name.AddValue(UPDATE_REMOVE, nil)
+	}()
+	go func() {
+		defer wg.Done()
+		expBuilder.BuildProjectionBuilder()
+		expBuilder.Validate()
+	}()
+	wg.Wait()
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Contains(t, explanation, "family_details")
+	assert.Contains(t, explanation, "REMOVE name")
+	assert.Contains(t, explanation, "phone_nos")
+}
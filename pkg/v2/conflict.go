@@ -0,0 +1,57 @@
+package v2
+
+import (
+	"github.com/gauxs/dynexpr/internal/core"
+)
+
+type MarkMode = core.MarkMode
+
+const (
+	// Marks shadowed by the marks of a parent are silently ignored
+	MARK_MODE_PERMISSIVE = core.MARK_MODE_PERMISSIVE
+	// Mark conflicts fail BuildProjectionBuilder and BuildUpdateBuilder
+	MARK_MODE_STRICT = core.MARK_MODE_STRICT
+)
+
+type MarkConflictKind = core.MarkConflictKind
+
+const (
+	MARK_CONFLICT_SHADOWED_UPDATE     = core.MARK_CONFLICT_SHADOWED_UPDATE
+	MARK_CONFLICT_SHADOWED_PROJECTION = core.MARK_CONFLICT_SHADOWED_PROJECTION
+	MARK_CONFLICT_REPLACED_UPDATE     = core.MARK_CONFLICT_REPLACED_UPDATE
+)
+
+// MarkConflict is a mark of the expression builder tree which doesn't end up in the expression
+// as marked, e.g. an update of an attribute whose parent is set as a whole
+type MarkConflict = core.MarkConflict
+
+// MarkConflicts are all the conflicting marks of the expression builder tree, errors.As can be
+// used to get the first *MarkConflict
+type MarkConflicts = core.MarkConflicts
+
+// WithMarkMode returns `this` expression builder using `mode`, by default MARK_MODE_PERMISSIVE
+// is used which silently ignores conflicting marks
+func (d DDBItemExpressionBuilder[T]) WithMarkMode(mode MarkMode) DDBItemExpressionBuilder[T] {
+	d.markMode = mode
+	return d
+}
+
+// MarkConflicts returns the marks of this expression builder tree which don't end up in the
+// expression as marked, irrespective of the mark mode so they can be used as warnings:
+//   - updates of attributes whose parent is marked for update, the parent update wins
+//   - projections of attributes whose parent is marked for projection
+//   - update operations replaced by a later AddValue with a different operation, e.g. UPDATE_SET
+//     followed by UPDATE_REMOVE on the same attribute
+//   - updates of attributes having the name of a key attribute
+func (d DDBItemExpressionBuilder[T]) MarkConflicts() MarkConflicts {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().MarkConflicts()
+	}
+
+	return d.root.node.Conflicts()
+}
+
+// markConflicts returns the conflicts of `kinds` in strict mark mode, nil otherwise
+func (d DDBItemExpressionBuilder[T]) markConflicts(kinds ...MarkConflictKind) error {
+	return d.root.node.StrictConflicts(d.markMode, kinds...)
+}
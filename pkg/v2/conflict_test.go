@@ -0,0 +1,63 @@
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that shadowed and replaced marks are reported, and fail the build in strict mode only
+func TestMarkConflicts(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.BankDetails.AR().Accounts.AddListItem(1)
+	expBuilder.Build()
+
+	assert.Empty(t, expBuilder.MarkConflicts())
+
+	rootExpBldr.BankDetails.AddValue(UPDATE_SET, BankDetails{})
+	rootExpBldr.BankDetails.AR().Accounts.Index(1).AR().BankAccountNumber.AddValue(UPDATE_ADD, 1)
+	rootExpBldr.FamilyDetails.Project()
+	rootExpBldr.FamilyDetails.AR().IsMarried.Project()
+	rootExpBldr.Name.AddValue(UPDATE_SET, "John")
+	rootExpBldr.Name.AddValue(UPDATE_REMOVE, nil)
+
+	conflicts := expBuilder.MarkConflicts()
+	if assert.Len(t, conflicts, 3) {
+		assert.Equal(t, MARK_CONFLICT_REPLACED_UPDATE, conflicts[0].Kind)
+		assert.Equal(t, "name", conflicts[0].Path)
+		assert.Equal(t, "", conflicts[0].ShadowedBy)
+		assert.Equal(t, "UPDATE_SET of attribute name is replaced by UPDATE_REMOVE", conflicts[0].Error())
+
+		assert.Equal(t, MARK_CONFLICT_SHADOWED_UPDATE, conflicts[1].Kind)
+		assert.Equal(t, "bank_details.accounts[1].bank_account_number", conflicts[1].Path)
+		assert.Equal(t, "bank_details", conflicts[1].ShadowedBy)
+		assert.Equal(t, "update of attribute bank_details.accounts[1].bank_account_number is ignored since its parent bank_details is updated", conflicts[1].Error())
+
+		assert.Equal(t, MARK_CONFLICT_SHADOWED_PROJECTION, conflicts[2].Kind)
+		assert.Equal(t, "family_details.is_married", conflicts[2].Path)
+		assert.Equal(t, "family_details", conflicts[2].ShadowedBy)
+		assert.Equal(t, "projection of attribute family_details.is_married is ignored since its parent family_details is projected", conflicts[2].Error())
+	}
+
+	// permissive mode ignores the conflicting marks
+	_, err := expBuilder.BuildUpdateBuilder()
+	assert.Nil(t, err)
+	_, err = expBuilder.BuildProjectionBuilder()
+	assert.Nil(t, err)
+
+	strictExpBuilder := expBuilder.WithMarkMode(MARK_MODE_STRICT)
+	_, err = strictExpBuilder.BuildUpdateBuilder()
+	var markConflicts MarkConflicts
+	if assert.True(t, errors.As(err, &markConflicts)) {
+		assert.Equal(t, 2, len(markConflicts))
+	}
+
+	_, err = strictExpBuilder.BuildProjectionBuilder()
+	var markConflict *MarkConflict
+	if assert.True(t, errors.As(err, &markConflict)) {
+		assert.Equal(t, MARK_CONFLICT_SHADOWED_PROJECTION, markConflict.Kind)
+		assert.Equal(t, "family_details", markConflict.ShadowedBy)
+	}
+}
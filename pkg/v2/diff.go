@@ -0,0 +1,77 @@
+package v2
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type diffOptions struct {
+	// Add a condition asserting the old value on every changed attribute
+	oldValueConditions bool
+}
+
+type DiffOption func(*diffOptions)
+
+// WithOldValueConditions adds a condition on every changed attribute asserting that its
+// old value still holds, attributes absent in old value are asserted to not exist
+func WithOldValueConditions() DiffOption {
+	return func(options *diffOptions) {
+		options.oldValueConditions = true
+	}
+}
+
+// UpdateFromDiff walks this expression builder tree and marks UPDATE_SET/UPDATE_REMOVE on the
+// minimal set of document paths which differ between `oldItem` and `newItem`
+//
+// Both items must be of the same type, generally the struct representing a single item of
+// dynamo db. Nested maps are compared attribute by attribute and lists index by index, list
+// items which were not added via AddListItem are added to the tree. Attributes below a node
+// having no children, e.g. sets, are set as a whole. Returns an error if the items are of different
+// types, if a key attribute differs or, wrapping ErrUnknownAttribute, if a changed attribute of a
+// map has no node in the tree
+func (d DDBItemExpressionBuilder[T]) UpdateFromDiff(oldItem, newItem any, opts ...DiffOption) error {
+	defer d.root.node.LockTree()()
+	if reflect.TypeOf(oldItem) != reflect.TypeOf(newItem) {
+		return errors.New("old and new item of diff must be of the same type")
+	}
+
+	options := diffOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	oldValue, err := attributevalue.Marshal(oldItem)
+	if err != nil {
+		return err
+	}
+
+	newValue, err := attributevalue.Marshal(newItem)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := oldValue.(*types.AttributeValueMemberM); !ok {
+		return errors.New("old and new item of diff must marshal into a map")
+	}
+
+	if _, ok := newValue.(*types.AttributeValueMemberM); !ok {
+		return errors.New("old and new item of diff must marshal into a map")
+	}
+
+	return d.root.node.Diff(utils.PointerTo(sdkv2.FromAttributeValue(oldValue)), utils.PointerTo(sdkv2.FromAttributeValue(newValue)), options.oldValueConditions)
+}
+
+// rawAttributeValue passes an already marshalled attribute value through the marshaller
+type rawAttributeValue struct {
+	attributeValue types.AttributeValue
+}
+
+func (rav rawAttributeValue) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return rav.attributeValue, nil
+}
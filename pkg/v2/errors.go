@@ -0,0 +1,40 @@
+package v2
+
+import (
+	"github.com/gauxs/dynexpr/internal/core"
+)
+
+var (
+	// ErrUnknownListIndex is returned when a list item is selected by an index which can't
+	// be part of the list, e.g. a negative index or an index of an attribute which is not a list
+	ErrUnknownListIndex = core.ErrUnknownListIndex
+
+	// ErrUnknownAttribute is returned when an attribute doesn't exist in the expression builder
+	ErrUnknownAttribute = core.ErrUnknownAttribute
+
+	// ErrKeyAttributeFilter is returned by BuildQueryFilterBuilder when a key attribute is
+	// filtered, DynamoDB expects key attributes in the key condition of a query instead
+	ErrKeyAttributeFilter = core.ErrKeyAttributeFilter
+
+	// ErrMapListItemValue is returned by Append, Prepend and SetAt of a list of maps, T of such
+	// a list is the expression builder of the map which can't be used as value. Mark the
+	// attributes of the list items instead, or the whole list using AddValue
+	ErrMapListItemValue = core.ErrMapListItemValue
+
+	// ErrNotNumber is returned when an attribute is incremented or decremented by a value which
+	// isn't a number
+	ErrNotNumber = core.ErrNotNumber
+
+	// ErrSlotOutsideCompile is returned when a slot is marshalled outside of Compile of the
+	// expression builder it was created by
+	ErrSlotOutsideCompile = core.ErrSlotOutsideCompile
+
+	// ErrUnsupportedPartiQL is returned by BuildPartiQL when the marked tree has no PartiQL
+	// equivalent, e.g. ADD of a number or if_not_exists
+	ErrUnsupportedPartiQL = core.ErrUnsupportedPartiQL
+)
+
+// PathError records an error and the operation and document path of the attribute that caused
+// it. Errors of every failing attribute of a traversal are joined, use errors.Is and errors.As
+// to inspect them
+type PathError = core.PathError
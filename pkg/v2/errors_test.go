@@ -0,0 +1,64 @@
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that errors can be inspected with errors.Is/errors.As and every failing path is reported
+func TestErrors(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+
+	// every invalid index is reported
+	err := rootExpBldr.PhoneNos.AddListItem(-1, 0, -2)
+	assert.ErrorIs(t, err, ErrUnknownListIndex)
+	assert.EqualError(t, err, "cannot add list item [-1] to attribute [phone_nos]: unknown list index\n"+
+		"cannot add list item [-2] to attribute [phone_nos]: unknown list index")
+
+	var pathErr *PathError
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, "phone_nos", pathErr.Path)
+		assert.True(t, errors.Is(pathErr, ErrUnknownListIndex))
+	}
+
+	// every unknown field is reported
+	type unknownSummary struct {
+		Nickname *string `dynamodbav:"nickname"`
+		Age      *int    `dynamodbav:"age"`
+	}
+	err = ProjectFor[unknownSummary](expBuilder)
+	assert.ErrorIs(t, err, ErrUnknownAttribute)
+	assert.ErrorContains(t, err, "[nickname]")
+	assert.ErrorContains(t, err, "[age]")
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, "nickname", pathErr.Path)
+		assert.Equal(t, "project", pathErr.Op)
+		assert.ErrorIs(t, pathErr, ErrUnknownAttribute)
+	}
+
+	// unknown fields and paths of a patch are reported
+	type unknownPatch struct {
+		FamilyDetails *struct {
+			Pets *int `dynamodbav:"pets"`
+		} `dynamodbav:"family_details"`
+	}
+	pets := 2
+	patch := unknownPatch{FamilyDetails: &struct {
+		Pets *int `dynamodbav:"pets"`
+	}{Pets: &pets}}
+	err = expBuilder.ApplyPatch(patch)
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, "family_details.pets", pathErr.Path)
+		assert.Equal(t, "patch", pathErr.Op)
+		assert.ErrorIs(t, pathErr, ErrUnknownAttribute)
+	}
+
+	err = expBuilder.ApplyPatch(unknownPatch{}, WithNull("nickname"))
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, "nickname", pathErr.Path)
+		assert.ErrorIs(t, pathErr, ErrUnknownAttribute)
+	}
+}
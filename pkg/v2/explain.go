@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+// Explain renders every expression of a built expression on its own line with name and value
+// placeholders substituted inline, e.g.
+//
+//	Update: SET bank_details.accounts[3].bank_account_number = 2
+//
+// values are pretty printed and truncated, so it is safe to use in logs
+func Explain(expr expression.Expression) string {
+	return fromExpression(expr).Explain()
+}
+
+// fromExpression converts a built expression into one independent of the aws sdk version
+func fromExpression(expr expression.Expression) ddbexpr.Expression {
+	return ddbexpr.Expression{
+		Expressions: ddbexpr.Expressions{
+			KeyCondition: expr.KeyCondition(),
+			Condition:    expr.Condition(),
+			Filter:       expr.Filter(),
+			Projection:   expr.Projection(),
+			Update:       expr.Update(),
+		},
+		Names:  expr.Names(),
+		Values: sdkv2.FromItem(expr.Values()),
+	}
+}
+
+// Explain builds every marked projection, key condition, condition, filter and update of this
+// expression builder tree into a single expression and explains it, see Explain
+func (d DDBItemExpressionBuilder[T]) Explain() (string, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().Explain()
+	}
+
+	expr, err := d.buildExpression()
+	if err != nil {
+		return "", err
+	}
+
+	return Explain(expr), nil
+}
+
+// buildExpression builds every marked projection, key condition, condition, filter and update of
+// this expression builder tree into a single expression, the expression is not validated
+func (d DDBItemExpressionBuilder[T]) buildExpression() (expression.Expression, error) {
+	if err := d.root.node.IndexErrors(); err != nil {
+		return expression.Expression{}, err
+	}
+
+	exprBuilder := expression.NewBuilder()
+	isSet := false
+
+	projectionBuilder, err := d.root.addName(&expression.ProjectionBuilder{})
+	if err != nil {
+		return expression.Expression{}, err
+	}
+
+	// key attributes are always projected, so the projection is never empty
+	exprBuilder, isSet = exprBuilder.WithProjection(*projectionBuilder), true
+
+	if keyConditionBuilder := d.keyConditionBuilder(); keyConditionBuilder != nil {
+		exprBuilder, isSet = exprBuilder.WithKeyCondition(*keyConditionBuilder), true
+	}
+
+	if conditionBuilder := d.conditionBuilder(); conditionBuilder != nil {
+		exprBuilder, isSet = exprBuilder.WithCondition(*conditionBuilder), true
+	}
+
+	if filterBuilder := addFilters(d.root.node, nil); filterBuilder != nil {
+		exprBuilder, isSet = exprBuilder.WithFilter(*filterBuilder), true
+	}
+
+	updateBuilder, err := d.root.addUpdate(&expression.UpdateBuilder{})
+	if err != nil {
+		return expression.Expression{}, err
+	}
+
+	if _, err := expression.NewBuilder().WithUpdate(*updateBuilder).Build(); err == nil {
+		exprBuilder, isSet = exprBuilder.WithUpdate(*updateBuilder), true
+	} else if !errors.As(err, &expression.UnsetParameterError{}) { // no attribute is marked for update
+		return expression.Expression{}, err
+	}
+
+	return buildOptional(exprBuilder, isSet)
+}
@@ -1,17 +1,15 @@
 package v2
 
 import (
-	"errors"
-	"strconv"
-	"strings"
-
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
 	"github.com/gauxs/dynexpr/internal/utils"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 )
 
 const (
-	DDBAtributeNameCancatenator = "."
+	DDBAtributeNameCancatenator = core.DDBAtributeNameCancatenator
 )
 
 // This is implemented by structs using this package
@@ -21,19 +19,12 @@ type TreeBuilder[T any] interface {
 	BuildTree(string) *DynamoAttribute[T]
 }
 
-// TODO: instead of Builder and Projector we should have union of
+// TODO: instead of Projector and Updater we should have union of
 // DynamoAttribute and DynamoListAttribute but current limitation
 // doesn't allow us.
 // Ref: https://stackoverflow.com/a/71378366
 
-type Builder interface {
-	// build builds `this` attribute. If `this` attribute
-	// is a list then new nodes(elements of the list) are attached
-	// if specified via AddListItem
-	build(string) error
-}
-
-// TODO: merge this with Builder
+// TODO: merge this with Updater
 type Projector interface {
 	// addName adds 'this' attribute's document name into the projection builder
 	// passed in argument and returns a new projection builder
@@ -60,6 +51,9 @@ type KeyConditioner interface {
 	//
 	// condition passed in argument always comes first in `AND`
 	addKeyCondition(*expression.KeyConditionBuilder) *expression.KeyConditionBuilder
+
+	// GetName returns the name of the key attribute as defined in DB
+	GetName() string
 }
 
 type Updater interface {
@@ -68,79 +62,65 @@ type Updater interface {
 	addUpdate(*expression.UpdateBuilder) (*expression.UpdateBuilder, error)
 }
 
+// attributeNode is implemented by the typed attributes wrapping a node of the expression
+// builder tree
+type attributeNode interface {
+	// coreNode returns the node of 'this' attribute
+	coreNode() *core.Node
+}
+
 // Enforcing constraints at compile time
-var _ Builder = (&DynamoAttribute[int]{})
 var _ Updater = (&DynamoAttribute[int]{})
 var _ Projector = (&DynamoAttribute[int]{})
 var _ Conditioner = (&DynamoAttribute[int]{})
+var _ attributeNode = (&DynamoAttribute[int]{})
 
-var _ Builder = (&DynamoListAttribute[int]{})
 var _ Updater = (&DynamoListAttribute[int]{})
 var _ Projector = (&DynamoListAttribute[int]{})
 var _ Conditioner = (&DynamoListAttribute[int]{})
+var _ attributeNode = (&DynamoListAttribute[int]{})
 
 var _ Projector = (&DynamoKeyAttribute[int]{})
 var _ KeyConditioner = (&DynamoKeyAttribute[int]{})
+var _ attributeNode = (&DynamoKeyAttribute[int]{})
 
-// Represents a dyanmo db attribute
-type DynamoAttribute[T any] struct {
-	// True when build has been executed on 'this' attribute
-	// projection, Condition and Update can only be done after
-	// build is executed
-	buildExecuted bool
-
-	// Mark 'this' attribute for projection
-	projection bool
+// ItemExpressionBuilder is implemented by DDBItemExpressionBuilder of every DDB item
+// type, this allows expression builders of different DDB items to be used together
+type ItemExpressionBuilder interface {
+	BuildProjectionBuilder() (*expression.ProjectionBuilder, error)
+	BuildKeyConditionBuilder() *expression.KeyConditionBuilder
+	BuildConditionBuilder() *expression.ConditionBuilder
+	BuildFilterBuilder() (*expression.ConditionBuilder, error)
+	BuildUpdateBuilder() (*expression.UpdateBuilder, error)
 
-	// Name of the dynamo attribute as defined in DB
-	name string
+	// keyAttributeNames returns the name of all the key attributes of the DDB item
+	keyAttributeNames() []string
 
-	// Document path of this attribute
-	documentPath string
+	// checkBuild returns the errors BuildKeyConditionBuilder and BuildConditionBuilder can't return
+	checkBuild() error
+}
 
-	// Use this to build any condition on 'this' attribute
-	nameBuilder expression.NameBuilder
+var _ ItemExpressionBuilder = DDBItemExpressionBuilder[int]{}
 
-	// Represents all the conditions applied on 'this' dynamo attribute
-	conditionBuilder expression.ConditionBuilder
+// Represents a dyanmo db attribute
+type DynamoAttribute[T any] struct {
+	// Node of 'this' attribute in the expression builder tree
+	// holding its name, document path and marks
+	node *core.Node
 
 	// Helps in direct member selection of child attributes
 	accessReference T
-
-	// Determines the operation which needs to performed on
-	// 'this' attribute
-	operation DynamoOperation
-
-	// Represent the value which needs to be assigned to 'this'
-	// attribute for update
-	value any
-
-	// Child attributes of 'this' attribute, can be DynamoAttribute
-	// or DynamoListAttribute
-	childAttributes []interface{}
 }
 
 func NewDynamoAttribute[T any]() *DynamoAttribute[T] {
-	return &DynamoAttribute[T]{
-		childAttributes: []interface{}{},
-	}
-}
-
-func (da *DynamoAttribute[T]) constructDocumentPath(documentPathOfParent string) string {
-	if strings.HasSuffix(da.GetName(), "]") { // this is a top level element of a list, don't use DDBAtributeNameCancatenator
-		return documentPathOfParent + da.GetName()
-	}
-
-	if documentPathOfParent == "" { // this is top level attribute
-		return da.GetName()
-	}
-
-	return documentPathOfParent + DDBAtributeNameCancatenator + da.GetName()
+	da := &DynamoAttribute[T]{node: core.NewNode(core.NODE_ATTRIBUTE)}
+	da.node.SetOwner(da)
+	return da
 }
 
 // WithName builds `this` DynamoAttribute with a dynamo db attribute name
 func (da *DynamoAttribute[T]) WithName(name string) *DynamoAttribute[T] {
-	da.name = name
+	da.node.SetName(name)
 	return da
 }
 
@@ -154,47 +134,29 @@ func (da *DynamoAttribute[T]) WithAccessReference(accessReference T) *DynamoAttr
 // WithChildAttribute builds `this` DynamoAttribute child attribute which are
 // child nodes holding member attribute of type `T`
 func (da *DynamoAttribute[T]) WithChildAttribute(childAttribute interface{}) *DynamoAttribute[T] {
-	da.childAttributes = append(da.childAttributes, childAttribute)
-	return da
-}
-
-func (da *DynamoAttribute[T]) build(parentDocumentPath string) error {
-	if da.buildExecuted {
-		return errors.New("build is already executed on attribute " + da.documentPath)
-	}
-
-	// build document path
-	da.documentPath = da.constructDocumentPath(parentDocumentPath)
-
-	// build name builder
-	da.nameBuilder = expression.Name(da.documentPath)
-
-	for _, childAttribute := range da.childAttributes {
-		switch childAttributeType := childAttribute.(type) {
-		case Builder:
-			childAttributeType.build(da.documentPath)
-		}
+	switch childAttributeType := childAttribute.(type) {
+	case attributeNode:
+		// the child attribute may be a copy of the attribute created with the node
+		childAttributeType.coreNode().SetOwner(childAttribute)
+		da.node.AddChild(childAttributeType.coreNode())
 	}
 
-	da.buildExecuted = true
-	return nil
+	return da
 }
 
+// Project marks `this` attribute for projection
 func (da *DynamoAttribute[T]) Project() error {
-	if !da.buildExecuted {
-		return errors.New("build is not yet executed on attribute [" + da.name + "], cannot mark this attribute for projection")
-	}
-
-	da.projection = true
-	return nil
+	defer da.node.LockTree()()
+	return da.node.Project()
 }
 
 func (da *DynamoAttribute[T]) GetName() string {
-	return da.name
+	return da.node.Name()
 }
 
 func (da *DynamoAttribute[T]) GetNameBuilder() expression.NameBuilder {
-	return da.nameBuilder
+	defer da.node.RLockTree()()
+	return nameBuilder(da.node)
 }
 
 // AR returns the type `T` held by this node
@@ -203,108 +165,222 @@ func (da *DynamoAttribute[T]) AR() T {
 	return da.accessReference
 }
 
-func (da *DynamoAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (newProjectionBuilder *expression.ProjectionBuilder, err error) {
-	if !da.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + da.name + "], cannot mark this attribute for projection")
+// AndWithCondition adds a new condition to `this` attributes existing conditions using `AND`
+func (da *DynamoAttribute[T]) AndWithCondition() func(conditionBuilder expression.ConditionBuilder) {
+	return func(conditionBuilder expression.ConditionBuilder) {
+		defer da.node.LockTree()()
+		da.node.AndWithCondition(conditionBuilder)
 	}
+}
 
-	if projectionBuilder == nil {
-		return nil, errors.New("nil projection builder passed for attribute " + da.documentPath)
+// AndWithFilter adds a new filter to `this` attributes existing filters using `AND`, filters
+// are built separately from conditions by BuildFilterBuilder and used by query and scan
+func (da *DynamoAttribute[T]) AndWithFilter() func(filterBuilder expression.ConditionBuilder) {
+	return func(filterBuilder expression.ConditionBuilder) {
+		defer da.node.LockTree()()
+		da.node.AndWithFilter(filterBuilder)
 	}
+}
 
-	newProjectionBuilder = projectionBuilder
-	if da.projection { // skipping projection of child attributes
-		return utils.PointerTo(newProjectionBuilder.AddNames(expression.Name(da.documentPath))), nil
-	} else {
-		for _, childAttribute := range da.childAttributes {
-			switch childAttributeType := childAttribute.(type) {
-			case Projector:
-				newProjectionBuilder, err = childAttributeType.addName(newProjectionBuilder)
-			}
+// AddValue adds a value which will be used to update `this` attributes
+func (da *DynamoAttribute[T]) AddValue(operation DynamoOperation, value any) {
+	defer da.node.LockTree()()
+	da.node.AddValue(core.Operation(operation), value)
+}
 
-			if err != nil {
-				return nil, err
-			}
-		}
+// Increment marks `this` attribute for UPDATE_SET with its current value plus `n`, combined
+// with SetIfNotExists in either order the default value is used when `this` attribute doesn't
+// exist, i.e. `SET a = if_not_exists(a, :d) + :n`
+//
+// `T` must be a number or a pointer to one, ErrNotNumber is returned otherwise and `this`
+// attribute is left as is
+func (da *DynamoAttribute[T]) Increment(n T) error {
+	defer da.node.LockTree()()
+	return da.node.AddArithmetic(core.SetOperation{Kind: core.SET_PLUS, Value: n})
+}
+
+// Decrement marks `this` attribute for UPDATE_SET with its current value minus `n`, see Increment
+func (da *DynamoAttribute[T]) Decrement(n T) error {
+	defer da.node.LockTree()()
+	return da.node.AddArithmetic(core.SetOperation{Kind: core.SET_MINUS, Value: n})
+}
+
+// SetIfNotExists marks `this` attribute for UPDATE_SET with `value` only if `this` attribute
+// doesn't exist, see Increment
+func (da *DynamoAttribute[T]) SetIfNotExists(value T) {
+	defer da.node.LockTree()()
+	da.node.AddSetOperation(core.SetOperation{Kind: core.SET_IF_NOT_EXISTS, Value: value})
+}
+
+// SetFrom marks `this` attribute for UPDATE_SET with the value of the attribute `from`
+func (da *DynamoAttribute[T]) SetFrom(from Node) {
+	documentPath := from.DocumentPath()
+	defer da.node.LockTree()()
+	da.node.AddSetOperation(core.SetOperation{Kind: core.SET_FROM, From: documentPath})
+}
+
+// SetExpr marks `this` attribute for UPDATE_SET with `operandBuilder`, e.g. a SetValueBuilder
+// which isn't covered by Increment, Decrement, SetIfNotExists and SetFrom
+func (da *DynamoAttribute[T]) SetExpr(operandBuilder expression.OperandBuilder) {
+	defer da.node.LockTree()()
+	da.node.AddValue(core.UPDATE_SET, operandBuilder)
+}
+
+func (da *DynamoAttribute[T]) coreNode() *core.Node {
+	return da.node
+}
+
+// addName recursively collects all the attributes which were marked for projection
+func (da *DynamoAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	return addNames(da.node, projectionBuilder)
+}
+
+func (da *DynamoAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return addConditions(da.node, conditionBuilder)
+}
+
+func (da *DynamoAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (*expression.UpdateBuilder, error) {
+	return addUpdates(da.node, updateBuilder)
+}
+
+// nameBuilder returns the name builder of the document path of `node`
+func nameBuilder(node *core.Node) expression.NameBuilder {
+	return expression.Name(node.DocumentPath())
+}
+
+// addNames adds the document paths of the nodes below `node` marked for projection into the
+// projection builder and returns a new projection builder
+func addNames(node *core.Node, projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	newProjectionBuilder := projectionBuilder
+	for _, path := range node.ProjectionPaths() {
+		newProjectionBuilder = utils.PointerTo(newProjectionBuilder.AddNames(expression.Name(path)))
 	}
 
 	return newProjectionBuilder, nil
 }
 
-func (da *DynamoAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) (newConditionBuilder *expression.ConditionBuilder) {
-	if da.conditionBuilder.IsSet() {
-		if conditionBuilder != nil && (*conditionBuilder).IsSet() {
-			newConditionBuilder = utils.PointerTo(conditionBuilder.And(da.conditionBuilder))
-		} else {
-			newConditionBuilder = &da.conditionBuilder
-		}
-	} else {
-		newConditionBuilder = conditionBuilder
-	}
+// addConditions adds the conditions of the nodes below `node` into the condition builder using
+// `AND` and returns a new condition builder, conditions of a single node are joined first
+func addConditions(node *core.Node, conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return joinConditions(node.Conditions(), conditionBuilder)
+}
+
+// addFilters adds the filters of the nodes below `node` into the condition builder using `AND`
+// and returns a new condition builder, filters of a single node are joined first
+func addFilters(node *core.Node, filterBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return joinConditions(node.Filters(), filterBuilder)
+}
 
-	for _, childAttribute := range da.childAttributes {
-		switch childAttributeType := childAttribute.(type) {
-		case Conditioner:
-			// when both passed and returned condition builder are nil
-			if retConditionBuilder := childAttributeType.addCondition(newConditionBuilder); retConditionBuilder != nil {
-				newConditionBuilder = retConditionBuilder
+// joinConditions joins the conditions of every node into the condition builder using `AND`
+func joinConditions(nodeConditions [][]any, conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	newConditionBuilder := conditionBuilder
+	for _, conditions := range nodeConditions {
+		var nodeConditionBuilder *expression.ConditionBuilder
+		for _, condition := range conditions {
+			// zero value of struct `ConditionBuilder` is not a condition
+			if conditionBuilder := renderCondition(condition); !conditionBuilder.IsSet() {
+				continue
+			} else if nodeConditionBuilder != nil {
+				nodeConditionBuilder = utils.PointerTo(nodeConditionBuilder.And(conditionBuilder))
+			} else {
+				nodeConditionBuilder = &conditionBuilder
 			}
 		}
+
+		if nodeConditionBuilder == nil {
+			continue
+		} else if newConditionBuilder != nil {
+			newConditionBuilder = utils.PointerTo(newConditionBuilder.And(*nodeConditionBuilder))
+		} else {
+			newConditionBuilder = nodeConditionBuilder
+		}
 	}
 
 	return newConditionBuilder
 }
 
-// AndWithCondition adds a new condition to `this` attributes existing conditions using `AND`
-func (da *DynamoAttribute[T]) AndWithCondition() func(conditionBuilder expression.ConditionBuilder) {
-	return func(conditionBuilder expression.ConditionBuilder) {
-		if da.conditionBuilder.IsSet() {
-			if conditionBuilder.IsSet() {
-				da.conditionBuilder = da.conditionBuilder.And(conditionBuilder)
-			}
-		} else {
-			da.conditionBuilder = conditionBuilder
+// renderCondition converts a condition of the tree into a condition builder
+func renderCondition(condition any) expression.ConditionBuilder {
+	switch conditionType := condition.(type) {
+	case core.OldValueCondition:
+		if conditionType.Old == nil {
+			return expression.Name(conditionType.Path).AttributeNotExists()
 		}
-	}
-}
 
-// AddValue adds a value which will be used to update `this` attributes
-func (da *DynamoAttribute[T]) AddValue(operation DynamoOperation, value any) {
-	da.operation = operation
-	da.value = value
-}
-
-func (da *DynamoAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (newUpdateBuilder *expression.UpdateBuilder, err error) {
-	if !da.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + da.name + "], cannot update this attribute")
-	}
-
-	if updateBuilder == nil {
-		return nil, errors.New("nil update builder passed for attribute " + da.documentPath)
-	}
-
-	newUpdateBuilder = updateBuilder
-	valueBuilder := expression.Value(da.value)
-
-	switch da.operation {
-	case UPDATE_SET:
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Set(da.nameBuilder, valueBuilder))
-	case UPDATE_REMOVE:
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Remove(da.nameBuilder))
-	case UPDATE_ADD: // for numbers and set data structure
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Add(da.nameBuilder, valueBuilder))
-	case UPDATE_DELETE: // for set data structure only
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Delete(da.nameBuilder, valueBuilder))
-	case NO_OP:
-		for _, childAttribute := range da.childAttributes {
-			switch childAttributeType := childAttribute.(type) {
-			case Updater:
-				if retUpdateBuilder, err := childAttributeType.addUpdate(newUpdateBuilder); err != nil {
-					return nil, err
-				} else if retUpdateBuilder != nil {
-					newUpdateBuilder = retUpdateBuilder
-				}
+		return expression.Name(conditionType.Path).Equal(expression.Value(renderValue(core.RawValue{Value: *conditionType.Old})))
+	case core.ParsedCondition:
+		return renderParsedCondition(conditionType)
+	default:
+		return condition.(expression.ConditionBuilder)
+	}
+}
+
+// renderValue converts a value of the tree into a value for the marshaller
+func renderValue(value any) any {
+	switch valueType := value.(type) {
+	case core.RawValue:
+		return rawAttributeValue{sdkv2.ToAttributeValue(valueType.Value)}
+	default:
+		return value
+	}
+}
+
+// renderUpdateValue converts a value marked for update on the attribute at `path` into a value
+// for the marshaller or an operand builder
+func renderUpdateValue(path string, value any) any {
+	if operand, ok := core.UpdateOperand(path, value); ok {
+		return renderOperand(operand)
+	}
+
+	return renderValue(value)
+}
+
+// renderOperand converts an Operand into an operand builder
+func renderOperand(operand core.Operand) expression.OperandBuilder {
+	arguments := make([]expression.OperandBuilder, 0, len(operand.Arguments))
+	for _, argument := range operand.Arguments {
+		arguments = append(arguments, renderOperand(argument))
+	}
+
+	switch operand.Kind {
+	case core.OPERAND_NAME:
+		return expression.Name(operand.Path)
+	case core.OPERAND_VALUE:
+		return expression.Value(renderValue(operand.Value))
+	case core.OPERAND_SIZE:
+		return expression.Name(operand.Path).Size()
+	case core.OPERAND_IF_NOT_EXISTS:
+		return expression.IfNotExists(expression.Name(operand.Arguments[0].Path), arguments[1])
+	case core.OPERAND_PLUS:
+		return expression.Plus(arguments[0], arguments[1])
+	case core.OPERAND_MINUS:
+		return expression.Minus(arguments[0], arguments[1])
+	default:
+		return expression.ListAppend(arguments[0], arguments[1])
+	}
+}
+
+// addUpdates adds the update operations of the nodes below `node` into the update builder and
+// returns a new update builder
+func addUpdates(node *core.Node, updateBuilder *expression.UpdateBuilder) (*expression.UpdateBuilder, error) {
+	newUpdateBuilder := updateBuilder
+	for _, update := range node.Updates() {
+		nameBuilder, value := expression.Name(update.Path), renderUpdateValue(update.Path, update.Value)
+		valueBuilder := expression.Value(value)
+		switch update.Operation {
+		case core.UPDATE_SET:
+			switch valueType := value.(type) {
+			case expression.OperandBuilder: // this is when we want to perform if_not_exists, list_append or arithmetic
+				newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Set(nameBuilder, valueType))
+			default:
+				newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Set(nameBuilder, valueBuilder))
 			}
+		case core.UPDATE_REMOVE:
+			newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Remove(nameBuilder))
+		case core.UPDATE_ADD: // for numbers and set data structure
+			newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Add(nameBuilder, valueBuilder))
+		case core.UPDATE_DELETE: // for set data structure only
+			newUpdateBuilder = utils.PointerTo(newUpdateBuilder.Delete(nameBuilder, valueBuilder))
 		}
 	}
 
@@ -313,63 +389,31 @@ func (da *DynamoAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder)
 
 // Represents a dyanmo db primary key attribute
 type DynamoKeyAttribute[T any] struct {
-	// True when build has been executed on 'this' attribute
-	// projection, Condition and Update can only be done after
-	// build is executed
-	buildExecuted bool
-
-	// Mark 'this' attribute for projection
-	projection bool
-
 	// Name of the dynamo attribute as defined in DB
 	Name string
 
-	// Document path of this attribute
-	documentPath string
-
-	// Use this to build any condition on 'this' attribute
-	keyBuilder expression.KeyBuilder
-
-	// Represents all the conditions applied on 'this' dynamo attribute
-	keyConditionBuilder expression.KeyConditionBuilder
+	// Node of 'this' attribute in the expression builder tree
+	// holding its document path and marks
+	node *core.Node
 }
 
 func NewDynamoKeyAttribute[T any]() *DynamoKeyAttribute[T] {
-	return &DynamoKeyAttribute[T]{}
-}
-
-func (dka *DynamoKeyAttribute[T]) constructDocumentPath(documentPathOfParent string) string {
-	return dka.GetName()
+	dka := &DynamoKeyAttribute[T]{node: core.NewNode(core.NODE_KEY)}
+	dka.node.SetOwner(dka)
+	return dka
 }
 
 // WithName builds `this` DynamoKeyAttribute with a dynamo db attribute name
 func (dka *DynamoKeyAttribute[T]) WithName(name string) *DynamoKeyAttribute[T] {
 	dka.Name = name
+	dka.node.SetName(name)
 	return dka
 }
 
-func (dka *DynamoKeyAttribute[T]) build(parentDocumentPath string) error {
-	if dka.buildExecuted {
-		return errors.New("build is already executed on attribute " + dka.documentPath)
-	}
-
-	// build document path
-	dka.documentPath = dka.constructDocumentPath(parentDocumentPath)
-
-	// build key builder
-	dka.keyBuilder = expression.Key(dka.documentPath)
-
-	dka.buildExecuted = true
-	return nil
-}
-
+// Project marks `this` attribute for projection
 func (dka *DynamoKeyAttribute[T]) Project() error {
-	if !dka.buildExecuted {
-		return errors.New("build is not yet executed on attribute [" + dka.Name + "], cannot mark this attribute for projection")
-	}
-
-	dka.projection = true
-	return nil
+	defer dka.node.LockTree()()
+	return dka.node.Project()
 }
 
 func (dka *DynamoKeyAttribute[T]) GetName() string {
@@ -377,50 +421,72 @@ func (dka *DynamoKeyAttribute[T]) GetName() string {
 }
 
 func (dka *DynamoKeyAttribute[T]) GetKeyBuilder() expression.KeyBuilder {
-	return dka.keyBuilder
+	defer dka.node.RLockTree()()
+	return expression.Key(dka.node.DocumentPath())
 }
 
-func (dka *DynamoKeyAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
-	if !dka.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + dka.Name + "], cannot mark this attribute for projection")
+// AndWithCondition adds a new condition to `this` attributes existing conditions using `AND`
+func (dka *DynamoKeyAttribute[T]) AndWithCondition() func(keyConditionBuilder expression.KeyConditionBuilder) {
+	return func(keyConditionBuilder expression.KeyConditionBuilder) {
+		defer dka.node.LockTree()()
+		dka.node.AndWithCondition(keyConditionBuilder)
 	}
+}
 
-	if projectionBuilder == nil {
-		return nil, errors.New("nil projection builder passed for attribute " + dka.documentPath)
+// AndWithFilter adds a new filter to `this` attributes existing filters using `AND`, DynamoDB
+// allows key attributes in the filter of a scan only, see BuildQueryFilterBuilder
+func (dka *DynamoKeyAttribute[T]) AndWithFilter() func(filterBuilder expression.ConditionBuilder) {
+	return func(filterBuilder expression.ConditionBuilder) {
+		defer dka.node.LockTree()()
+		dka.node.AndWithFilter(filterBuilder)
 	}
+}
+
+func (dka *DynamoKeyAttribute[T]) coreNode() *core.Node {
+	return dka.node
+}
 
-	return utils.PointerTo(projectionBuilder.AddNames(expression.Name(dka.documentPath))), nil
+func (dka *DynamoKeyAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	return addNames(dka.node, projectionBuilder)
 }
 
-// Implicitly uses AND condition between conditions of different attribute
-// func (dka *DynamoKeyAttribute[T]) addCondition(keyConditionBuilder *expression.KeyConditionBuilder) (newKeyConditionBuilder *expression.KeyConditionBuilder) {
-// 	if !dka.keyConditionBuilder.IsSet() {
-// 		return &dka.keyConditionBuilder
-// 	}
+func (dka *DynamoKeyAttribute[T]) addKeyCondition(keyConditionBuilder *expression.KeyConditionBuilder) *expression.KeyConditionBuilder {
+	return addKeyConditions([][]any{dka.node.MarkedConditions()}, keyConditionBuilder)
+}
 
-// 	return utils.PointerTo(keyConditionBuilder.And(dka.keyConditionBuilder))
-// }
+// renderKeyCondition converts a marked key condition into a key condition builder
+func renderKeyCondition(condition any) expression.KeyConditionBuilder {
+	if parsed, ok := condition.(core.ParsedCondition); ok {
+		return renderParsedKeyCondition(parsed)
+	}
 
-// AndWithCondition adds a new condition to `this` attributes existing conditions using `AND`
-func (dka *DynamoKeyAttribute[T]) AndWithCondition() func(keyConditionBuilder expression.KeyConditionBuilder) {
-	return func(keyConditionBuilder expression.KeyConditionBuilder) {
-		if dka.keyConditionBuilder.IsSet() {
-			if keyConditionBuilder.IsSet() {
-				dka.keyConditionBuilder = dka.keyConditionBuilder.And(keyConditionBuilder)
+	return condition.(expression.KeyConditionBuilder)
+}
+
+// addKeyConditions adds the key conditions grouped by key attribute into the key condition
+// builder using `AND` and returns a new key condition builder
+func addKeyConditions(keyConditions [][]any, keyConditionBuilder *expression.KeyConditionBuilder) *expression.KeyConditionBuilder {
+	newKeyConditionBuilder := keyConditionBuilder
+	for _, conditions := range keyConditions {
+		var nodeKeyConditionBuilder *expression.KeyConditionBuilder
+		for _, condition := range conditions {
+			// zero value of struct `KeyConditionBuilder` is not a condition
+			if keyConditionBuilder := renderKeyCondition(condition); !keyConditionBuilder.IsSet() {
+				continue
+			} else if nodeKeyConditionBuilder != nil {
+				nodeKeyConditionBuilder = utils.PointerTo(nodeKeyConditionBuilder.And(keyConditionBuilder))
+			} else {
+				nodeKeyConditionBuilder = &keyConditionBuilder
 			}
-		} else {
-			dka.keyConditionBuilder = keyConditionBuilder
 		}
-	}
-}
 
-func (dka *DynamoKeyAttribute[T]) addKeyCondition(keyConditionBuilder *expression.KeyConditionBuilder) (newKeyConditionBuilder *expression.KeyConditionBuilder) {
-	if dka.keyConditionBuilder.IsSet() {
-		if keyConditionBuilder != nil && (*keyConditionBuilder).IsSet() {
+		if nodeKeyConditionBuilder == nil {
+			continue
+		} else if newKeyConditionBuilder != nil {
 			// parent condition will be L.H.S
-			newKeyConditionBuilder = utils.PointerTo(keyConditionBuilder.And(dka.keyConditionBuilder))
+			newKeyConditionBuilder = utils.PointerTo(newKeyConditionBuilder.And(*nodeKeyConditionBuilder))
 		} else {
-			newKeyConditionBuilder = &dka.keyConditionBuilder
+			newKeyConditionBuilder = nodeKeyConditionBuilder
 		}
 	}
 
@@ -435,67 +501,31 @@ func (dka *DynamoKeyAttribute[T]) addKeyCondition(keyConditionBuilder *expressio
 // 2 - List of list is NOT supported currently
 // 3 - List of object is supported but list of object containing another list is NOT
 type DynamoListAttribute[T any] struct {
-	// True when build has been executed on 'this' attribute
-	// projection, Condition and Update can only be done after
-	// build is executed
-	buildExecuted bool
-
-	// Mark 'this' attribute for projection
-	projection bool
-
-	// Name of the dynamo attribute as defined in DB
-	name string
-
-	// Document path of this attribute
-	documentPath string
-
-	// Use this to build any condition on 'this' attribute
-	nameBuilder expression.NameBuilder
-
-	// Represents all the conditions applied on 'this' dynamo attribute
-	conditionBuilder expression.ConditionBuilder
+	// Node of 'this' attribute in the expression builder tree
+	// holding its name, document path, marks and list items
+	node *core.Node
 
 	// Helps in direct member selection of child attributes of list item
 	// This will also be used in creating new list item
 	listItemAccessReference T
-
-	// Determines the operation which needs to performed on
-	// 'this' attribute
-	operation DynamoOperation
-
-	// Represent the value which needs to be assigned to 'this'
-	// attribute for update
-	value any
-
-	// Defines the order of index added, this helps in maintaining
-	// order when generating projection
-	orderOfListItems []int
-
-	// List of item of 'this' list attribute
-	listAttributes map[int]interface{}
 }
 
 func NewDynamoListAttribute[T any]() *DynamoListAttribute[T] {
-	return &DynamoListAttribute[T]{
-		listAttributes: map[int]interface{}{},
-	}
-}
-
-func (dla *DynamoListAttribute[T]) constructDocumentPath(documentPathOfParent string) string {
-	if strings.HasSuffix(dla.GetName(), "]") { // this is a top level element of a list, we can skip DDBAtributeNameCancatenator
-		return documentPathOfParent + dla.GetName()
+	dla := &DynamoListAttribute[T]{node: core.NewNode(core.NODE_LIST)}
+	dla.node.SetOwner(dla)
+	dla.node.SetListItemFactory(func(index int) *core.Node {
+		// owner is the copy of `dla` held by the expression builder of the parent
+		return dla.node.Owner().(*DynamoListAttribute[T]).newListItem(index)
+	})
+	if _, ok := interface{}(dla.listItemAccessReference).(TreeBuilder[T]); ok {
+		dla.node.SetMapListItems()
 	}
-
-	if documentPathOfParent == "" { // this is root's child
-		return dla.GetName()
-	}
-
-	return documentPathOfParent + DDBAtributeNameCancatenator + dla.GetName()
+	return dla
 }
 
 // WithName builds `this` DynamoListAttribute with a dynamo db attribute name
 func (dla *DynamoListAttribute[T]) WithName(name string) *DynamoListAttribute[T] {
-	dla.name = name
+	dla.node.SetName(name)
 	return dla
 }
 
@@ -508,43 +538,19 @@ func (dla *DynamoListAttribute[T]) WithListItemAccessReference(listItemAccessRef
 	return dla
 }
 
-func (dla *DynamoListAttribute[T]) build(parentDocumentPath string) error {
-	if dla.buildExecuted {
-		return errors.New("build is already executed on attribute " + dla.documentPath)
-	}
-
-	// build document path
-	dla.documentPath = dla.constructDocumentPath(parentDocumentPath)
-
-	// build name builder
-	dla.nameBuilder = expression.Name(dla.documentPath)
-
-	for _, listItem := range dla.listAttributes {
-		switch listItemType := listItem.(type) {
-		case Builder:
-			listItemType.build(dla.documentPath)
-		}
-	}
-
-	dla.buildExecuted = true
-	return nil
-}
-
+// Project marks `this` attribute for projection
 func (dla *DynamoListAttribute[T]) Project() error {
-	if !dla.buildExecuted {
-		return errors.New("build is not yet executed on attribute [" + dla.name + "], cannot mark this attribute for projection")
-	}
-
-	dla.projection = true
-	return nil
+	defer dla.node.LockTree()()
+	return dla.node.Project()
 }
 
 func (dla *DynamoListAttribute[T]) GetName() string {
-	return dla.name
+	return dla.node.Name()
 }
 
 func (dla *DynamoListAttribute[T]) GetNameBuilder() expression.NameBuilder {
-	return dla.nameBuilder
+	defer dla.node.RLockTree()()
+	return nameBuilder(dla.node)
 }
 
 // AR returns the type `T` held by this node
@@ -553,201 +559,273 @@ func (dla *DynamoListAttribute[T]) AR() T {
 	return dla.listItemAccessReference
 }
 
-func (dla *DynamoListAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (newProjectionBuilder *expression.ProjectionBuilder, err error) {
-	if !dla.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + dla.name + "], cannot mark this attribute for projection")
-	}
-
-	if projectionBuilder == nil {
-		return nil, errors.New("nil projection builder passed for attribute " + dla.documentPath)
-	}
-
-	newProjectionBuilder = projectionBuilder
-	if dla.projection { // skipping projection of child attributes
-		return utils.PointerTo(newProjectionBuilder.AddNames(expression.Name(dla.documentPath))), nil
-	} else {
-		for _, listAttributeIndex := range dla.orderOfListItems {
-			listItem := dla.listAttributes[listAttributeIndex]
-			switch listItemType := listItem.(type) {
-			case Projector:
-				newProjectionBuilder, err = listItemType.addName(newProjectionBuilder)
-			}
-
-			if err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	return newProjectionBuilder, nil
-}
-
 // AddListItem add a node in the list
 func (dla *DynamoListAttribute[T]) AddListItem(listItemsIndex ...int) error {
-	if dla.buildExecuted {
-		return errors.New("build is already executed on attribute " + dla.documentPath)
-	}
-
-	// currently, we are not allowing nested lists
-	for _, index := range listItemsIndex {
-		dla.orderOfListItems = append(dla.orderOfListItems, index)
-		switch listItemType := interface{}(dla.listItemAccessReference).(type) {
-		case TreeBuilder[T]:
-			dla.listAttributes[index] = listItemType.BuildTree("[" + strconv.Itoa(int(index)) + "]")
-		default: // it's a primitive
-			dla.listAttributes[index] = NewDynamoAttribute[T]().WithName("[" + strconv.Itoa(int(index)) + "]")
-		}
+	defer dla.node.LockTree()()
+	return dla.node.AddListItem(listItemsIndex...)
+}
+
+// newListItem creates the node of list item at `index`
+func (dla *DynamoListAttribute[T]) newListItem(index int) *core.Node {
+	switch listItemType := interface{}(dla.listItemAccessReference).(type) {
+	case TreeBuilder[T]:
+		return listItemType.BuildTree(core.ListItemName(index)).node
+	default: // it's a primitive
+		return NewDynamoAttribute[T]().WithName(core.ListItemName(index)).node
 	}
+}
 
-	return nil
+// AddListItemRange adds the list items from index `from` up to but excluding `to`, list items
+// which are already part of the list are kept as they are
+func (dla *DynamoListAttribute[T]) AddListItemRange(from, to int) error {
+	defer dla.node.LockTree()()
+	return dla.node.AddListItemRange(from, to)
 }
 
+// Index returns the list item at `listAttributeIndex`, it is added to the list if not present.
+// For a negative index the list item returned is not part of the list, its marks are ignored and
+// building the expression builder tree fails with an error wrapping ErrUnknownListIndex. Use
+// AddListItem or AddListItemRange to get the error right away
 func (dla *DynamoListAttribute[T]) Index(listAttributeIndex int) *DynamoAttribute[T] {
+	defer dla.node.LockTree()()
 	// currently, we are limiting the type to DynamoAttribute
-	if listAttribute, ok := dla.listAttributes[listAttributeIndex].(*DynamoAttribute[T]); !ok {
-		return nil
-	} else {
-		return listAttribute
-	}
+	listItem := dla.node.IndexListItem(listAttributeIndex)
+	listAttribute, _ := listItem.Owner().(*DynamoAttribute[T])
+	return listAttribute
 }
 
-func (dla *DynamoListAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) (newConditionBuilder *expression.ConditionBuilder) {
-	if dla.conditionBuilder.IsSet() {
-		if conditionBuilder != nil && (*conditionBuilder).IsSet() {
-			newConditionBuilder = utils.PointerTo(conditionBuilder.And(dla.conditionBuilder))
-		} else {
-			newConditionBuilder = &dla.conditionBuilder
-		}
-	} else {
-		newConditionBuilder = conditionBuilder
-	}
-
-	for _, listAttributeIndex := range dla.orderOfListItems {
-		listItem := dla.listAttributes[listAttributeIndex]
-		switch listItemType := listItem.(type) {
-		case Conditioner:
-			if retConditionBuilder := listItemType.addCondition(newConditionBuilder); retConditionBuilder != nil {
-				newConditionBuilder = retConditionBuilder
-			}
-		}
-	}
-
-	return newConditionBuilder
+// ForEachIndex calls `fn` for every list item in the order they were added, e.g. to apply the
+// same projection, condition or update to the list items added by AddListItemRange. Errors
+// returned by `fn` are joined
+//
+// In concurrency mode the tree is not locked while `fn` is called, so `fn` can mark the list items
+func (dla *DynamoListAttribute[T]) ForEachIndex(fn func(index int, listItem *DynamoAttribute[T]) error) error {
+	return dla.node.ForEachListItem(func(index int, listItem *core.Node) error {
+		listAttribute, _ := listItem.Owner().(*DynamoAttribute[T])
+		return fn(index, listAttribute)
+	})
 }
 
 func (dla *DynamoListAttribute[T]) AndWithCondition() func(conditionBuilder expression.ConditionBuilder) {
 	return func(conditionBuilder expression.ConditionBuilder) {
-		if dla.conditionBuilder.IsSet() {
-			if conditionBuilder.IsSet() {
-				dla.conditionBuilder = dla.conditionBuilder.And(conditionBuilder)
-			}
-		} else {
-			dla.conditionBuilder = conditionBuilder
-		}
+		defer dla.node.LockTree()()
+		dla.node.AndWithCondition(conditionBuilder)
+	}
+}
+
+// AndWithFilter adds a new filter to `this` attributes existing filters using `AND`, see
+// DynamoAttribute.AndWithFilter
+func (dla *DynamoListAttribute[T]) AndWithFilter() func(filterBuilder expression.ConditionBuilder) {
+	return func(filterBuilder expression.ConditionBuilder) {
+		defer dla.node.LockTree()()
+		dla.node.AndWithFilter(filterBuilder)
 	}
 }
 
 func (dla *DynamoListAttribute[T]) AddValue(operation DynamoOperation, value any) {
-	dla.operation = operation
-	dla.value = value
+	defer dla.node.LockTree()()
+	dla.node.AddValue(core.Operation(operation), value)
 }
 
-func (dla *DynamoListAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (newUpdateBuilder *expression.UpdateBuilder, err error) {
-	if !dla.buildExecuted {
-		return nil, errors.New("build is not yet executed on attribute [" + dla.name + "], cannot update this list attribute")
-	}
+// Append marks `this` list for update by adding `items` to its end using list_append. Items of
+// later calls are added after the items of earlier ones and compose with the items of Prepend as
+// `list_append(:prepended, list_append(list, :appended))`, any other value marked for update on
+// `this` list is replaced
+//
+// NOTE: T of a list of maps is the expression builder of the map which can't be used as value,
+// ErrMapListItemValue is returned for such a list. Mark it using AddValue instead
+func (dla *DynamoListAttribute[T]) Append(items ...T) error {
+	defer dla.node.LockTree()()
+	return dla.node.AppendListItems(core.ListItemValues(items), false)
+}
 
-	if updateBuilder == nil {
-		return nil, errors.New("nil update builder passed for attribute " + dla.documentPath)
-	}
+// Prepend marks `this` list for update by adding `items` to its start using list_append, see
+// Append
+func (dla *DynamoListAttribute[T]) Prepend(items ...T) error {
+	defer dla.node.LockTree()()
+	return dla.node.AppendListItems(core.ListItemValues(items), true)
+}
 
-	newUpdateBuilder = updateBuilder
-	valueBuilder := expression.Value(dla.value)
+// RemoveAt marks the list items at `indices` for UPDATE_REMOVE, the list items are added to the
+// list if not present. Every index is removed once and the indices are removed in order
+func (dla *DynamoListAttribute[T]) RemoveAt(indices ...int) error {
+	defer dla.node.LockTree()()
+	return dla.node.RemoveListItems(indices...)
+}
 
-	switch dla.operation {
-	case UPDATE_SET:
-		switch valueType := interface{}(dla.value).(type) {
-		case expression.SetValueBuilder: // this is when we want to perform list_append
-			newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Set(dla.nameBuilder, valueType))
-		default:
-			newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Set(dla.nameBuilder, valueBuilder))
-		}
-	case UPDATE_REMOVE:
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Remove(dla.nameBuilder))
-	case UPDATE_ADD: // for numbers and set data structure
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Add(dla.nameBuilder, valueBuilder))
-	case UPDATE_DELETE: // for set data structure only
-		newUpdateBuilder = utils.PointerTo((newUpdateBuilder).Delete(dla.nameBuilder, valueBuilder))
-	case NO_OP:
-		for _, listAttributeIndex := range dla.orderOfListItems {
-			listItem := dla.listAttributes[listAttributeIndex]
-			switch listItemType := listItem.(type) {
-			case Updater:
-				if retUpdateBuilder, err := listItemType.addUpdate(newUpdateBuilder); err != nil {
-					return nil, err
-				} else if retUpdateBuilder != nil {
-					newUpdateBuilder = retUpdateBuilder
-				}
-			}
-		}
-	}
+// SetAt marks the list item at `index` for UPDATE_SET with `value`, the list item is added to the
+// list if not present. ErrMapListItemValue is returned for a list of maps, see Append
+func (dla *DynamoListAttribute[T]) SetAt(index int, value T) error {
+	defer dla.node.LockTree()()
+	return dla.node.SetListItem(index, value)
+}
 
-	return newUpdateBuilder, nil
+func (dla *DynamoListAttribute[T]) coreNode() *core.Node {
+	return dla.node
+}
+
+func (dla *DynamoListAttribute[T]) addName(projectionBuilder *expression.ProjectionBuilder) (*expression.ProjectionBuilder, error) {
+	return addNames(dla.node, projectionBuilder)
+}
+
+func (dla *DynamoListAttribute[T]) addCondition(conditionBuilder *expression.ConditionBuilder) *expression.ConditionBuilder {
+	return addConditions(dla.node, conditionBuilder)
+}
+
+func (dla *DynamoListAttribute[T]) addUpdate(updateBuilder *expression.UpdateBuilder) (*expression.UpdateBuilder, error) {
+	return addUpdates(dla.node, updateBuilder)
 }
 
 func NewDDBItemExpressionBuilder[T TreeBuilder[T]](treeBuilder T) DDBItemExpressionBuilder[T] {
 	return DDBItemExpressionBuilder[T]{
-		root: treeBuilder.BuildTree(""),
+		root:      treeBuilder.BuildTree(""),
+		slotScope: core.NewSlotScope(),
 	}
 }
 
 type DDBItemExpressionBuilder[T any] struct {
 	// root of the ddb item
 	root *DynamoAttribute[T]
+
+	// Validate the tree in BuildProjectionBuilder and BuildUpdateBuilder
+	validation bool
+
+	// Mode in which conflicting marks are handled
+	markMode MarkMode
+
+	// Scope of the slots created by Slot, shared with the clones of this expression builder
+	slotScope *core.SlotScope
 }
 
 func (d DDBItemExpressionBuilder[T]) DDBItemRoot() *DynamoAttribute[T] {
 	return d.root
 }
 
-// Build creates additional tree structure for attributes of List/Set data type
-// this has to be invoked before any builder can be built
+// Build is optional, document paths are computed on demand and list items can be added at any
+// time. It is kept for compatibility, does nothing and always returns nil
 func (d DDBItemExpressionBuilder[T]) Build() error {
-	return d.root.build("")
+	return nil
 }
 
 // BuildProjectionBuilder builds a ProjectionBuilder by aggregating all the projection of this
 // expression builder tree
 func (d DDBItemExpressionBuilder[T]) BuildProjectionBuilder() (*expression.ProjectionBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildProjectionBuilder()
+	}
+
+	if err := d.markConflicts(MARK_CONFLICT_SHADOWED_PROJECTION); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return nil, err
+	}
+
 	return d.root.addName(&expression.ProjectionBuilder{})
 }
 
 // BuildKeyConditionBuilder builds a KeyConditionBuilder by aggregating all the KeyCondition of this
-// expression builder tree
+// expression builder tree. It doesn't return an error, key conditions are validated by the
+// builders which do or by calling Validate
 func (d DDBItemExpressionBuilder[T]) BuildKeyConditionBuilder() *expression.KeyConditionBuilder {
-	keyConditionBuilder := &expression.KeyConditionBuilder{}
-	// key condition will always be top level attribute, so we can directly traverse root's child attribute only
-	// instead of a recusrsive solution
-	for _, childAttribute := range d.root.childAttributes {
-		switch childAttributeType := childAttribute.(type) {
-		case KeyConditioner:
-			if newKeyConditionBuilder := childAttributeType.addKeyCondition(keyConditionBuilder); newKeyConditionBuilder != nil {
-				keyConditionBuilder = newKeyConditionBuilder
-			}
-		}
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildKeyConditionBuilder()
 	}
 
-	return keyConditionBuilder
+	return d.keyConditionBuilder()
 }
 
 // BuildConditionBuilder builds a ConditionBuilder by aggregating all the condition of this
-// expression builder tree
+// expression builder tree. It doesn't return an error, conditions are validated by the builders
+// which do or by calling Validate
 func (d DDBItemExpressionBuilder[T]) BuildConditionBuilder() *expression.ConditionBuilder {
-	return d.root.addCondition(&expression.ConditionBuilder{})
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildConditionBuilder()
+	}
+
+	return d.conditionBuilder()
+}
+
+// BuildFilterBuilder builds a ConditionBuilder by aggregating all the filters of this
+// expression builder tree, to be used as the filter of a scan. Use BuildQueryFilterBuilder for
+// the filter of a query
+func (d DDBItemExpressionBuilder[T]) BuildFilterBuilder() (*expression.ConditionBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildFilterBuilder()
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return nil, err
+	}
+
+	return addFilters(d.root.node, nil), nil
+}
+
+// BuildQueryFilterBuilder builds the ConditionBuilder of BuildFilterBuilder to be used as the
+// filter of a query, filters marked on key attributes are rejected with ErrKeyAttributeFilter.
+// Use key conditions instead
+func (d DDBItemExpressionBuilder[T]) BuildQueryFilterBuilder() (*expression.ConditionBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildQueryFilterBuilder()
+	}
+
+	if err := d.root.node.CheckQueryFilters(); err != nil {
+		return nil, err
+	}
+
+	return d.BuildFilterBuilder()
 }
 
 // BuildUpdateBuilder builds a UpdateBuilder by aggregating all the update operation of this
 // expression builder tree
 func (d DDBItemExpressionBuilder[T]) BuildUpdateBuilder() (*expression.UpdateBuilder, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildUpdateBuilder()
+	}
+
+	if err := d.markConflicts(MARK_CONFLICT_SHADOWED_UPDATE, MARK_CONFLICT_REPLACED_UPDATE); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return nil, err
+	}
+
 	return d.root.addUpdate(&expression.UpdateBuilder{})
 }
+
+// checkBuild returns the errors of list items Index couldn't add to this expression builder tree
+// and runs Validate if it is built with validation, every builder returning an error runs it
+func (d DDBItemExpressionBuilder[T]) checkBuild() error {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().checkBuild()
+	}
+
+	if err := d.root.node.IndexErrors(); err != nil {
+		return err
+	}
+
+	if !d.validation {
+		return nil
+	}
+
+	return d.Validate()
+}
+
+// keyConditionBuilder aggregates the key conditions of this expression builder tree, nil when
+// nothing is marked
+func (d DDBItemExpressionBuilder[T]) keyConditionBuilder() *expression.KeyConditionBuilder {
+	return addKeyConditions(d.root.node.KeyConditions(), nil)
+}
+
+// conditionBuilder aggregates the conditions of this expression builder tree, nil when nothing is
+// marked
+func (d DDBItemExpressionBuilder[T]) conditionBuilder() *expression.ConditionBuilder {
+	return d.root.addCondition(nil)
+}
+
+// keyAttributeNames returns the name of all the key attributes of this expression builder tree
+func (d DDBItemExpressionBuilder[T]) keyAttributeNames() []string {
+	return d.root.node.KeyAttributeNames()
+}
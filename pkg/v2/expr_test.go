@@ -2,6 +2,7 @@ package v2
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -483,3 +484,99 @@ func TestAttributeUpdate(t *testing.T) {
 		assert.Equal(t, exprectedUpdateExpression, *expr.Update())
 	}
 }
+
+// Testing that the tree can be marked and list items added in any order without Build
+func TestLazyBuild(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	if err := rootExpBldr.Name.Project(); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	// list items can be added after Build which is optional and idempotent
+	assert.Nil(t, expBuilder.Build())
+	assert.Nil(t, expBuilder.Build())
+	if err := rootExpBldr.BankDetails.AR().Accounts.AddListItem(2); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	accountType := rootExpBldr.BankDetails.AR().Accounts.Index(2).AR().AccountType
+	accountType.Project()
+	assert.Equal(t, "bank_details.accounts[2].account_type", accountType.DocumentPath())
+
+	projBuilder, err := expBuilder.BuildProjectionBuilder()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expr, err := expression.NewBuilder().WithProjection(*projBuilder).Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "#0, #1, #2, #3.#4[2].#5", *expr.Projection())
+}
+
+// Testing filters which are built separately from conditions
+func TestFilters(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value("person#1")))
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().AttributeExists())
+	rootExpBldr.FamilyDetails.AR().IsMarried.AndWithFilter()(rootExpBldr.FamilyDetails.AR().IsMarried.GetNameBuilder().Equal(expression.Value(true)))
+	rootExpBldr.PhoneNos.AndWithFilter()(rootExpBldr.PhoneNos.GetNameBuilder().Size().GreaterThan(expression.Value(1)))
+
+	explanation, err := expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, strings.Join([]string{
+		`KeyCondition: pk = "person#1"`,
+		`Condition: attribute_exists (name)`,
+		`Filter: (family_details.is_married = true) AND (size (phone_nos) > 1)`,
+		`Projection: pk, sk`,
+	}, "\n"), explanation)
+
+	walked := map[string]Marks{}
+	Walk(expBuilder.DDBItemRoot(), func(node Node) error {
+		walked[node.DocumentPath()] = node.Marks()
+		return nil
+	})
+	assert.Equal(t, 1, walked["phone_nos"].Filters)
+	assert.Equal(t, 0, walked["phone_nos"].Conditions)
+	assert.Equal(t, 0, walked["name"].Filters)
+
+	// key attributes can be filtered in a scan but not in a query
+	rootExpBldr.SK.AndWithFilter()(expression.Name("sk").BeginsWith("profile#"))
+	filterBuilder, err := expBuilder.BuildFilterBuilder()
+	assert.Nil(t, err)
+	assert.NotNil(t, filterBuilder)
+	_, err = expBuilder.BuildQueryFilterBuilder()
+	assert.ErrorIs(t, err, ErrKeyAttributeFilter)
+	assert.EqualError(t, err, "cannot filter attribute [sk]: key attribute cannot be used in filter")
+
+	expBuilder.Reset()
+	filterBuilder, err = expBuilder.BuildQueryFilterBuilder()
+	assert.Nil(t, err)
+	assert.Nil(t, filterBuilder)
+	assert.Nil(t, expBuilder.BuildConditionBuilder())
+
+	names := map[string]string{"#pk": "pk", "#name": "name"}
+	values := map[string]types.AttributeValue{":pk": &types.AttributeValueMemberS{Value: "person#1"}, ":name": &types.AttributeValueMemberS{Value: "John"}}
+	// conjuncts of a parsed filter are marked on the key attributes they refer to
+	if err := expBuilder.ParseExpression(EXPRESSION_FILTER, "#name = :name AND #pk = :pk", names, values); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	_, err = expBuilder.BuildQueryFilterBuilder()
+	assert.ErrorIs(t, err, ErrKeyAttributeFilter)
+	explanation, err = expBuilder.Explain()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "Filter: (pk = \"person#1\") AND (name = \"John\")\nProjection: pk, sk", explanation)
+}
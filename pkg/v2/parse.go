@@ -0,0 +1,135 @@
+package v2
+
+import (
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type ExpressionKind = core.ExpressionKind
+
+const (
+	EXPRESSION_KEY_CONDITION = core.EXPRESSION_KEY_CONDITION
+	EXPRESSION_CONDITION     = core.EXPRESSION_CONDITION
+	EXPRESSION_PROJECTION    = core.EXPRESSION_PROJECTION
+	EXPRESSION_UPDATE        = core.EXPRESSION_UPDATE
+	EXPRESSION_FILTER        = core.EXPRESSION_FILTER
+)
+
+// UnknownPathsError is returned by ParseExpression when document paths of the expression
+// don't exist in the expression builder tree
+type UnknownPathsError = ddbexpr.UnknownPathsError
+
+// ParseExpression parses an existing expression of `kind` and marks it on this expression
+// builder tree, names and values are the ExpressionAttributeNames and ExpressionAttributeValues
+// of the expression
+//
+// Every document path of the expression is resolved to a node of the tree, list items which
+// are not yet part of the tree are added to it. If document paths don't exist in the tree an
+// *UnknownPathsError listing all of them is returned and the tree is left unchanged
+//
+// Top level conjuncts of a condition or filter are marked on the attribute of their first
+// document path, or on the root when that is a key attribute of a condition
+func (d DDBItemExpressionBuilder[T]) ParseExpression(kind ExpressionKind, expr string, names map[string]string, values map[string]types.AttributeValue) error {
+	defer d.root.node.LockTree()()
+	return d.root.node.ParseExpression(kind, expr, names, sdkv2.FromItem(values))
+}
+
+// renderParsedCondition converts a condition of a parsed expression into a condition builder
+func renderParsedCondition(parsed core.ParsedCondition) expression.ConditionBuilder {
+	condition := parsed.Condition
+	switch condition.Kind {
+	case ddbexpr.CONDITION_AND, ddbexpr.CONDITION_OR, ddbexpr.CONDITION_NOT:
+		conditionBuilders := make([]expression.ConditionBuilder, 0, len(condition.Conditions))
+		for _, subCondition := range condition.Conditions {
+			conditionBuilders = append(conditionBuilders, renderParsedCondition(core.ParsedCondition{Condition: subCondition, Values: parsed.Values}))
+		}
+
+		switch condition.Kind {
+		case ddbexpr.CONDITION_AND:
+			return expression.And(conditionBuilders[0], conditionBuilders[1], conditionBuilders[2:]...)
+		case ddbexpr.CONDITION_OR:
+			return expression.Or(conditionBuilders[0], conditionBuilders[1], conditionBuilders[2:]...)
+		default:
+			return expression.Not(conditionBuilders[0])
+		}
+	case ddbexpr.CONDITION_FUNCTION:
+		nameBuilder := expression.Name(condition.Operands[0].Path.String())
+		switch condition.Operator {
+		case "attribute_exists":
+			return expression.AttributeExists(nameBuilder)
+		case "attribute_not_exists":
+			return expression.AttributeNotExists(nameBuilder)
+		case "attribute_type":
+			return expression.AttributeType(nameBuilder, expression.DynamoDBAttributeType(parsed.Value(condition.Operands[1]).String))
+		case "begins_with":
+			return expression.BeginsWith(nameBuilder, parsed.Value(condition.Operands[1]).String)
+		default:
+			return expression.Contains(nameBuilder, parsed.Value(condition.Operands[1]).String)
+		}
+	}
+
+	operandBuilders := make([]expression.OperandBuilder, 0, len(condition.Operands))
+	for _, operand := range condition.Operands {
+		switch operand.Kind {
+		case ddbexpr.OPERAND_PATH:
+			operandBuilders = append(operandBuilders, expression.Name(operand.Path.String()))
+		case ddbexpr.OPERAND_SIZE:
+			operandBuilders = append(operandBuilders, expression.Name(operand.Path.String()).Size())
+		default:
+			operandBuilders = append(operandBuilders, expression.Value(renderValue(core.RawValue{Value: parsed.Value(operand)})))
+		}
+	}
+
+	switch {
+	case condition.Kind == ddbexpr.CONDITION_BETWEEN:
+		return expression.Between(operandBuilders[0], operandBuilders[1], operandBuilders[2])
+	case condition.Kind == ddbexpr.CONDITION_IN:
+		return expression.In(operandBuilders[0], operandBuilders[1], operandBuilders[2:]...)
+	case condition.Operator == "=":
+		return expression.Equal(operandBuilders[0], operandBuilders[1])
+	case condition.Operator == "<>":
+		return expression.NotEqual(operandBuilders[0], operandBuilders[1])
+	case condition.Operator == "<":
+		return expression.LessThan(operandBuilders[0], operandBuilders[1])
+	case condition.Operator == "<=":
+		return expression.LessThanEqual(operandBuilders[0], operandBuilders[1])
+	case condition.Operator == ">":
+		return expression.GreaterThan(operandBuilders[0], operandBuilders[1])
+	default:
+		return expression.GreaterThanEqual(operandBuilders[0], operandBuilders[1])
+	}
+}
+
+// renderParsedKeyCondition converts a key condition of a parsed expression into a key
+// condition builder
+func renderParsedKeyCondition(parsed core.ParsedCondition) expression.KeyConditionBuilder {
+	condition := parsed.Condition
+	keyBuilder := expression.Key(condition.Operands[0].Path.String())
+	if condition.Kind == ddbexpr.CONDITION_FUNCTION {
+		return expression.KeyBeginsWith(keyBuilder, parsed.Value(condition.Operands[1]).String)
+	}
+
+	valueBuilders := make([]expression.ValueBuilder, 0, len(condition.Operands)-1)
+	for _, operand := range condition.Operands[1:] {
+		valueBuilders = append(valueBuilders, expression.Value(renderValue(core.RawValue{Value: parsed.Value(operand)})))
+	}
+
+	switch {
+	case condition.Kind == ddbexpr.CONDITION_BETWEEN:
+		return expression.KeyBetween(keyBuilder, valueBuilders[0], valueBuilders[1])
+	case condition.Operator == "=":
+		return expression.KeyEqual(keyBuilder, valueBuilders[0])
+	case condition.Operator == "<":
+		return expression.KeyLessThan(keyBuilder, valueBuilders[0])
+	case condition.Operator == "<=":
+		return expression.KeyLessThanEqual(keyBuilder, valueBuilders[0])
+	case condition.Operator == ">":
+		return expression.KeyGreaterThan(keyBuilder, valueBuilders[0])
+	default:
+		return expression.KeyGreaterThanEqual(keyBuilder, valueBuilders[0])
+	}
+}
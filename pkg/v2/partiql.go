@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"errors"
+
+	"github.com/gauxs/dynexpr/internal/ddbexpr"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type PartiQLStatementKind = ddbexpr.PartiQLStatementKind
+
+const (
+	PARTIQL_SELECT = ddbexpr.PARTIQL_SELECT
+	PARTIQL_UPDATE = ddbexpr.PARTIQL_UPDATE
+	PARTIQL_DELETE = ddbexpr.PARTIQL_DELETE
+)
+
+// BuildPartiQL renders the marked tree as a parameterised PartiQL statement which can be used
+// with ExecuteStatement, parameters are returned in the order of their `?` in the statement
+//
+// Projections become the SELECT columns, key attributes are always projected. Key conditions and
+// conditions become the WHERE clause and updates become the SET/REMOVE clauses of UPDATE. Filters
+// are part of the WHERE clause of SELECT only, so it returns the items of the equivalent query or
+// scan.
+// UPDATE and DELETE require a WHERE clause. ADD of a number and if_not_exists, e.g. of
+// SetIfNotExists, return ErrUnsupportedPartiQL
+func (d DDBItemExpressionBuilder[T]) BuildPartiQL(kind PartiQLStatementKind, tableName string) (string, []types.AttributeValue, error) {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().BuildPartiQL(kind, tableName)
+	}
+
+	exprBuilder := expression.NewBuilder()
+	isSet := false
+	switch kind {
+	case PARTIQL_SELECT:
+		projectionBuilder, err := d.BuildProjectionBuilder()
+		if err != nil {
+			return "", nil, err
+		}
+
+		exprBuilder, isSet = exprBuilder.WithProjection(*projectionBuilder), true
+
+		filterBuilder, err := d.BuildFilterBuilder()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if filterBuilder != nil {
+			exprBuilder = exprBuilder.WithFilter(*filterBuilder)
+		}
+	case PARTIQL_UPDATE:
+		updateBuilder, err := d.BuildUpdateBuilder()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if _, err := expression.NewBuilder().WithUpdate(*updateBuilder).Build(); err == nil {
+			exprBuilder, isSet = exprBuilder.WithUpdate(*updateBuilder), true
+		} else if !errors.As(err, &expression.UnsetParameterError{}) { // no attribute is marked for update
+			return "", nil, err
+		}
+	}
+
+	if err := d.checkBuild(); err != nil {
+		return "", nil, err
+	}
+
+	if keyConditionBuilder := d.BuildKeyConditionBuilder(); keyConditionBuilder != nil {
+		exprBuilder, isSet = exprBuilder.WithKeyCondition(*keyConditionBuilder), true
+	}
+
+	if conditionBuilder := d.BuildConditionBuilder(); conditionBuilder != nil {
+		exprBuilder, isSet = exprBuilder.WithCondition(*conditionBuilder), true
+	}
+
+	expr, err := buildOptional(exprBuilder, isSet)
+	if err != nil {
+		return "", nil, err
+	}
+
+	statement, values, err := fromExpression(expr).PartiQL(kind, tableName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	params := make([]types.AttributeValue, 0, len(values))
+	for _, value := range values {
+		params = append(params, sdkv2.ToAttributeValue(value))
+	}
+
+	return statement, params, nil
+}
@@ -0,0 +1,122 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing update expression generated from a partial person
+func TestApplyPatch(t *testing.T) {
+	patch := &Person{
+		PK:            utils.PointerTo("person#1"),
+		SK:            utils.PointerTo("details"),
+		FamilyDetails: &FamilyDetail{IsMarried: utils.PointerTo(true)},
+		PhoneNos:      &[]*string{utils.PointerTo("111")},
+	}
+
+	// nested structs are patched field by field and null is set
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	if err := expBuilder.ApplyPatch(patch, WithNull("name")); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expr, err := buildUpdate(expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, "SET #0 = :0, #1.#2 = :1, #3 = :2\n", *expr.Update())
+	assert.Equal(t, map[string]string{
+		"#0": "name",
+		"#1": "family_details",
+		"#2": "is_married",
+		"#3": "phone_nos",
+	}, expr.Names())
+	assert.Equal(t, map[string]types.AttributeValue{
+		":0": &types.AttributeValueMemberNULL{Value: true},
+		":1": &types.AttributeValueMemberBOOL{Value: true},
+		":2": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "111"}}},
+	}, expr.Values())
+
+	// explicit nulls are removed and nested structs replace the whole map
+	expBuilder = NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	if err := expBuilder.ApplyPatch(patch, WithNull("name"), WithNullAsRemove(), WithReplaceNested()); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expr, err = buildUpdate(expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	assert.Equal(t, "REMOVE #0\nSET #1 = :0, #2 = :1\n", *expr.Update())
+	assert.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"is_married": &types.AttributeValueMemberBOOL{Value: true}}},
+		expr.Values()[":0"])
+
+	// patch must be a struct
+	assert.ErrorContains(t, expBuilder.ApplyPatch("name"), "patch must be a struct or a pointer to struct")
+
+	// nulls must refer to attributes which can be updated
+	assert.ErrorIs(t, expBuilder.ApplyPatch(patch, WithNull("nickname")), ErrUnknownAttribute)
+	assert.ErrorContains(t, expBuilder.ApplyPatch(patch, WithNull("pk")), "cannot set null on attribute [pk]: key attribute cannot be updated")
+
+	// a failing patch leaves the tree unchanged, including the list items of its null paths
+	expBuilder = NewPerson_ExpressionBuilder()
+	assert.ErrorIs(t, expBuilder.ApplyPatch(patch, WithNull("phone_nos[2]"), WithNull("nickname")), ErrUnknownAttribute)
+	assert.Empty(t, expBuilder.DDBItemRoot().AR().PhoneNos.Children())
+	assert.Equal(t, NO_OP, expBuilder.DDBItemRoot().AR().PhoneNos.Marks().Operation)
+	assert.Equal(t, NO_OP, expBuilder.DDBItemRoot().AR().FamilyDetails.AR().IsMarried.Marks().Operation)
+}
+
+// Testing that zero values of fields which aren't pointers leave their attributes unchanged
+func TestApplyPatchZeroValues(t *testing.T) {
+	type familyDetailPatch struct {
+		IsMarried bool `dynamodbav:"is_married"`
+	}
+	type personPatch struct {
+		Name          string            `dynamodbav:"name"`
+		FamilyDetails familyDetailPatch `dynamodbav:"family_details"`
+	}
+
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	if err := expBuilder.ApplyPatch(personPatch{Name: "", FamilyDetails: familyDetailPatch{IsMarried: false}}); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, NO_OP, rootExpBldr.Name.Marks().Operation)
+	assert.Equal(t, NO_OP, rootExpBldr.FamilyDetails.AR().IsMarried.Marks().Operation)
+
+	// the zero value is only set through WithNull or a pointer field
+	if err := expBuilder.ApplyPatch(personPatch{Name: "New Name"}, WithNull("family_details.is_married")); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	expr, err := buildUpdate(expBuilder)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "SET #0 = :0, #1.#2 = :1\n", *expr.Update())
+}
+
+func buildUpdate[T any](expBuilder DDBItemExpressionBuilder[T]) (expression.Expression, error) {
+	updateBuilder, err := expBuilder.BuildUpdateBuilder()
+	if err != nil {
+		return expression.Expression{}, err
+	}
+
+	return expression.NewBuilder().WithUpdate(*updateBuilder).Build()
+}
@@ -0,0 +1,16 @@
+package v2
+
+// Path returns the node of the attribute at document path `path`, e.g.
+// "bank_details.accounts[3].bank_account_number". List items which are not yet part of the
+// tree are added to it once the whole of `path` is resolved. A *PathError naming the first
+// unknown element is returned if `path` doesn't exist in this expression builder tree, or
+// naming the whole of `path` if it isn't a valid document path
+func (d DDBItemExpressionBuilder[T]) Path(path string) (Node, error) {
+	defer d.root.node.LockTree()()
+	node, err := d.root.node.PathNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Owner().(Node), nil
+}
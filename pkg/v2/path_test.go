@@ -0,0 +1,64 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that nodes are looked up by document path and list items are added on demand
+func TestPath(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	expBuilder.Build()
+
+	node, err := expBuilder.Path("bank_details.accounts[3].bank_account_number")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.Equal(t, "bank_details.accounts[3].bank_account_number", node.DocumentPath())
+	assert.Same(t, &rootExpBldr.BankDetails.AR().Accounts.Index(3).AR().BankAccountNumber, node)
+
+	if err := node.Project(); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	assert.True(t, rootExpBldr.BankDetails.AR().Accounts.Index(3).AR().BankAccountNumber.Marks().Projection)
+
+	node, err = expBuilder.Path("pk")
+	if assert.NoError(t, err) {
+		assert.Equal(t, NODE_KEY, node.Kind())
+	}
+
+	node, err = expBuilder.Path("phone_nos[0]")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "phone_nos[0]", node.DocumentPath())
+	}
+
+	_, err = expBuilder.Path("bank_details.cards[0]")
+	assert.EqualError(t, err, "cannot resolve attribute [bank_details.cards]: unknown attribute")
+	assert.ErrorIs(t, err, ErrUnknownAttribute)
+	_, err = expBuilder.Path("name[0]")
+	assert.ErrorIs(t, err, ErrUnknownListIndex)
+	_, err = expBuilder.Path("bank_details.accounts.account_type")
+	var pathErr *PathError
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, "bank_details.accounts.account_type", pathErr.Path)
+	}
+
+	// invalid document paths are reported with the whole path
+	for _, path := range []string{"phone_nos[-1]", "", "bank_details..name"} {
+		_, err = expBuilder.Path(path)
+		if assert.ErrorAs(t, err, &pathErr, path) {
+			assert.Equal(t, path, pathErr.Path)
+			assert.Equal(t, "path", pathErr.Op)
+			assert.NotNil(t, pathErr.Err)
+		}
+	}
+
+	// list items are only added once the whole path is resolved
+	_, err = expBuilder.Path("bank_details.accounts[5].unknown")
+	assert.ErrorIs(t, err, ErrUnknownAttribute)
+	assert.Len(t, rootExpBldr.BankDetails.AR().Accounts.Children(), 1)
+}
@@ -0,0 +1,16 @@
+package v2
+
+import (
+	"reflect"
+)
+
+// ProjectFor marks for projection exactly the attributes of the expression builder tree which
+// match the fields of `DTO`, fields are matched by their dynamodbav tag like the marshaller does
+//
+// Nested structs of DTO project only their own fields, rest of the fields are projected as a
+// whole. Returns an error if a field of DTO has no attribute in the expression builder tree, in
+// which case nothing is marked
+func ProjectFor[DTO any, T any](d DDBItemExpressionBuilder[T]) error {
+	defer d.root.node.LockTree()()
+	return d.root.node.ProjectFor(reflect.TypeOf((*DTO)(nil)).Elem())
+}
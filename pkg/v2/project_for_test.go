@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+type familySummary struct {
+	IsMarried *bool `json:"is_married,omitempty" dynamodbav:"is_married,omitempty"`
+}
+
+type personSummary struct {
+	Name          *string        `json:"name,omitempty" dynamodbav:"name,omitempty"`
+	FamilyDetails *familySummary `json:"family_details,omitempty" dynamodbav:"family_details,omitempty"`
+	PhoneNos      []string       `json:"phone_nos,omitempty" dynamodbav:"phone_nos,omitempty"`
+	Ignored       string         `json:"-" dynamodbav:"-"`
+}
+
+// Testing projection of the fields of a DTO
+func TestProjectFor(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	expBuilder.Build()
+	if err := ProjectFor[*personSummary](expBuilder); err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	projectionBuilder, err := expBuilder.BuildProjectionBuilder()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expr, err := expression.NewBuilder().WithProjection(*projectionBuilder).Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	// key attributes are always projected
+	assert.Equal(t, "#0, #1, #2, #3.#4, #5", *expr.Projection())
+	assert.Equal(t, "family_details", expr.Names()["#3"])
+	assert.Equal(t, "is_married", expr.Names()["#4"])
+	assert.Equal(t, "phone_nos", expr.Names()["#5"])
+
+	// field without counterpart in the item, the known fields aren't marked either
+	type unknownSummary struct {
+		Name     *string `dynamodbav:"name"`
+		Nickname *string `dynamodbav:"nickname"`
+	}
+	unknownExpBuilder := NewPerson_ExpressionBuilder()
+	unknownExpBuilder.Build()
+	assert.ErrorIs(t, ProjectFor[unknownSummary](unknownExpBuilder), ErrUnknownAttribute)
+	assert.False(t, unknownExpBuilder.DDBItemRoot().AR().Name.Marks().Projection)
+	assert.ErrorContains(t, ProjectFor[unknownSummary](expBuilder), "cannot project attribute [nickname]: field Nickname of v2.unknownSummary has no attribute in expression builder")
+	assert.ErrorContains(t, ProjectFor[string](expBuilder), "DTO must be a struct or a pointer to struct")
+}
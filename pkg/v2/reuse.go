@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"github.com/gauxs/dynexpr/internal/core"
+)
+
+// Reset clears every mark and list item of this expression builder tree while keeping the nodes
+// of its attributes, the tree is left as if it was newly created. Nodes of list items obtained
+// before Reset must not be used
+func (d DDBItemExpressionBuilder[T]) Reset() {
+	defer d.root.node.LockTree()()
+	d.root.node.Reset()
+}
+
+// Clone returns a deep copy of this expression builder tree including its list items, marks
+// and options. Values added for update, conditions and filters are deep copied, except for what
+// is reachable only through unexported fields, e.g. of the condition builders of the sdk which
+// are never modified in place
+func (d DDBItemExpressionBuilder[T]) Clone() DDBItemExpressionBuilder[T] {
+	if d.root.node.Lock() != nil {
+		return d.snapshot().WithConcurrency()
+	}
+
+	return d.clone()
+}
+
+// clone returns a deep copy of this expression builder tree, see Clone
+func (d DDBItemExpressionBuilder[T]) clone() DDBItemExpressionBuilder[T] {
+	// root's access reference is the tree builder the tree was created with
+	treeBuilder := interface{}(d.root.accessReference).(TreeBuilder[T])
+
+	clone := d
+	clone.root = treeBuilder.BuildTree("")
+	d.root.node.CopyTo(clone.root.node)
+
+	return clone
+}
+
+// DDBItemExpressionBuilderPool reuses the expression builder trees of a DDB item type, trees put
+// back into the pool are Reset so that high QPS services don't create large trees per request
+type DDBItemExpressionBuilderPool[T TreeBuilder[T]] struct {
+	pool *core.Pool[DDBItemExpressionBuilder[T]]
+}
+
+func NewDDBItemExpressionBuilderPool[T TreeBuilder[T]](treeBuilder T) *DDBItemExpressionBuilderPool[T] {
+	newExpressionBuilder := func() DDBItemExpressionBuilder[T] {
+		return NewDDBItemExpressionBuilder(treeBuilder)
+	}
+
+	return &DDBItemExpressionBuilderPool[T]{
+		pool: core.NewPool(newExpressionBuilder, DDBItemExpressionBuilder[T].Reset),
+	}
+}
+
+// Get returns an expression builder from the pool. An expression builder which was put back
+// keeps its options, i.e. validation, mark mode and concurrency mode, while a newly created one
+// has default options
+func (p *DDBItemExpressionBuilderPool[T]) Get() DDBItemExpressionBuilder[T] {
+	return p.pool.Get()
+}
+
+// Put resets the expression builder and puts it back into the pool along with its options,
+// neither the expression builder nor the nodes of its attributes must be used afterwards
+func (p *DDBItemExpressionBuilderPool[T]) Put(d DDBItemExpressionBuilder[T]) {
+	p.pool.Put(d)
+}
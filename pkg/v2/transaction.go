@@ -0,0 +1,228 @@
+package v2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// Maximum number of items allowed by dynamo db in a single TransactWriteItems request
+	MaxTransactWriteItems = core.MaxTransactWriteItems
+)
+
+type TransactWriteOperation = core.TransactWriteOperation
+
+const (
+	TRANSACT_PUT             = core.TRANSACT_PUT
+	TRANSACT_UPDATE          = core.TRANSACT_UPDATE
+	TRANSACT_DELETE          = core.TRANSACT_DELETE
+	TRANSACT_CONDITION_CHECK = core.TRANSACT_CONDITION_CHECK
+)
+
+// Represents a single operation of a TransactWriteItems request
+type transactWriteItem struct {
+	// Operation which needs to be performed on the item
+	operation TransactWriteOperation
+
+	// Table in which the item is stored
+	tableName string
+
+	// Whole item for TRANSACT_PUT and key of the item for
+	// rest of the operations
+	value any
+
+	// Expression builder holding condition and update of the item
+	itemExpressionBuilder ItemExpressionBuilder
+}
+
+// TransactionBuilder builds a TransactWriteItemsInput from expression builders of
+// one or more DDB items, DDB items can be of different types
+type TransactionBuilder struct {
+	items []transactWriteItem
+}
+
+func NewTransactionBuilder() *TransactionBuilder {
+	return &TransactionBuilder{
+		items: []transactWriteItem{},
+	}
+}
+
+// WithItem adds an operation on a DDB item to `this` transaction
+//
+// value is the whole item for TRANSACT_PUT and the key of the item for rest of
+// the operations, it can either be a struct or a map[string]types.AttributeValue.
+// Condition of the item is taken from the expression builder and for TRANSACT_UPDATE
+// update is also taken from the expression builder
+func (tb *TransactionBuilder) WithItem(operation TransactWriteOperation, tableName string, value any, itemExpressionBuilder ItemExpressionBuilder) *TransactionBuilder {
+	tb.items = append(tb.items, transactWriteItem{
+		operation:             operation,
+		tableName:             tableName,
+		value:                 value,
+		itemExpressionBuilder: itemExpressionBuilder,
+	})
+	return tb
+}
+
+// Build builds a TransactWriteItemsInput, expression names and values of every item are
+// built separately
+//
+// Returns an error if number of items are more than MaxTransactWriteItems or if more than
+// one operation targets the same item
+func (tb *TransactionBuilder) Build() (*dynamodb.TransactWriteItemsInput, error) {
+	itemValues := make([]map[string]types.AttributeValue, 0, len(tb.items))
+	coreItems := make([]core.TransactWriteItem, 0, len(tb.items))
+	for idx, item := range tb.items {
+		if item.itemExpressionBuilder == nil {
+			return nil, fmt.Errorf("nil expression builder passed for item %d of transaction", idx)
+		}
+
+		itemValue, err := marshalItem(item.value)
+		if err != nil {
+			return nil, fmt.Errorf("item %d of transaction: %w", idx, err)
+		}
+
+		itemValues = append(itemValues, itemValue)
+		coreItems = append(coreItems, core.TransactWriteItem{
+			TableName:         item.tableName,
+			Item:              sdkv2.FromItem(itemValue),
+			KeyAttributeNames: item.itemExpressionBuilder.keyAttributeNames(),
+		})
+	}
+
+	keys, err := core.TransactWriteKeys(coreItems)
+	if err != nil {
+		return nil, err
+	}
+
+	transactItems := make([]types.TransactWriteItem, 0, len(tb.items))
+	for idx, item := range tb.items {
+		transactItem, err := item.build(itemValues[idx], sdkv2.ToItem(keys[idx]))
+		if err != nil {
+			return nil, fmt.Errorf("item %d of transaction: %w", idx, err)
+		}
+
+		transactItems = append(transactItems, transactItem)
+	}
+
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	}, nil
+}
+
+func (ti transactWriteItem) build(itemValue, key map[string]types.AttributeValue) (types.TransactWriteItem, error) {
+	exprBuilder := expression.NewBuilder()
+	if err := ti.itemExpressionBuilder.checkBuild(); err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	conditionBuilder := ti.itemExpressionBuilder.BuildConditionBuilder()
+	if conditionBuilder != nil {
+		exprBuilder = exprBuilder.WithCondition(*conditionBuilder)
+	}
+
+	switch ti.operation {
+	case TRANSACT_PUT:
+		expr, err := buildOptional(exprBuilder, conditionBuilder != nil)
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		return types.TransactWriteItem{
+			Put: &types.Put{
+				TableName:                 utils.PointerTo(ti.tableName),
+				Item:                      itemValue,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+		}, nil
+	case TRANSACT_UPDATE:
+		updateBuilder, err := ti.itemExpressionBuilder.BuildUpdateBuilder()
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		expr, err := exprBuilder.WithUpdate(*updateBuilder).Build()
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		return types.TransactWriteItem{
+			Update: &types.Update{
+				TableName:                 utils.PointerTo(ti.tableName),
+				Key:                       key,
+				UpdateExpression:          expr.Update(),
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+		}, nil
+	case TRANSACT_DELETE:
+		expr, err := buildOptional(exprBuilder, conditionBuilder != nil)
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		return types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName:                 utils.PointerTo(ti.tableName),
+				Key:                       key,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+		}, nil
+	case TRANSACT_CONDITION_CHECK:
+		if conditionBuilder == nil {
+			return types.TransactWriteItem{}, errors.New("condition check requires a condition on the item")
+		}
+
+		expr, err := exprBuilder.Build()
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		return types.TransactWriteItem{
+			ConditionCheck: &types.ConditionCheck{
+				TableName:                 utils.PointerTo(ti.tableName),
+				Key:                       key,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+		}, nil
+	default:
+		return types.TransactWriteItem{}, errors.New("unsupported transact write operation " + ti.operation.String())
+	}
+}
+
+// buildOptional builds the expression only if something was added to the builder,
+// building an empty builder gives error
+func buildOptional(exprBuilder expression.Builder, isSet bool) (expression.Expression, error) {
+	if !isSet {
+		return expression.Expression{}, nil
+	}
+
+	return exprBuilder.Build()
+}
+
+// marshalItem marshals a struct or map into a dynamo db item
+func marshalItem(value any) (map[string]types.AttributeValue, error) {
+	switch valueType := value.(type) {
+	case nil:
+		return nil, errors.New("nil item passed")
+	case map[string]types.AttributeValue:
+		return valueType, nil
+	default:
+		return attributevalue.MarshalMap(value)
+	}
+}
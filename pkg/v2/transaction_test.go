@@ -0,0 +1,172 @@
+package v2
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// ------------------------------------------ TRANSACTION MODEL ------------------------------------------
+type Transaction struct {
+	UserID        *string `json:"user_id,omitempty" dynamodbav:"user_id,omitempty"`
+	TransactionID *string `json:"transaction_id,omitempty" dynamodbav:"transaction_id,omitempty"`
+	Amount        *int    `json:"amount,omitempty" dynamodbav:"amount,omitempty"`
+}
+
+// ------------------------------------- EXPRESSION BUILDER FOR TRANSACTION MODEL -------------------------------------
+type Transaction_ExpressionBuilder struct {
+	UserID        DynamoKeyAttribute[*string]
+	TransactionID DynamoKeyAttribute[*string]
+	Amount        DynamoAttribute[*int]
+}
+
+func (transactionExpBldr *Transaction_ExpressionBuilder) BuildTree(name string) *DynamoAttribute[*Transaction_ExpressionBuilder] {
+	transactionExpBldr = &Transaction_ExpressionBuilder{}
+	transactionExpBldr.UserID = *NewDynamoKeyAttribute[*string]().WithName("user_id")
+	transactionExpBldr.TransactionID = *NewDynamoKeyAttribute[*string]().WithName("transaction_id")
+	transactionExpBldr.Amount = *NewDynamoAttribute[*int]().WithName("amount")
+	return NewDynamoAttribute[*Transaction_ExpressionBuilder]().
+		WithAccessReference(transactionExpBldr).
+		WithName(name).
+		WithChildAttribute(&transactionExpBldr.UserID).
+		WithChildAttribute(&transactionExpBldr.TransactionID).
+		WithChildAttribute(&transactionExpBldr.Amount)
+}
+
+func NewTransaction_ExpressionBuilder() DDBItemExpressionBuilder[*Transaction_ExpressionBuilder] {
+	return NewDDBItemExpressionBuilder(&Transaction_ExpressionBuilder{})
+}
+
+// -------------------------------------------------------------------------------------------------------------
+
+// Testing transaction of DDB items of different types
+func TestTransactionBuilder(t *testing.T) {
+	// update a person only if it exists
+	personExpBuilder := NewPerson_ExpressionBuilder()
+	personExpBuilder.Build()
+	personRoot := personExpBuilder.DDBItemRoot().AR()
+	personRoot.Name.AddValue(UPDATE_SET, "New Name")
+	personRoot.Name.AndWithCondition()(personRoot.Name.GetNameBuilder().AttributeExists())
+
+	// insert a transaction only if it doesn't exist
+	transactionExpBuilder := NewTransaction_ExpressionBuilder()
+	transactionExpBuilder.Build()
+	transactionRoot := transactionExpBuilder.DDBItemRoot().AR()
+	transactionRoot.Amount.AndWithCondition()(transactionRoot.Amount.GetNameBuilder().AttributeNotExists())
+
+	// check balance of the user
+	balanceExpBuilder := NewTransaction_ExpressionBuilder()
+	balanceExpBuilder.Build()
+	balanceRoot := balanceExpBuilder.DDBItemRoot().AR()
+	balanceRoot.Amount.AndWithCondition()(balanceRoot.Amount.GetNameBuilder().GreaterThanEqual(expression.Value(100)))
+
+	input, err := NewTransactionBuilder().
+		WithItem(TRANSACT_UPDATE, "persons", Person{PK: utils.PointerTo("person#1"), SK: utils.PointerTo("details")}, personExpBuilder).
+		WithItem(TRANSACT_PUT, "transactions", Transaction{
+			UserID:        utils.PointerTo("person#1"),
+			TransactionID: utils.PointerTo("transaction#1"),
+			Amount:        utils.PointerTo(100),
+		}, transactionExpBuilder).
+		WithItem(TRANSACT_CONDITION_CHECK, "transactions", map[string]types.AttributeValue{
+			"user_id":        &types.AttributeValueMemberS{Value: "person#1"},
+			"transaction_id": &types.AttributeValueMemberS{Value: "balance"},
+		}, balanceExpBuilder).
+		Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	expectedInput := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName: utils.PointerTo("persons"),
+					Key: map[string]types.AttributeValue{
+						"pk": &types.AttributeValueMemberS{Value: "person#1"},
+						"sk": &types.AttributeValueMemberS{Value: "details"},
+					},
+					UpdateExpression:          utils.PointerTo("SET #0 = :0\n"),
+					ConditionExpression:       utils.PointerTo("attribute_exists (#0)"),
+					ExpressionAttributeNames:  map[string]string{"#0": "name"},
+					ExpressionAttributeValues: map[string]types.AttributeValue{":0": &types.AttributeValueMemberS{Value: "New Name"}},
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: utils.PointerTo("transactions"),
+					Item: map[string]types.AttributeValue{
+						"user_id":        &types.AttributeValueMemberS{Value: "person#1"},
+						"transaction_id": &types.AttributeValueMemberS{Value: "transaction#1"},
+						"amount":         &types.AttributeValueMemberN{Value: "100"},
+					},
+					ConditionExpression:      utils.PointerTo("attribute_not_exists (#0)"),
+					ExpressionAttributeNames: map[string]string{"#0": "amount"},
+				},
+			},
+			{
+				ConditionCheck: &types.ConditionCheck{
+					TableName: utils.PointerTo("transactions"),
+					Key: map[string]types.AttributeValue{
+						"user_id":        &types.AttributeValueMemberS{Value: "person#1"},
+						"transaction_id": &types.AttributeValueMemberS{Value: "balance"},
+					},
+					ConditionExpression:       utils.PointerTo("#0 >= :0"),
+					ExpressionAttributeNames:  map[string]string{"#0": "amount"},
+					ExpressionAttributeValues: map[string]types.AttributeValue{":0": &types.AttributeValueMemberN{Value: "100"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expectedInput, input)
+}
+
+// Testing validations of transaction builder
+func TestTransactionBuilderValidation(t *testing.T) {
+	expBuilder := NewTransaction_ExpressionBuilder()
+	expBuilder.Build()
+
+	// two operations on the same item
+	_, err := NewTransactionBuilder().
+		WithItem(TRANSACT_DELETE, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		WithItem(TRANSACT_PUT, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1"), Amount: utils.PointerTo(1)}, expBuilder).
+		Build()
+	assert.ErrorContains(t, err, "item 0 and 1 of transaction target the same item")
+
+	// same key but different tables
+	_, err = NewTransactionBuilder().
+		WithItem(TRANSACT_DELETE, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		WithItem(TRANSACT_DELETE, "archived_transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		Build()
+	assert.Nil(t, err)
+
+	// key attribute missing
+	_, err = NewTransactionBuilder().
+		WithItem(TRANSACT_DELETE, "transactions", Transaction{UserID: utils.PointerTo("u1")}, expBuilder).
+		Build()
+	assert.ErrorContains(t, err, "value of key attribute [transaction_id] is missing")
+
+	// condition check without condition
+	_, err = NewTransactionBuilder().
+		WithItem(TRANSACT_CONDITION_CHECK, "transactions", Transaction{UserID: utils.PointerTo("u1"), TransactionID: utils.PointerTo("t1")}, expBuilder).
+		Build()
+	assert.ErrorContains(t, err, "condition check requires a condition")
+
+	// more than allowed number of items
+	transactionBuilder := NewTransactionBuilder()
+	for idx := 0; idx <= MaxTransactWriteItems; idx++ {
+		transactionBuilder.WithItem(TRANSACT_DELETE, "transactions", Transaction{
+			UserID:        utils.PointerTo("u1"),
+			TransactionID: utils.PointerTo("t" + strconv.Itoa(idx)),
+		}, expBuilder)
+	}
+	_, err = transactionBuilder.Build()
+	assert.ErrorContains(t, err, "a transaction can have at most 100 items")
+}
@@ -0,0 +1,109 @@
+package v2
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gauxs/dynexpr/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+// Testing that exceeded limits of dynamo db are reported with their document paths
+func TestValidate(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	// adding a list item twice keeps a single list item
+	rootExpBldr.PhoneNos.AddListItem(1, 1)
+	expBuilder.Build()
+
+	// nothing is marked
+	assert.Nil(t, expBuilder.Validate())
+
+	rootExpBldr.PhoneNos.Index(1).Project()
+	rootExpBldr.PhoneNos.Index(1).AddValue(UPDATE_SET, "123")
+	names := []expression.OperandBuilder{}
+	for idx := 0; idx < 101; idx++ {
+		names = append(names, expression.Value("Name"))
+	}
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().In(names[0], names[1:]...))
+	rootExpBldr.Name.AddValue(UPDATE_SET, strings.Repeat("a", 400*1024))
+
+	err := expBuilder.Validate()
+	var validationErrors ValidationErrors
+	if !assert.True(t, errors.As(err, &validationErrors)) {
+		return
+	}
+
+	kinds := []ValidationErrorKind{}
+	for _, validationError := range validationErrors {
+		kinds = append(kinds, validationError.Kind)
+	}
+	assert.Equal(t, []ValidationErrorKind{
+		VALIDATION_TOO_MANY_IN_OPERANDS,
+		VALIDATION_ITEM_TOO_LARGE,
+	}, kinds)
+	assert.Equal(t, VALIDATION_TOO_MANY_IN_OPERANDS, validationErrors[0].Kind)
+	assert.Equal(t, "Condition", validationErrors[0].Expression)
+	assert.Equal(t, []string{"name"}, validationErrors[0].Paths)
+	assert.Equal(t, "Condition expression is invalid, IN has 101 operands, maximum allowed is 100", validationErrors[0].Error())
+	assert.Equal(t, "Update", validationErrors[1].Expression)
+
+	// only an expression builder with validation fails to build
+	_, err = expBuilder.BuildUpdateBuilder()
+	assert.Nil(t, err)
+
+	// every builder returning an error fails for an expression builder with validation, the
+	// conditions of BuildConditionBuilder are validated by the others
+	validatingExpBuilder := expBuilder.WithValidation()
+	assert.NotNil(t, validatingExpBuilder.BuildConditionBuilder())
+	builds := map[string]func() error{
+		"projection": func() error {
+			_, err := validatingExpBuilder.BuildProjectionBuilder()
+			return err
+		},
+		"filter": func() error {
+			_, err := validatingExpBuilder.BuildFilterBuilder()
+			return err
+		},
+		"update": func() error {
+			_, err := validatingExpBuilder.BuildUpdateBuilder()
+			return err
+		},
+		"partiql": func() error {
+			_, _, err := validatingExpBuilder.BuildPartiQL(PARTIQL_DELETE, "persons")
+			return err
+		},
+		"transaction": func() error {
+			person := Person{PK: utils.PointerTo("person#1"), SK: utils.PointerTo("person#1")}
+			_, err := NewTransactionBuilder().WithItem(TRANSACT_CONDITION_CHECK, "persons", person, validatingExpBuilder).Build()
+			return err
+		},
+	}
+	for name, build := range builds {
+		var validationError *ValidationError
+		if assert.True(t, errors.As(build(), &validationError), name) {
+			assert.Equal(t, VALIDATION_TOO_MANY_IN_OPERANDS, validationError.Kind, name)
+		}
+	}
+}
+
+// Testing that expressions longer than 4KB are reported
+func TestValidateExpressionLength(t *testing.T) {
+	expBuilder := NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	expBuilder.Build()
+
+	for idx := 0; idx < 600; idx++ {
+		rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().NotEqual(expression.Value(idx)))
+	}
+
+	var validationError *ValidationError
+	if assert.True(t, errors.As(expBuilder.Validate(), &validationError)) {
+		assert.Equal(t, VALIDATION_EXPRESSION_TOO_LONG, validationError.Kind)
+		assert.Equal(t, "Condition", validationError.Expression)
+		assert.Empty(t, validationError.Paths)
+	}
+}
@@ -0,0 +1,189 @@
+package v2
+
+import (
+	"reflect"
+
+	"github.com/gauxs/dynexpr/internal/core"
+	"github.com/gauxs/dynexpr/internal/ddbexpr/sdkv2"
+)
+
+type NodeKind = core.WalkKind
+
+const (
+	// Attribute holding a primitive, a set or a map without a node for its attributes
+	NODE_LEAF = core.WALK_LEAF
+	// Attribute holding a map with a node for each of its attributes
+	NODE_STRUCT = core.WALK_STRUCT
+	NODE_LIST   = core.WALK_LIST
+	NODE_KEY    = core.WALK_KEY
+)
+
+// Marks are the marks of a single node of the expression builder tree
+type Marks struct {
+	// Marked for projection
+	Projection bool
+
+	// Operation and value marked for update, NO_OP when not marked. Values marked by
+	// UpdateFromDiff are types.AttributeValue and values marked by Append,
+	// Prepend, Increment, Decrement, SetIfNotExists and SetFrom are expression operand builders
+	Operation DynamoOperation
+	Value     any
+
+	// Number of conditions, key conditions for key attributes, marked
+	Conditions int
+
+	// Number of filters marked
+	Filters int
+}
+
+// Node is a node of the expression builder tree, it is implemented by DynamoAttribute,
+// DynamoListAttribute and DynamoKeyAttribute
+type Node interface {
+	// GetName returns the name of the attribute as defined in DB
+	GetName() string
+
+	// DocumentPath returns the document path of the attribute
+	DocumentPath() string
+
+	Kind() NodeKind
+
+	// Type returns the type parameter `T` of the attribute, i.e. the type of the field for
+	// leaves and key attributes, the type of list items for lists and the expression builder
+	// for structs
+	Type() reflect.Type
+
+	Marks() Marks
+
+	// Project marks the attribute for projection
+	Project() error
+
+	// Children returns the child attributes of a struct or the list items of a list in the
+	// order they were added
+	Children() []Node
+
+	coreNode() *core.Node
+}
+
+// Enforcing constraints at compile time
+var _ Node = (&DynamoAttribute[int]{})
+var _ Node = (&DynamoListAttribute[int]{})
+var _ Node = (&DynamoKeyAttribute[int]{})
+
+// SkipChildren is returned by the function passed to Walk to skip the children of a node
+var SkipChildren = core.SkipChildren
+
+// Walk calls `fn` for `root` and every node below it, depth first with parents before their
+// children. Walk stops at the first error returned by `fn` and returns it, unless it is
+// SkipChildren in which case the children of that node are skipped
+//
+// In concurrency mode the tree is not locked while `fn` is called, so `fn` can mark the nodes
+func Walk(root Node, fn func(Node) error) error {
+	return root.coreNode().Walk(func(node *core.Node) error {
+		if child, ok := node.Owner().(Node); ok {
+			return fn(child)
+		}
+
+		return nil
+	})
+}
+
+func (da *DynamoAttribute[T]) DocumentPath() string {
+	defer da.node.RLockTree()()
+	return da.node.DocumentPath()
+}
+
+func (da *DynamoAttribute[T]) Kind() NodeKind {
+	return da.node.WalkKind()
+}
+
+func (da *DynamoAttribute[T]) Type() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (da *DynamoAttribute[T]) Marks() Marks {
+	defer da.node.RLockTree()()
+	return nodeMarks(da.node)
+}
+
+func (da *DynamoAttribute[T]) Children() []Node {
+	defer da.node.RLockTree()()
+	return childNodes(da.node.WalkChildren())
+}
+
+func (dla *DynamoListAttribute[T]) DocumentPath() string {
+	defer dla.node.RLockTree()()
+	return dla.node.DocumentPath()
+}
+
+func (dla *DynamoListAttribute[T]) Kind() NodeKind {
+	return dla.node.WalkKind()
+}
+
+func (dla *DynamoListAttribute[T]) Type() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (dla *DynamoListAttribute[T]) Marks() Marks {
+	defer dla.node.RLockTree()()
+	return nodeMarks(dla.node)
+}
+
+func (dla *DynamoListAttribute[T]) Children() []Node {
+	defer dla.node.RLockTree()()
+	return childNodes(dla.node.WalkChildren())
+}
+
+func (dka *DynamoKeyAttribute[T]) DocumentPath() string {
+	defer dka.node.RLockTree()()
+	return dka.node.DocumentPath()
+}
+
+func (dka *DynamoKeyAttribute[T]) Kind() NodeKind {
+	return dka.node.WalkKind()
+}
+
+func (dka *DynamoKeyAttribute[T]) Type() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (dka *DynamoKeyAttribute[T]) Marks() Marks {
+	defer dka.node.RLockTree()()
+	return nodeMarks(dka.node)
+}
+
+func (dka *DynamoKeyAttribute[T]) Children() []Node {
+	defer dka.node.RLockTree()()
+	return childNodes(dka.node.WalkChildren())
+}
+
+// nodeMarks returns the marks of `node` with the value converted for the sdk
+func nodeMarks(node *core.Node) Marks {
+	marks := node.Marks()
+	switch valueType := marks.Value.(type) {
+	case core.RawValue:
+		marks.Value = sdkv2.ToAttributeValue(valueType.Value)
+	case core.ListAppend, core.SetOperation, core.Operand:
+		operand, _ := core.UpdateOperand(node.DocumentPath(), valueType)
+		marks.Value = renderOperand(operand)
+	}
+
+	return Marks{
+		Projection: marks.Projection,
+		Operation:  DynamoOperation(marks.Operation),
+		Value:      marks.Value,
+		Conditions: marks.Conditions,
+		Filters:    marks.Filters,
+	}
+}
+
+// childNodes returns the typed attributes wrapping `nodes`
+func childNodes(nodes []*core.Node) []Node {
+	children := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		if child, ok := node.Owner().(Node); ok {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
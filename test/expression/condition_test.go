@@ -1,8 +1,7 @@
 package expression
 
 import (
-	"encoding/json"
-	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -50,15 +49,9 @@ func TestTransaction(t *testing.T) {
 		return
 	}
 
-	fmt.Println(string(*(dynamoDBExpr.Projection())))
-	fmt.Println(string(*(dynamoDBExpr.KeyCondition())))
-	fmt.Println(string(*(dynamoDBExpr.Update())))
-
-	nM, _ := json.Marshal(dynamoDBExpr.Names())
-	vM, _ := json.Marshal(dynamoDBExpr.Values())
-	fmt.Println(string(nM))
-	fmt.Println(string(vM))
-
+	assert.Equal(t, "KeyCondition: transaction_id = \"userID#123\"\n"+
+		"Projection: user_id, transaction_id, amount\n"+
+		"Update: SET amount = 9000", strings.TrimSpace(dynexprv1.Explain(dynamoDBExpr)))
 }
 
 func TestAttributeCondition(t *testing.T) {
@@ -117,6 +117,12 @@ func (o *BankAccount_ExpressionBuilder) BuildTree(name string) *dynexpr.DynamoAt
 func NewPerson_ExpressionBuilder() dynexpr.DDBItemExpressionBuilder[*Person_ExpressionBuilder] {
 	return dynexpr.NewDDBItemExpressionBuilder(&Person_ExpressionBuilder{})
 }
+func NewPerson_ExpressionBuilderPool() *dynexpr.DDBItemExpressionBuilderPool[*Person_ExpressionBuilder] {
+	return dynexpr.NewDDBItemExpressionBuilderPool(&Person_ExpressionBuilder{})
+}
 func NewTransaction_ExpressionBuilder() dynexpr.DDBItemExpressionBuilder[*Transaction_ExpressionBuilder] {
 	return dynexpr.NewDDBItemExpressionBuilder(&Transaction_ExpressionBuilder{})
 }
+func NewTransaction_ExpressionBuilderPool() *dynexpr.DDBItemExpressionBuilderPool[*Transaction_ExpressionBuilder] {
+	return dynexpr.NewDDBItemExpressionBuilderPool(&Transaction_ExpressionBuilder{})
+}
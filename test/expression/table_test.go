@@ -0,0 +1,125 @@
+package expression
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/gauxs/dynexpr/internal/utils"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gauxs/dynexpr/pkg/dynexprtest"
+	dynexprv1 "github.com/gauxs/dynexpr/pkg/v1"
+	test_models "github.com/gauxs/dynexpr/test/expression/data"
+)
+
+// Testing the behaviour of expressions built by expression builder against a fake table
+func TestExpressionsOnTable(t *testing.T) {
+	fake := dynexprtest.New()
+	assert.Nil(t, fake.CreateTable("persons", "pk", "sk"))
+	client := fake.ClientV1()
+
+	person := test_models.Person{
+		PK:            utils.PointerTo("person#1"),
+		SK:            utils.PointerTo("profile"),
+		Name:          utils.PointerTo("John"),
+		FamilyDetails: &test_models.FamilyDetail{IsMarried: utils.PointerTo(false)},
+		PhoneNos:      &[]*string{utils.PointerTo("123"), utils.PointerTo("456")},
+	}
+	item, err := dynamodbattribute.MarshalMap(person)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	_, err = client.PutItem(&dynamodb.PutItemInput{TableName: aws.String("persons"), Item: item})
+	assert.Nil(t, err)
+
+	// update guarded by a condition
+	expBuilder := test_models.NewPerson_ExpressionBuilder()
+	rootExpBldr := expBuilder.DDBItemRoot().AR()
+	rootExpBldr.PhoneNos.AddListItem(1)
+	expBuilder.Build()
+
+	rootExpBldr.Name.AndWithCondition()(rootExpBldr.Name.GetNameBuilder().Equal(expression.Value("John")))
+	rootExpBldr.FamilyDetails.AR().IsMarried.AddValue(dynexprv1.UPDATE_SET, true)
+	rootExpBldr.PhoneNos.Index(1).AddValue(dynexprv1.UPDATE_REMOVE, nil)
+
+	conditionBuilder := expBuilder.BuildConditionBuilder()
+	updateBuilder, err := expBuilder.BuildUpdateBuilder()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	updateExpr, err := expression.NewBuilder().WithCondition(*conditionBuilder).WithUpdate(*updateBuilder).Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	updateItemInput := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("persons"),
+		Key:                       map[string]*dynamodb.AttributeValue{"pk": item["pk"], "sk": item["sk"]},
+		ConditionExpression:       updateExpr.Condition(),
+		UpdateExpression:          updateExpr.Update(),
+		ExpressionAttributeNames:  updateExpr.Names(),
+		ExpressionAttributeValues: updateExpr.Values(),
+	}
+	_, err = client.UpdateItem(updateItemInput)
+	assert.Nil(t, err)
+
+	// projection and key condition of the expression builder
+	expBuilder = test_models.NewPerson_ExpressionBuilder()
+	rootExpBldr = expBuilder.DDBItemRoot().AR()
+	expBuilder.Build()
+
+	rootExpBldr.PK.AndWithCondition()(rootExpBldr.PK.GetKeyBuilder().Equal(expression.Value("person#1")))
+	rootExpBldr.FamilyDetails.Project()
+	rootExpBldr.PhoneNos.Project()
+
+	projectionBuilder, err := expBuilder.BuildProjectionBuilder()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	queryExpr, err := expression.NewBuilder().WithKeyCondition(*expBuilder.BuildKeyConditionBuilder()).WithProjection(*projectionBuilder).Build()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	queryOutput, err := client.Query(&dynamodb.QueryInput{
+		TableName:                 aws.String("persons"),
+		KeyConditionExpression:    queryExpr.KeyCondition(),
+		ProjectionExpression:      queryExpr.Projection(),
+		ExpressionAttributeNames:  queryExpr.Names(),
+		ExpressionAttributeValues: queryExpr.Values(),
+	})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	persons := []test_models.Person{}
+	assert.Nil(t, dynamodbattribute.UnmarshalListOfMaps(queryOutput.Items, &persons))
+	assert.Equal(t, []test_models.Person{{
+		PK:            utils.PointerTo("person#1"),
+		SK:            utils.PointerTo("profile"),
+		FamilyDetails: &test_models.FamilyDetail{IsMarried: utils.PointerTo(true)},
+		PhoneNos:      &[]*string{utils.PointerTo("123")},
+	}}, persons)
+
+	// condition of the update no longer holds once name changes
+	item["name"] = &dynamodb.AttributeValue{S: aws.String("Doe")}
+	_, err = client.PutItem(&dynamodb.PutItemInput{TableName: aws.String("persons"), Item: item})
+	assert.Nil(t, err)
+
+	_, err = client.UpdateItem(updateItemInput)
+	var conditionalCheckFailed *dynamodb.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &conditionalCheckFailed))
+}